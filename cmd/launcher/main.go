@@ -82,10 +82,20 @@ func main() {
 		LauncherLogger.Fatal(err)
 	}
 
-	osCreds, err := ansible.MakeOsCreds(vaultCfg.OsKey, vaultClient, config.OsVersion)
+	//check the default cloud settings, its credentials are read for every cluster
+	provider, err := ansible.NewCloudProvider(config, db)
+	if err != nil {
+		LauncherLogger.SetOutput(os.Stderr)
+		LauncherLogger.Fatal(err)
+	}
+	err = provider.LoadCredentials(vaultClient, vaultCfg)
+	if err != nil {
+		LauncherLogger.SetOutput(os.Stderr)
+		LauncherLogger.Fatal(err)
+	}
 
 	aService := ansible.LauncherServer{Logger: LauncherLogger, Db: db,
-		VaultCommunicator: &vaultCommunicator, Config: config, OsCreds: osCreds}
+		VaultCommunicator: &vaultCommunicator, Config: config}
 
 	protobuf.RegisterAnsibleRunnerServer(gas, &aService)
 
@@ -15,8 +15,14 @@ import (
 	"github.com/ispras/michman/internal/database"
 	"github.com/ispras/michman/internal/logger"
 	"github.com/ispras/michman/internal/rest/authorization"
+	"github.com/ispras/michman/internal/rest/autoscaler"
 	grpc_client "github.com/ispras/michman/internal/rest/grpc"
 	"github.com/ispras/michman/internal/rest/handler"
+	"github.com/ispras/michman/internal/rest/health"
+	"github.com/ispras/michman/internal/rest/queue"
+	"github.com/ispras/michman/internal/rest/reaper"
+	"github.com/ispras/michman/internal/rest/reconciler"
+	"github.com/ispras/michman/internal/rest/webhook"
 	"github.com/ispras/michman/internal/utils"
 	"github.com/julienschmidt/httprouter"
 	"github.com/sirupsen/logrus"
@@ -149,6 +155,48 @@ func main() {
 		grpcLogger.Fatal(err)
 	}
 
+	//deliver cluster lifecycle events to the project webhooks, resuming deliveries interrupted by the previous shutdown
+	webhookRetries := webhook.DefaultRetries
+	if config.WebhookRetries != nil {
+		webhookRetries = *config.WebhookRetries
+	}
+	dispatcher := webhook.Dispatcher{Db: db, Logger: httpLogger, Retries: webhookRetries,
+		RetryDelay: time.Duration(config.WebhookRetryDelay) * time.Second}
+	err = dispatcher.Resume()
+	if err != nil {
+		httpLogger.SetOutput(os.Stderr)
+		httpLogger.Fatal(err)
+	}
+
+	//resume cluster operations interrupted by the previous shutdown
+	opQueue := queue.Queue{Db: db, Runner: gc, Logger: grpcLogger, Notifier: dispatcher}
+	err = opQueue.Resume()
+	if err != nil {
+		httpLogger.SetOutput(os.Stderr)
+		httpLogger.Fatal(err)
+	}
+
+	//evaluate cluster autoscaling policies in background
+	scaler := autoscaler.Autoscaler{Db: db, Queue: opQueue, Metrics: autoscaler.HttpMetricReader{}, Logger: httpLogger,
+		Interval: time.Duration(config.AutoscalingInterval) * time.Second}
+	go scaler.Run()
+
+	//delete expired clusters in background
+	clusterReaper := reaper.Reaper{Db: db, Queue: opQueue, Notifier: reaper.WebhookNotifier{Events: dispatcher, Logger: httpLogger},
+		Logger: httpLogger, Interval: time.Duration(config.ReaperInterval) * time.Second,
+		WarningPeriod: time.Duration(config.ExpiryWarningPeriod) * time.Hour}
+	go clusterReaper.Run()
+
+	//check health of the active clusters services in background
+	healthMonitor := health.Monitor{Db: db, Checker: health.NetChecker{ConsulPort: config.ConsulPort}, Logger: httpLogger,
+		Interval: time.Duration(config.HealthCheckInterval) * time.Second}
+	go healthMonitor.Run()
+
+	//inspect the active clusters for drift from their instances and services in background
+	clusterReconciler := reconciler.Reconciler{Db: db, Inspector: gc, Logger: httpLogger,
+		Interval: time.Duration(config.ReconcileInterval) * time.Second}
+	go clusterReconciler.Run()
+
 	//setup session manager
 	sessionManager := scs.New()
 	//set session configurations
@@ -163,7 +211,8 @@ func main() {
 
 	httpLogger.Info("Server starts to work")
 
-	hS := handler.HttpServer{Gc: gc, Logger: httpLogger, Db: db, Router: router, Config: config}
+	hS := handler.HttpServer{Queue: opQueue, Planner: gc, Health: healthMonitor, Reconciler: clusterReconciler, Logger: httpLogger,
+		Db: db, Router: router, Config: config}
 	hS.CreateRoutes()
 
 	//serve with session and authorization if authentication is used
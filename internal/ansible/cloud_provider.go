@@ -0,0 +1,59 @@
+package ansible
+
+import (
+	"context"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"io"
+)
+
+// PlaybookRunner runs ansible playbook with the args and writes its output to the writer
+type PlaybookRunner func(ctx context.Context, args []string, stdout io.Writer) error
+
+// CloudProvider describes infrastructure where cluster instances are deployed
+type CloudProvider interface {
+	// Type returns provider type from the configuration file
+	Type() string
+	// LoadCredentials reads provider credentials from vault
+	LoadCredentials(vaultClient *vaultapi.Client, vaultCfg *utils.Config) error
+	// Env returns environment variables with provider credentials for ansible
+	Env() []string
+	// InstanceVars returns provider specific ansible extra vars for the cluster instances
+	InstanceVars(cluster *protobuf.Cluster, image *protobuf.Image) (InterfaceMap, error)
+	// Playbook returns playbook for the role or empty string if the provider skips it
+	Playbook(role string) string
+	// DiscoverNodes returns all hosts of the cluster with their roles and IPs
+	DiscoverNodes(ctx context.Context, cluster *protobuf.Cluster, run PlaybookRunner) ([]*protobuf.Node, error)
+}
+
+// NewCloudProvider creates cloud provider chosen in the configuration file
+func NewCloudProvider(config utils.Config, db database.Database) (CloudProvider, error) {
+	switch config.CloudProvider {
+	case utils.CloudProviderOpenstack, "":
+		return &OpenstackProvider{Config: config}, nil
+	case utils.CloudProviderFake:
+		return &FakeProvider{HostIP: utils.FakeProviderHostIP, Db: db}, nil
+	}
+	return nil, ErrCloudProvider(config.CloudProvider)
+}
+
+// NewClusterCloudProvider creates cloud provider for the cloud chosen for the cluster,
+// cloud settings override ones from the configuration file
+func NewClusterCloudProvider(config utils.Config, cloud *protobuf.Cloud, db database.Database) (CloudProvider, error) {
+	config.CloudProvider = cloud.Type
+	if cloud.OsVersion != "" {
+		config.OsVersion = cloud.OsVersion
+	}
+	if cloud.VirtualNetwork != "" {
+		config.VirtualNetwork = cloud.VirtualNetwork
+	}
+	if cloud.FloatingIP != "" {
+		config.FloatingIP = cloud.FloatingIP
+	}
+	if cloud.KeyName != "" {
+		config.Key = cloud.KeyName
+	}
+	return NewCloudProvider(config, db)
+}
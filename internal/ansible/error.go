@@ -20,6 +20,8 @@ const (
 	errChmod                     = "error occurred while changing the mode of the file"
 	errWrite                     = "error occurred while writing to the file"
 	errClose                     = "error occurred while closing file"
+	errCmdCancelled              = "command was cancelled"
+	errReadInventory             = "error occurred while reading cluster inventory"
 )
 
 var (
@@ -37,9 +39,43 @@ var (
 	ErrChmod                     = errors.New(errChmod)
 	ErrWrite                     = errors.New(errWrite)
 	ErrClose                     = errors.New(errClose)
+	ErrCmdCancelled              = errors.New(errCmdCancelled)
+	ErrReadInventory             = errors.New(errReadInventory)
 )
 
 func ErrParseValue(param string) error {
 	ErrParamType := fmt.Errorf("error occurred while parsing %s value", param)
 	return ErrParamType
 }
+
+func ErrCloudProvider(providerType string) error {
+	return fmt.Errorf("unsupported cloud provider type %s", providerType)
+}
+
+func ErrClusterBusy(clusterID string, action string) error {
+	return fmt.Errorf("cluster %s is already processing %s action", clusterID, action)
+}
+
+func ErrStreamSend(clusterID string, err error) error {
+	return fmt.Errorf("error occurred while sending progress of cluster %s: %s", clusterID, err.Error())
+}
+
+func ErrUnknownAction(action string) error {
+	return fmt.Errorf("unknown cluster lifecycle action %s", action)
+}
+
+func ErrRestartNotSupported(serviceType string) error {
+	return fmt.Errorf("restart of %s service is not supported", serviceType)
+}
+
+func ErrRemoveNotSupported(serviceType string) error {
+	return fmt.Errorf("removal of %s service is not supported", serviceType)
+}
+
+func ErrServiceActionNotFound(action string, serviceType string) error {
+	return fmt.Errorf("action %s is not declared by %s service type", action, serviceType)
+}
+
+func ErrServiceActionPlaybook(playbook string) error {
+	return fmt.Errorf("playbook %s of the service type action is not found", playbook)
+}
@@ -0,0 +1,71 @@
+package ansible
+
+import (
+	"context"
+	"fmt"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// FakeProvider doesn't create any instances, all cluster nodes have the same IP.
+// It is used to run Michman without infrastructure for development and testing.
+// Service types are read from database to find out the cluster nodes
+type FakeProvider struct {
+	HostIP string
+	Db     database.Database
+}
+
+func (fp *FakeProvider) Type() string {
+	return utils.CloudProviderFake
+}
+
+func (fp *FakeProvider) LoadCredentials(_ *vaultapi.Client, _ *utils.Config) error {
+	return nil
+}
+
+func (fp *FakeProvider) Env() []string {
+	return nil
+}
+
+func (fp *FakeProvider) InstanceVars(_ *protobuf.Cluster, image *protobuf.Image) (InterfaceMap, error) {
+	var extraVars = make(InterfaceMap)
+	extraVars["fake_image"] = image.Name
+	return extraVars, nil
+}
+
+// Playbook skips all playbooks, so actions finish successfully without changes
+func (fp *FakeProvider) Playbook(_ string) string {
+	return ""
+}
+
+// DiscoverNodes returns the nodes created for the cluster services and monitoring
+// as they are counted in the project quotas, all nodes have the same IP
+func (fp *FakeProvider) DiscoverNodes(_ context.Context, cluster *protobuf.Cluster, _ PlaybookRunner) ([]*protobuf.Node, error) {
+	roles, err := helpfunc.ClusterNodes(fp.Db, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	// nodes are sorted by name as the inventory ones
+	var nodes []*protobuf.Node
+	if roles[utils.NodeRoleMaster] > 0 {
+		nodes = append(nodes, NewNode(cluster, cluster.Name+"-master", utils.NodeRoleMaster))
+	}
+	if roles[utils.NodeRoleMonitoring] > 0 {
+		nodes = append(nodes, NewNode(cluster, cluster.Name+"-monitoring", utils.NodeRoleMonitoring))
+	}
+	for i := int32(1); i <= roles[utils.NodeRoleSlaves]; i++ {
+		nodes = append(nodes, NewNode(cluster, fmt.Sprintf("%s-slave-%d", cluster.Name, i), utils.NodeRoleSlaves))
+	}
+	if roles[utils.NodeRoleStorage] > 0 {
+		nodes = append(nodes, NewNode(cluster, cluster.Name+"-storage", utils.NodeRoleStorage))
+	}
+	for _, node := range nodes {
+		node.PrivateIP = fp.HostIP
+		node.Status = utils.NodeStatusActive
+	}
+	return nodes, nil
+}
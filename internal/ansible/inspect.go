@@ -0,0 +1,104 @@
+package ansible
+
+import (
+	"context"
+	"fmt"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"io"
+	"net"
+	"time"
+)
+
+// dialTimeout limits waiting for the service to accept connection during the cluster inspection
+const dialTimeout = 5 * time.Second
+
+// Dialer checks that the service address accepts connections
+type Dialer func(address string) error
+
+// Inspect compares the cluster saved in db with its instances found in the cloud and reachability of its services
+func (aL *LauncherServer) Inspect(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.ClusterDrift, error) {
+	aL.Logger.Infof("Getting inspect request for cluster %s...", cluster.Name)
+
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	run := func(ctx context.Context, args []string, stdout io.Writer) error {
+		_, err := cL.RunAnsible(ctx, utils.AnsiblePlaybookCmd, args, stdout, nil)
+		return err
+	}
+	nodes, err := cL.Provider.DiscoverNodes(ctx, cluster, run)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	res := &protobuf.ClusterDrift{ClusterID: cluster.ID, Nodes: nodes}
+	res.Missing = len(nodes) == 0 && len(cluster.Nodes) > 0
+	res.Drift = NodesDrift(cluster.Nodes, nodes)
+	if !res.Missing {
+		res.Drift = append(res.Drift, ServicesDrift(cluster.Services, dialTCP)...)
+	}
+	return res, nil
+}
+
+// NodesDrift returns differences between the saved cluster nodes and the instances found in the cloud
+func NodesDrift(saved []*protobuf.Node, found []*protobuf.Node) []string {
+	foundNodes := make(map[string]*protobuf.Node)
+	for _, node := range found {
+		foundNodes[node.Name] = node
+	}
+
+	var res []string
+	for _, node := range saved {
+		cur, ok := foundNodes[node.Name]
+		if !ok {
+			res = append(res, fmt.Sprintf("node %s is missing", node.Name))
+			continue
+		}
+		delete(foundNodes, node.Name)
+		if node.PrivateIP != cur.PrivateIP {
+			res = append(res, fmt.Sprintf("node %s private IP changed from '%s' to '%s'", node.Name, node.PrivateIP, cur.PrivateIP))
+		}
+		if node.FloatingIP != cur.FloatingIP {
+			res = append(res, fmt.Sprintf("node %s floating IP changed from '%s' to '%s'", node.Name, node.FloatingIP, cur.FloatingIP))
+		}
+		if node.IPv6 != cur.IPv6 {
+			res = append(res, fmt.Sprintf("node %s IPv6 changed from '%s' to '%s'", node.Name, node.IPv6, cur.IPv6))
+		}
+		if node.Status == utils.NodeStatusActive && cur.Status != utils.NodeStatusActive {
+			res = append(res, fmt.Sprintf("node %s is %s", node.Name, cur.Status))
+		}
+	}
+	for _, node := range found {
+		if _, ok := foundNodes[node.Name]; ok {
+			res = append(res, fmt.Sprintf("node %s is not saved in the cluster", node.Name))
+		}
+	}
+	return res
+}
+
+// ServicesDrift returns the cluster services which URLs with port don't accept connections
+func ServicesDrift(services []*protobuf.Service, dial Dialer) []string {
+	var res []string
+	for _, service := range services {
+		if _, port, err := net.SplitHostPort(service.URL); err != nil || port == "" {
+			continue
+		}
+		if err := dial(service.URL); err != nil {
+			res = append(res, fmt.Sprintf("service %s at %s is unreachable: %s", service.Type, service.URL, err.Error()))
+		}
+	}
+	return res
+}
+
+func dialTCP(address string) error {
+	conn, err := net.DialTimeout("tcp", address, dialTimeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
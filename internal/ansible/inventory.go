@@ -0,0 +1,129 @@
+package ansible
+
+import (
+	"encoding/json"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// inventoryHost is a cluster host written by the inventory playbook
+type inventoryHost struct {
+	Name      string `json:"name"`
+	Group     string `json:"group"`
+	PrivateV4 string `json:"private_v4"`
+	PublicV4  string `json:"public_v4"`
+	PrivateV6 string `json:"private_v6"`
+	PublicV6  string `json:"public_v6"`
+	Status    string `json:"status"`
+}
+
+// ParseInventory makes cluster nodes sorted by name from the inventory written by the inventory playbook
+func ParseInventory(cluster *protobuf.Cluster, data []byte) ([]*protobuf.Node, error) {
+	var hosts []inventoryHost
+	if err := json.Unmarshal(data, &hosts); err != nil {
+		return nil, ErrUnMarshal
+	}
+
+	nodes := make([]*protobuf.Node, 0, len(hosts))
+	for _, host := range hosts {
+		ipv6 := host.PublicV6
+		if ipv6 == "" {
+			ipv6 = host.PrivateV6
+		}
+		node := NewNode(cluster, host.Name, strings.TrimPrefix(host.Group, cluster.Name+"_"))
+		node.PrivateIP = host.PrivateV4
+		node.FloatingIP = host.PublicV4
+		node.IPv6 = ipv6
+		node.Status = nodeStatus(host.Status)
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes, nil
+}
+
+// NewNode creates cluster node with the role, its flavor and image are taken from the cluster
+func NewNode(cluster *protobuf.Cluster, name string, role string) *protobuf.Node {
+	node := &protobuf.Node{Name: name, Role: role, Image: cluster.Image, Status: utils.NodeStatusUnknown}
+	switch role {
+	case utils.NodeRoleMaster:
+		node.Flavor = cluster.MasterFlavor
+	case utils.NodeRoleSlaves:
+		node.Flavor = cluster.SlavesFlavor
+	case utils.NodeRoleStorage:
+		node.Flavor = cluster.StorageFlavor
+	case utils.NodeRoleMonitoring:
+		node.Flavor = cluster.MonitoringFlavor
+	}
+	return node
+}
+
+// nodeStatus converts status of the cloud server to the node status
+func nodeStatus(serverStatus string) string {
+	switch strings.ToUpper(serverStatus) {
+	case "ACTIVE":
+		return utils.NodeStatusActive
+	case "ERROR":
+		return utils.NodeStatusFailed
+	case "SHUTOFF", "STOPPED", "SUSPENDED", "PAUSED":
+		return utils.NodeStatusDown
+	}
+	return utils.NodeStatusUnknown
+}
+
+// NodeAccessIP returns IP the node is accessed by: floating IP if assigned, otherwise private or IPv6 address
+func NodeAccessIP(node *protobuf.Node) string {
+	if node.FloatingIP != "" {
+		return node.FloatingIP
+	}
+	if node.PrivateIP != "" {
+		return node.PrivateIP
+	}
+	return node.IPv6
+}
+
+// FindNode returns the first node with the role or nil if there is no such node
+func FindNode(nodes []*protobuf.Node, role string) *protobuf.Node {
+	for _, node := range nodes {
+		if node.Role == role {
+			return node
+		}
+	}
+	return nil
+}
+
+// RemoveNodes deletes nodes marked for removal from the cluster and updates its number of slaves
+func RemoveNodes(cluster *protobuf.Cluster) {
+	var nodes []*protobuf.Node
+	var nSlaves int32
+	for _, node := range cluster.Nodes {
+		if node.Status == utils.NodeStatusRemoving {
+			continue
+		}
+		if node.Role == utils.NodeRoleSlaves {
+			nSlaves++
+		}
+		nodes = append(nodes, node)
+	}
+	cluster.Nodes = nodes
+	cluster.NSlaves = nSlaves
+}
+
+// DrainServices returns types of the cluster services which have drain tasks
+func DrainServices(cluster *protobuf.Cluster) []string {
+	drainServices := []string{}
+	for _, service := range cluster.Services {
+		if utils.ItemExists(drainServices, service.Type) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(utils.AnsibleDrainTasksPath, service.Type+".yml")); err == nil {
+			drainServices = append(drainServices, service.Type)
+		}
+	}
+	return drainServices
+}
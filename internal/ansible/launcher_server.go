@@ -2,6 +2,7 @@ package ansible
 
 import (
 	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
 	"github.com/ispras/michman/internal/utils"
 	"github.com/sirupsen/logrus"
 )
@@ -11,5 +12,48 @@ type LauncherServer struct {
 	Db                database.Database
 	VaultCommunicator utils.SecretStorage
 	Config            utils.Config
-	OsCreds           utils.OsCredentials
+	Provider          CloudProvider
+}
+
+// clusterLauncher returns launcher which deploys the cluster into its cloud, clusters without cloud
+// are deployed with the provider from the configuration file. Provider credentials are read from vault
+// for every cluster, so rotated credentials of any cloud are used without the launcher restart
+func (aL *LauncherServer) clusterLauncher(cluster *protobuf.Cluster) (*LauncherServer, error) {
+	vaultClient, vaultCfg, err := aL.VaultCommunicator.ConnectVault()
+	if err != nil {
+		return nil, err
+	}
+	if vaultClient == nil {
+		return nil, utils.ErrVaultNewClient
+	}
+	cloudVaultCfg := *vaultCfg
+
+	var provider CloudProvider
+	if cluster.Cloud == "" {
+		aL.Logger.Info("Getting default cloud credentials...")
+		provider, err = NewCloudProvider(aL.Config, aL.Db)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		aL.Logger.Info("Getting ", cluster.Cloud, " cloud credentials...")
+		cloud, err := aL.Db.ReadCloud(cluster.Cloud)
+		if err != nil {
+			return nil, err
+		}
+		provider, err = NewClusterCloudProvider(aL.Config, cloud, aL.Db)
+		if err != nil {
+			return nil, err
+		}
+		cloudVaultCfg.OsKey = cloud.VaultKey
+	}
+
+	err = provider.LoadCredentials(vaultClient, &cloudVaultCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cL := *aL
+	cL.Provider = provider
+	return &cL, nil
 }
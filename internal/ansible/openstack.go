@@ -0,0 +1,97 @@
+package ansible
+
+import (
+	"context"
+	"encoding/json"
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"io/ioutil"
+	"os"
+)
+
+// OpenstackProvider deploys cluster instances into OpenStack tenant from the configuration file
+type OpenstackProvider struct {
+	Config utils.Config
+	Creds  utils.OsCredentials
+}
+
+func (op *OpenstackProvider) Type() string {
+	return utils.CloudProviderOpenstack
+}
+
+func (op *OpenstackProvider) LoadCredentials(vaultClient *vaultapi.Client, vaultCfg *utils.Config) error {
+	osCreds, err := MakeOsCreds(vaultCfg.OsKey, vaultClient, op.Config.OsVersion)
+	if err != nil {
+		return err
+	}
+	op.Creds = osCreds
+	return nil
+}
+
+// Env returns OpenStack credentials as environment variables named for the OpenStack version
+func (op *OpenstackProvider) Env() []string {
+	var env []string
+	for key, name := range utils.OpenstackSecretsKeys[osVersion(op.Config.OsVersion)] {
+		if value := op.Creds[key]; value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+func (op *OpenstackProvider) InstanceVars(cluster *protobuf.Cluster, image *protobuf.Image) (InterfaceMap, error) {
+	var extraVars = make(InterfaceMap)
+	extraVars["os_image"] = image.CloudImageID
+	extraVars["os_project_name"] = op.Creds[utils.OsProjectName]
+	extraVars["floating_ip_pool"] = op.Config.FloatingIP
+	extraVars["os_auth_url"] = op.Creds[utils.OsAuthUrl]
+	extraVars["virtual_network"] = op.Config.VirtualNetwork
+	extraVars["os_key_name"] = op.Config.Key
+	extraVars["os_swift_user_name"] = op.Creds[utils.OsSwiftUserName]
+	extraVars["os_swift_password"] = op.Creds[utils.OsSwiftPassword]
+	return extraVars, nil
+}
+
+func (op *OpenstackProvider) Playbook(role string) string {
+	return role
+}
+
+// DiscoverNodes finds cluster hosts by their metadata with inventory playbook which writes them to the file
+func (op *OpenstackProvider) DiscoverNodes(ctx context.Context, cluster *protobuf.Cluster, run PlaybookRunner) ([]*protobuf.Node, error) {
+	inventoryFile, err := ioutil.TempFile("", "michman-inventory-*.json")
+	if err != nil {
+		return nil, ErrCreate
+	}
+	inventoryFile.Close()
+	defer os.Remove(inventoryFile.Name())
+
+	v := map[string]string{
+		"cluster_name":   cluster.Name,
+		"inventory_path": inventoryFile.Name(),
+	}
+	inventoryExtraVars, err := json.Marshal(v)
+	if err != nil {
+		return nil, ErrMarshal
+	}
+	args := []string{"-v", utils.AnsibleInventoryRole, "--extra-vars", string(inventoryExtraVars)}
+
+	err = run(ctx, args, ioutil.Discard)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory, err := ioutil.ReadFile(inventoryFile.Name())
+	if err != nil {
+		return nil, ErrReadInventory
+	}
+	return ParseInventory(cluster, inventory)
+}
+
+// osVersion returns supported OpenStack version, ussuri is used by default
+func osVersion(version string) string {
+	if _, exists := utils.OpenstackSecretsKeys[version]; exists {
+		return version
+	}
+	return utils.OsUssuriVersion
+}
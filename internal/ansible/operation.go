@@ -8,6 +8,18 @@ import (
 )
 
 func (aL *LauncherServer) Delete(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.TaskStatus, error) {
+	return aL.attachRun(cluster.ID, utils.ActionDelete, nil, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runDelete(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) DeleteStream(cluster *protobuf.Cluster, stream protobuf.AnsibleRunner_DeleteStreamServer) error {
+	return aL.streamRun(cluster.ID, utils.ActionDelete, stream, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runDelete(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) runDelete(ctx context.Context, cluster *protobuf.Cluster, send ProgressSender) (*protobuf.TaskStatus, error) {
 	aL.Logger.Info("Getting delete cluster request...")
 	cluster.PrintClusterData(aL.Logger)
 
@@ -17,6 +29,12 @@ func (aL *LauncherServer) Delete(ctx context.Context, cluster *protobuf.Cluster)
 		return nil, err
 	}
 
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
 	aL.Logger.Info("Updating cluster in db...")
 	err = aL.Db.UpdateCluster(cluster)
 	if err != nil {
@@ -32,8 +50,10 @@ func (aL *LauncherServer) Delete(ctx context.Context, cluster *protobuf.Cluster)
 	if err != nil {
 		return nil, err
 	}
+	// logs are finished even if the action fails or is cancelled
+	defer aL.finClusterLogs(cLogger)
 
-	ansibleStatus, err := aL.RunInstances(cluster, dockRegCreds, utils.ActionDelete, cLogsWriter)
+	ansibleStatus, err := cL.RunInstances(ctx, cluster, dockRegCreds, utils.ActionDelete, cLogsWriter, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
@@ -44,23 +64,30 @@ func (aL *LauncherServer) Delete(ctx context.Context, cluster *protobuf.Cluster)
 		return nil, err
 	}
 
-	ansibleStatus, err = aL.RunServices(cluster, dockRegCreds, utils.ActionDelete, cLogsWriter, sTypes)
+	ansibleStatus, err = cL.RunServices(ctx, cluster, dockRegCreds, utils.ActionDelete, cLogsWriter, sTypes, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
 	}
 
-	err = cLogger.FinClusterLogsWriter()
-	if err != nil {
-		return nil, err
-	}
-
 	res := new(protobuf.TaskStatus)
 	res.Status = ansibleStatus
 	return res, nil
 }
 
 func (aL *LauncherServer) Update(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.TaskStatus, error) {
+	return aL.attachRun(cluster.ID, utils.ActionUpdate, nil, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runUpdate(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) UpdateStream(cluster *protobuf.Cluster, stream protobuf.AnsibleRunner_UpdateStreamServer) error {
+	return aL.streamRun(cluster.ID, utils.ActionUpdate, stream, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runUpdate(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) runUpdate(ctx context.Context, cluster *protobuf.Cluster, send ProgressSender) (*protobuf.TaskStatus, error) {
 	aL.Logger.Info("Getting update cluster request...")
 	cluster.PrintClusterData(aL.Logger)
 
@@ -70,6 +97,12 @@ func (aL *LauncherServer) Update(ctx context.Context, cluster *protobuf.Cluster)
 		return nil, err
 	}
 
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
 	aL.Logger.Info("Updating cluster in db...")
 	err = aL.Db.UpdateCluster(cluster)
 	if err != nil {
@@ -85,8 +118,10 @@ func (aL *LauncherServer) Update(ctx context.Context, cluster *protobuf.Cluster)
 	if err != nil {
 		return nil, err
 	}
+	// logs are finished even if the action fails or is cancelled
+	defer aL.finClusterLogs(cLogger)
 
-	ansibleStatus, err := aL.RunInstances(cluster, dockRegCreds, utils.ActionUpdate, cLogsWriter)
+	ansibleStatus, err := cL.RunInstances(ctx, cluster, dockRegCreds, utils.ActionUpdate, cLogsWriter, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
@@ -96,17 +131,12 @@ func (aL *LauncherServer) Update(ctx context.Context, cluster *protobuf.Cluster)
 	if err != nil {
 		return nil, err
 	}
-	ansibleStatus, err = aL.RunServices(cluster, dockRegCreds, utils.ActionUpdate, cLogsWriter, sTypes)
+	ansibleStatus, err = cL.RunServices(ctx, cluster, dockRegCreds, utils.ActionUpdate, cLogsWriter, sTypes, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
 	}
 
-	err = cLogger.FinClusterLogsWriter()
-	if err != nil {
-		return nil, err
-	}
-
 	aL.Logger.Info("Saving IPs and URLs for services...")
 	err = aL.Db.UpdateCluster(cluster)
 	if err != nil {
@@ -119,6 +149,18 @@ func (aL *LauncherServer) Update(ctx context.Context, cluster *protobuf.Cluster)
 }
 
 func (aL *LauncherServer) Create(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.TaskStatus, error) {
+	return aL.attachRun(cluster.ID, utils.ActionCreate, nil, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runCreate(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) CreateStream(cluster *protobuf.Cluster, stream protobuf.AnsibleRunner_CreateStreamServer) error {
+	return aL.streamRun(cluster.ID, utils.ActionCreate, stream, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runCreate(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) runCreate(ctx context.Context, cluster *protobuf.Cluster, send ProgressSender) (*protobuf.TaskStatus, error) {
 	aL.Logger.Info("Getting create cluster request...")
 	cluster.PrintClusterData(aL.Logger)
 
@@ -128,6 +170,12 @@ func (aL *LauncherServer) Create(ctx context.Context, cluster *protobuf.Cluster)
 		return nil, err
 	}
 
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
 	aL.Logger.Info("Writing new cluster to db...")
 	err = aL.Db.UpdateCluster(cluster)
 	if err != nil {
@@ -146,8 +194,10 @@ func (aL *LauncherServer) Create(ctx context.Context, cluster *protobuf.Cluster)
 		aL.Logger.Warn(err)
 		return nil, err
 	}
+	// logs are finished even if the action fails or is cancelled
+	defer aL.finClusterLogs(cLogger)
 
-	ansibleStatus, err := aL.RunInstances(cluster, dockRegCreds, utils.ActionCreate, cLogsWriter)
+	ansibleStatus, err := cL.RunInstances(ctx, cluster, dockRegCreds, utils.ActionCreate, cLogsWriter, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
@@ -159,25 +209,158 @@ func (aL *LauncherServer) Create(ctx context.Context, cluster *protobuf.Cluster)
 		return nil, err
 	}
 
-	ansibleStatus, err = aL.RunServices(cluster, dockRegCreds, utils.ActionCreate, cLogsWriter, sTypes)
+	ansibleStatus, err = cL.RunServices(ctx, cluster, dockRegCreds, utils.ActionCreate, cLogsWriter, sTypes, send)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
 	}
 
-	err = cLogger.FinClusterLogsWriter()
+	aL.Logger.Info("Saving IPs and URLs for services...")
+	err = aL.Db.UpdateCluster(cluster)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
 	}
 
-	aL.Logger.Info("Saving IPs and URLs for services...")
+	res := new(protobuf.TaskStatus)
+	res.Status = ansibleStatus
+	return res, nil
+}
+
+// finClusterLogs finishes cluster logs writer of the action
+func (aL *LauncherServer) finClusterLogs(cLogger clusterlogger.Logger) {
+	err := cLogger.FinClusterLogsWriter()
+	if err != nil {
+		aL.Logger.Warn(err)
+	}
+}
+
+func (aL *LauncherServer) Cancel(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.TaskStatus, error) {
+	aL.Logger.Infof("Getting cancel request for cluster %s...", cluster.ID)
+
+	res := new(protobuf.TaskStatus)
+	res.Status = utils.AnsibleNotRunning
+	if clusterRuns.cancel(cluster.ID) {
+		res.Status = utils.AnsibleCancelled
+	}
+	return res, nil
+}
+
+func (aL *LauncherServer) Scale(ctx context.Context, cluster *protobuf.Cluster) (*protobuf.TaskStatus, error) {
+	return aL.attachRun(cluster.ID, utils.ActionScale, nil, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runScale(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) ScaleStream(cluster *protobuf.Cluster, stream protobuf.AnsibleRunner_ScaleStreamServer) error {
+	return aL.streamRun(cluster.ID, utils.ActionScale, stream, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runScale(runCtx, cluster, send)
+	})
+}
+
+func (aL *LauncherServer) runScale(ctx context.Context, cluster *protobuf.Cluster, send ProgressSender) (*protobuf.TaskStatus, error) {
+	aL.Logger.Info("Getting scale cluster request...")
+	cluster.PrintClusterData(aL.Logger)
+
+	dockRegCreds, err := aL.GetDockerCreds()
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	cLogger, err := clusterlogger.MakeNewClusterLogger(aL.Config, cluster.ID, utils.ActionScale)
+	if err != nil {
+		return nil, err
+	}
+
+	cLogsWriter, err := cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		return nil, err
+	}
+	// logs are finished even if the action fails or is cancelled
+	defer aL.finClusterLogs(cLogger)
+
+	ansibleStatus, err := cL.RunScale(ctx, cluster, dockRegCreds, cLogsWriter, send)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	aL.Logger.Info("Saving remaining nodes...")
 	err = aL.Db.UpdateCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(protobuf.TaskStatus)
+	res.Status = ansibleStatus
+	return res, nil
+}
+
+func (aL *LauncherServer) RunAction(ctx context.Context, action *protobuf.ClusterAction) (*protobuf.TaskStatus, error) {
+	return aL.attachRun(action.Cluster.ID, action.Action, nil, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runAction(runCtx, action, send)
+	})
+}
+
+func (aL *LauncherServer) RunActionStream(action *protobuf.ClusterAction, stream protobuf.AnsibleRunner_RunActionStreamServer) error {
+	return aL.streamRun(action.Cluster.ID, action.Action, stream, func(runCtx context.Context, send ProgressSender) (*protobuf.TaskStatus, error) {
+		return aL.runAction(runCtx, action, send)
+	})
+}
+
+func (aL *LauncherServer) runAction(ctx context.Context, action *protobuf.ClusterAction, send ProgressSender) (*protobuf.TaskStatus, error) {
+	aL.Logger.Info("Getting ", action.Action, " cluster request...")
+	cluster := action.Cluster
+	cluster.PrintClusterData(aL.Logger)
+
+	dockRegCreds, err := aL.GetDockerCreds()
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	cL, err := aL.clusterLauncher(cluster)
 	if err != nil {
 		aL.Logger.Warn(err)
 		return nil, err
 	}
 
+	cLogger, err := clusterlogger.MakeNewClusterLogger(aL.Config, cluster.ID, action.Action)
+	if err != nil {
+		return nil, err
+	}
+
+	cLogsWriter, err := cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		return nil, err
+	}
+	// logs are finished even if the action fails or is cancelled
+	defer aL.finClusterLogs(cLogger)
+
+	var ansibleStatus string
+	if action.Action == utils.ActionRepair {
+		ansibleStatus, err = cL.RunRepair(ctx, cluster, dockRegCreds, cLogsWriter, send)
+	} else {
+		ansibleStatus, err = cL.RunClusterAction(ctx, action, dockRegCreds, cLogsWriter, send)
+	}
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	aL.Logger.Info("Saving statuses of the cluster nodes...")
+	err = aL.Db.UpdateCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	res := new(protobuf.TaskStatus)
 	res.Status = ansibleStatus
 	return res, nil
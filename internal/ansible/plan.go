@@ -0,0 +1,82 @@
+package ansible
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/ispras/michman/internal/protobuf"
+	"regexp"
+)
+
+// RedactedValue replaces secrets in the extra vars of the cluster plan
+const RedactedValue = "******"
+
+// secretVarPattern matches names of the extra vars with secrets
+var secretVarPattern = regexp.MustCompile(`(?i)password|secret|token`)
+
+// Plan returns ansible extra vars which the cluster action would pass to the playbooks, without running them
+func (aL *LauncherServer) Plan(_ context.Context, request *protobuf.PlanRequest) (*protobuf.ClusterPlan, error) {
+	cluster := request.Cluster
+	aL.Logger.Infof("Getting %s plan request for cluster %s...", request.Action, cluster.Name)
+
+	dockRegCreds, err := aL.GetDockerCreds()
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	cL, err := aL.clusterLauncher(cluster)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	extraVars, err := cL.MakeExtraVars(aL.Db, cluster, &aL.Config, dockRegCreds, request.Action)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+
+	redacted, err := RedactExtraVars(extraVars)
+	if err != nil {
+		aL.Logger.Warn(err)
+		return nil, err
+	}
+	return &protobuf.ClusterPlan{Action: request.Action, ExtraVars: redacted}, nil
+}
+
+// RedactExtraVars returns JSON of the extra vars with values of the secret vars replaced on any nesting level
+func RedactExtraVars(extraVars InterfaceMap) (string, error) {
+	data, err := json.Marshal(extraVars)
+	if err != nil {
+		return "", ErrMarshal
+	}
+	var vars map[string]interface{}
+	err = json.Unmarshal(data, &vars)
+	if err != nil {
+		return "", ErrUnMarshal
+	}
+
+	redact(vars)
+	data, err = json.Marshal(vars)
+	if err != nil {
+		return "", ErrMarshal
+	}
+	return string(data), nil
+}
+
+func redact(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if secretVarPattern.MatchString(key) && item != "" {
+				v[key] = RedactedValue
+			} else {
+				redact(item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			redact(item)
+		}
+	}
+}
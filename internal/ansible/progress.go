@@ -0,0 +1,117 @@
+package ansible
+
+import (
+	"bytes"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"regexp"
+	"strings"
+)
+
+// ProgressSender sends progress event of the running cluster action
+type ProgressSender func(event *protobuf.ProgressEvent)
+
+var (
+	playPattern  = regexp.MustCompile(`^PLAY \[(.*)\]`)
+	taskPattern  = regexp.MustCompile(`^TASK \[(.*)\]`)
+	hostPattern  = regexp.MustCompile(`^(ok|changed|skipping|failed|fatal): \[([^\]]+)\](: UNREACHABLE!)?`)
+	recapPattern = regexp.MustCompile(`^(\S+)\s+:\s+ok=\d+\s+changed=\d+\s+unreachable=(\d+)\s+failed=(\d+)`)
+)
+
+// ProgressWriter parses ansible-playbook output and sends progress events for plays, tasks and host results
+type ProgressWriter struct {
+	phase   string
+	send    ProgressSender
+	buf     []byte
+	play    string
+	task    string
+	inRecap bool
+}
+
+// NewProgressWriter creates writer for the ansible output of the phase
+func NewProgressWriter(phase string, send ProgressSender) *ProgressWriter {
+	return &ProgressWriter{phase: phase, send: send}
+}
+
+func (pw *ProgressWriter) Write(p []byte) (int, error) {
+	pw.buf = append(pw.buf, p...)
+	for {
+		i := bytes.IndexByte(pw.buf, '\n')
+		if i < 0 {
+			break
+		}
+		pw.parseLine(strings.TrimRight(string(pw.buf[:i]), "\r"))
+		pw.buf = pw.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (pw *ProgressWriter) event(eventType string) *protobuf.ProgressEvent {
+	return &protobuf.ProgressEvent{
+		Type:  eventType,
+		Phase: pw.phase,
+		Play:  pw.play,
+		Task:  pw.task,
+	}
+}
+
+func (pw *ProgressWriter) parseLine(line string) {
+	if strings.HasPrefix(line, "PLAY RECAP") {
+		pw.inRecap = true
+		pw.task = ""
+		return
+	}
+
+	if m := playPattern.FindStringSubmatch(line); m != nil {
+		pw.inRecap = false
+		pw.play = m[1]
+		pw.task = ""
+		pw.send(pw.event(utils.ProgressEventPlay))
+		return
+	}
+
+	if m := taskPattern.FindStringSubmatch(line); m != nil {
+		pw.task = m[1]
+		pw.send(pw.event(utils.ProgressEventTask))
+		return
+	}
+
+	if pw.inRecap {
+		if m := recapPattern.FindStringSubmatch(line); m != nil {
+			e := pw.event(utils.ProgressEventRecap)
+			e.Host = m[1]
+			e.HostStatus = utils.HostOk
+			if m[2] != "0" {
+				e.HostStatus = utils.HostUnreachable
+			} else if m[3] != "0" {
+				e.HostStatus = utils.HostFailed
+			}
+			pw.send(e)
+		}
+		return
+	}
+
+	if m := hostPattern.FindStringSubmatch(line); m != nil {
+		e := pw.event(utils.ProgressEventHost)
+		e.Host = m[2]
+		switch {
+		case m[3] != "":
+			e.HostStatus = utils.HostUnreachable
+		case m[1] == "fatal":
+			e.HostStatus = utils.HostFailed
+		default:
+			e.HostStatus = m[1]
+		}
+		pw.send(e)
+	}
+}
+
+// sendStarted sends event about the registered run, since then the run could be cancelled
+func sendStarted(send ProgressSender) {
+	send(&protobuf.ProgressEvent{Type: utils.ProgressEventStarted})
+}
+
+// sendPhase sends event about the start of the new phase
+func sendPhase(send ProgressSender, phase string) {
+	send(&protobuf.ProgressEvent{Type: utils.ProgressEventPhase, Phase: phase})
+}
@@ -1,34 +1,38 @@
 package ansible
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"github.com/ispras/michman/internal/protobuf"
 	"github.com/ispras/michman/internal/utils"
 	"io"
+	"os"
+	"path/filepath"
 )
 
-func (aL LauncherServer) RunGetIP(cluster *protobuf.Cluster, extendedRole string) (string, error) {
-	var outb bytes.Buffer
-	v := map[string]string{
-		"cluster_name":  cluster.Name,
-		"extended_role": extendedRole,
+func (aL LauncherServer) RunGetNodes(ctx context.Context, cluster *protobuf.Cluster, send ProgressSender) ([]*protobuf.Node, error) {
+	aL.Logger.Info("Getting cluster nodes from ", aL.Provider.Type(), " cloud provider...")
+	sendPhase(send, utils.OperationPhaseInventory)
+	run := func(ctx context.Context, args []string, stdout io.Writer) error {
+		outWriter := io.MultiWriter(stdout, NewProgressWriter(utils.OperationPhaseInventory, send))
+		_, err := aL.RunAnsible(ctx, utils.AnsiblePlaybookCmd, args, outWriter, nil)
+		return err
 	}
-	ipExtraVars, err := json.Marshal(v)
-	if err != nil {
-		return utils.RunFail, ErrMarshal
-	}
-	args := []string{"-v", utils.AnsibleIpRole, "--extra-vars", string(ipExtraVars)}
+	return aL.Provider.DiscoverNodes(ctx, cluster, run)
+}
 
-	aL.Logger.Info("Running ansible for getting IP...")
-	_, err = aL.RunAnsible(utils.AnsiblePlaybookCmd, args, &outb, nil)
-	if err != nil {
-		return utils.RunFail, err
+// runPlaybook runs playbook of the cloud provider for the role, the playbook skipped by the provider succeeds
+func (aL LauncherServer) runPlaybook(ctx context.Context, role string, args []string, outWriter io.Writer) (bool, error) {
+	playbook := aL.Provider.Playbook(role)
+	if playbook == "" {
+		aL.Logger.Infof("Playbook %s is skipped by %s cloud provider", role, aL.Provider.Type())
+		return true, nil
 	}
-	return FindIP(outb.String()), nil
+	cmdArgs := append([]string{"-vvv", playbook}, args...)
+	return aL.RunAnsible(ctx, utils.AnsiblePlaybookCmd, cmdArgs, outWriter, outWriter)
 }
 
-func (aL LauncherServer) RunServices(cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, action string, clusterLogsWriter io.Writer, serviceTypes []protobuf.ServiceType) (string, error) {
+func (aL LauncherServer) RunServices(ctx context.Context, cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, action string, clusterLogsWriter io.Writer, serviceTypes []protobuf.ServiceType, send ProgressSender) (string, error) {
 	newExtraVars, err := aL.MakeExtraVars(aL.Db, cluster, &aL.Config, dockRegCreds, action)
 	if err != nil {
 		return utils.RunFail, err
@@ -39,24 +43,28 @@ func (aL LauncherServer) RunServices(cluster *protobuf.Cluster, dockRegCreds *ut
 		return utils.RunFail, ErrMarshal
 	}
 
-	cmdArgs := []string{"-vvv", utils.AnsibleServicesRole, "--extra-vars", string(newAnsibleArgs)}
+	cmdArgs := []string{"--extra-vars", string(newAnsibleArgs)}
 
 	aL.Logger.Info("Running ansible...")
-	res, runErr := aL.RunAnsible(utils.AnsiblePlaybookCmd, cmdArgs, clusterLogsWriter, clusterLogsWriter)
+	sendPhase(send, utils.OperationPhaseServices)
+	outWriter := io.MultiWriter(clusterLogsWriter, NewProgressWriter(utils.OperationPhaseServices, send))
+	res, runErr := aL.runPlaybook(ctx, utils.AnsibleServicesRole, cmdArgs, outWriter)
 	if runErr != nil {
 		aL.Logger.Warn(runErr)
 		return utils.RunFail, runErr
 	}
 
+	if action == utils.ActionCreate || action == utils.ActionUpdate {
+		FinishServices(cluster.Services, res)
+	}
+
 	if res && (action == utils.ActionCreate || action == utils.ActionUpdate) {
 		storageIp := ""
 		//check if cluster has storage
 		if newExtraVars["create_storage"] == true {
-			ip, err := aL.RunGetIP(cluster, "storage")
-			if err != nil {
-				return utils.RunFail, err
+			if storage := FindNode(cluster.Nodes, utils.NodeRoleStorage); storage != nil {
+				storageIp = NodeAccessIP(storage)
 			}
-			storageIp = ip
 		}
 		for i, service := range cluster.Services {
 			for _, st := range serviceTypes {
@@ -92,7 +100,7 @@ func (aL LauncherServer) RunServices(cluster *protobuf.Cluster, dockRegCreds *ut
 	}
 }
 
-func (aL LauncherServer) RunInstances(cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, action string, clusterLogsWriter io.Writer) (string, error) {
+func (aL LauncherServer) RunInstances(ctx context.Context, cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, action string, clusterLogsWriter io.Writer, send ProgressSender) (string, error) {
 	newExtraVars, err := aL.MakeExtraVars(aL.Db, cluster, &aL.Config, dockRegCreds, action)
 	if err != nil {
 		return utils.RunFail, err
@@ -103,37 +111,351 @@ func (aL LauncherServer) RunInstances(cluster *protobuf.Cluster, dockRegCreds *u
 		return utils.RunFail, ErrMarshal
 	}
 
-	cmdArgs := []string{"-vvv", utils.AnsibleInstancesRole, "--extra-vars", string(newAnsibleArgs)}
+	cmdArgs := []string{"--extra-vars", string(newAnsibleArgs)}
 
 	aL.Logger.Info("Running ansible...")
-	res, runErr := aL.RunAnsible(utils.AnsiblePlaybookCmd, cmdArgs, clusterLogsWriter, clusterLogsWriter)
+	sendPhase(send, utils.OperationPhaseInstances)
+	outWriter := io.MultiWriter(clusterLogsWriter, NewProgressWriter(utils.OperationPhaseInstances, send))
+	res, runErr := aL.runPlaybook(ctx, utils.AnsibleInstancesRole, cmdArgs, outWriter)
 	if runErr != nil {
 		aL.Logger.Warn(runErr)
 		return utils.RunFail, runErr
 	}
 
-	// get master IP
+	// get cluster nodes and master IP
 	if res && (action == utils.ActionCreate || action == utils.ActionUpdate) {
-		masterIp := ""
+		nodes, err := aL.RunGetNodes(ctx, cluster, send)
+		if err != nil {
+			return utils.RunFail, err
+		}
+		cluster.Nodes = nodes
+
 		if newExtraVars["create_master"] == true || newExtraVars["create_master_slave"] == true {
-			ip, err := aL.RunGetIP(cluster, "master")
-			if err != nil {
-				return utils.RunFail, err
+			if master := FindNode(nodes, utils.NodeRoleMaster); master != nil && NodeAccessIP(master) != "" {
+				aL.Logger.Info("Master IP is: ", NodeAccessIP(master))
+				cluster.MasterIP = NodeAccessIP(master)
 			}
-			masterIp = ip
 		}
+	}
+
+	if res {
+		aL.Logger.Info("Launch: OK")
+		return utils.AnsibleOk, nil
+	} else {
+		aL.Logger.Info("Ansible has failed, check logs for more information.")
+		return utils.AnsibleFail, nil
+	}
+}
 
-		if masterIp != "" {
-			aL.Logger.Info("Master IP is: ", masterIp)
-			cluster.MasterIP = masterIp
+// RunScale drains services on the slaves marked for removal and destroys their instances
+func (aL LauncherServer) RunScale(ctx context.Context, cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, clusterLogsWriter io.Writer, send ProgressSender) (string, error) {
+	var removeNodes []string
+	for _, node := range cluster.Nodes {
+		if node.Status == utils.NodeStatusRemoving {
+			removeNodes = append(removeNodes, node.Name)
 		}
 	}
+	if len(removeNodes) == 0 {
+		aL.Logger.Info("There are no nodes to remove")
+		return utils.AnsibleOk, nil
+	}
+
+	newExtraVars, err := aL.MakeExtraVars(aL.Db, cluster, &aL.Config, dockRegCreds, utils.ActionScale)
+	if err != nil {
+		return utils.RunFail, err
+	}
+	newExtraVars["remove_nodes"] = removeNodes
+	newExtraVars["drain_services"] = DrainServices(cluster)
+
+	newAnsibleArgs, jErr := json.Marshal(newExtraVars)
+	if jErr != nil {
+		return utils.RunFail, ErrMarshal
+	}
+
+	cmdArgs := []string{"--extra-vars", string(newAnsibleArgs)}
+
+	aL.Logger.Info("Running ansible...")
+	sendPhase(send, utils.OperationPhaseScale)
+	outWriter := io.MultiWriter(clusterLogsWriter, NewProgressWriter(utils.OperationPhaseScale, send))
+	res, runErr := aL.runPlaybook(ctx, utils.AnsibleScaleRole, cmdArgs, outWriter)
+	if runErr != nil {
+		aL.Logger.Warn(runErr)
+		return utils.RunFail, runErr
+	}
 
 	if res {
-		aL.Logger.Info("Launch: OK")
+		RemoveNodes(cluster)
+		aL.Logger.Info("Scale: OK")
 		return utils.AnsibleOk, nil
 	} else {
 		aL.Logger.Info("Ansible has failed, check logs for more information.")
 		return utils.AnsibleFail, nil
 	}
 }
+
+// ActionPlaybook returns playbook and extra vars which run the cluster lifecycle action
+func ActionPlaybook(action *protobuf.ClusterAction) (string, InterfaceMap, error) {
+	switch action.Action {
+	case utils.ActionStop, utils.ActionStart:
+		return utils.AnsiblePowerRole, InterfaceMap{"power_state": action.Action}, nil
+	case utils.ActionRebootNode:
+		return utils.AnsibleRebootNodeRole, InterfaceMap{"reboot_node": action.Node}, nil
+	case utils.ActionRestartService:
+		playbook := utils.AnsibleRestartRolePrefix + action.Service + ".yml"
+		if _, err := os.Stat(playbook); err != nil {
+			return "", nil, ErrRestartNotSupported(action.Service)
+		}
+		return playbook, InterfaceMap{}, nil
+	case utils.ActionRemoveService:
+		if _, err := os.Stat(filepath.Join(utils.AnsibleRemoveTasksPath, action.Service+".yml")); err != nil {
+			return "", nil, ErrRemoveNotSupported(action.Service)
+		}
+		return utils.AnsibleRemoveServiceRole, InterfaceMap{"remove_service": action.Service}, nil
+	case utils.ActionUpgradeService, utils.ActionReconfigureService:
+		// services playbook deploys only the upgraded or reconfigured service
+		vars := InterfaceMap{"act": utils.AnsibleLaunch}
+		if action.Action == utils.ActionUpgradeService {
+			vars["upgrade_service"] = action.Service
+		} else {
+			vars["reconfigure_service"] = action.Service
+			vars["restart_service"] = action.Restart
+		}
+		if action.Cluster != nil {
+			for _, service := range action.Cluster.Services {
+				if service.Type != action.Service {
+					vars[SetDeployService(service.Type)] = false
+				}
+			}
+		}
+		return utils.AnsibleServicesRole, vars, nil
+	}
+	return "", nil, ErrUnknownAction(action.Action)
+}
+
+// SetServicesStatus sets status of the cluster services of the type, or of all services if the type is empty
+func SetServicesStatus(services []*protobuf.Service, serviceType string, status string) {
+	for _, service := range services {
+		if serviceType == "" || service.Type == serviceType {
+			service.EntityStatus = status
+		}
+	}
+}
+
+// FinishServices sets statuses of the cluster services after the services playbook run: all services become active
+// after the successful run, services which were not active before the failed run become failed
+func FinishServices(services []*protobuf.Service, succeeded bool) {
+	for _, service := range services {
+		if succeeded {
+			service.EntityStatus = utils.StatusActive
+		} else if service.EntityStatus != utils.StatusActive {
+			service.EntityStatus = utils.StatusFailed
+		}
+	}
+}
+
+// RemoveClusterService deletes services of the type from the cluster services
+func RemoveClusterService(cluster *protobuf.Cluster, serviceType string) {
+	services := cluster.Services[:0]
+	for _, service := range cluster.Services {
+		if service.Type != serviceType {
+			services = append(services, service)
+		}
+	}
+	cluster.Services = services
+}
+
+// UpgradedServices returns the cluster services with the service of the upgrade or reconfigure action
+// moved to the new version and config
+func UpgradedServices(services []*protobuf.Service, action *protobuf.ClusterAction) []*protobuf.Service {
+	res := make([]*protobuf.Service, 0, len(services))
+	for _, service := range services {
+		if service.Type == action.Service {
+			service = &protobuf.Service{
+				ID:           service.ID,
+				Name:         service.Name,
+				Type:         service.Type,
+				ClusterRef:   service.ClusterRef,
+				Config:       action.Params,
+				DisplayName:  service.DisplayName,
+				EntityStatus: service.EntityStatus,
+				Version:      action.ServiceVersion,
+				URL:          service.URL,
+				Description:  service.Description,
+			}
+		}
+		res = append(res, service)
+	}
+	return res
+}
+
+// ServiceActionPlaybook returns playbook and extra vars which run the action declared by the service type,
+// parameters which are not set in the request get their default values
+func (aL LauncherServer) ServiceActionPlaybook(sType *protobuf.ServiceType, action *protobuf.ClusterAction) (string, InterfaceMap, error) {
+	for _, serviceAction := range sType.Actions {
+		if serviceAction.Name != action.ServiceAction {
+			continue
+		}
+
+		playbook := filepath.Join(utils.AnsibleActionsPath, serviceAction.Playbook)
+		if _, err := os.Stat(playbook); err != nil {
+			return "", nil, ErrServiceActionPlaybook(playbook)
+		}
+
+		actionVars := InterfaceMap{}
+		for _, param := range serviceAction.Parameters {
+			value, ok := action.Params[param.ParameterName]
+			if !ok {
+				if param.DefaultValue == "" {
+					continue
+				}
+				value = param.DefaultValue
+			}
+			convertedValue, err := aL.ConvertParamValue(value, param.Type, param.IsList)
+			if err != nil {
+				return "", nil, err
+			}
+			varName := param.AnsibleVarName
+			if varName == "" {
+				varName = param.ParameterName
+			}
+			actionVars[varName] = convertedValue
+		}
+		return playbook, actionVars, nil
+	}
+	return "", nil, ErrServiceActionNotFound(action.ServiceAction, sType.Type)
+}
+
+// RunClusterAction runs the lifecycle action for the cluster instances or services, or the action declared
+// by the service type, and refreshes statuses of the cluster nodes
+func (aL LauncherServer) RunClusterAction(ctx context.Context, action *protobuf.ClusterAction, dockRegCreds *utils.DockerCredentials, clusterLogsWriter io.Writer, send ProgressSender) (string, error) {
+	cluster := action.Cluster
+	var playbook string
+	var actionVars InterfaceMap
+	var err error
+	if action.Action == utils.ActionServiceAction {
+		var sType *protobuf.ServiceType
+		sType, err = aL.Db.ReadServiceType(action.Service)
+		if err != nil {
+			return utils.RunFail, err
+		}
+		playbook, actionVars, err = aL.ServiceActionPlaybook(sType, action)
+	} else {
+		playbook, actionVars, err = ActionPlaybook(action)
+	}
+	if err != nil {
+		return utils.RunFail, err
+	}
+
+	// upgraded or reconfigured service is saved with the new version and config only after the successful run
+	services := cluster.Services
+	if action.Action == utils.ActionUpgradeService || action.Action == utils.ActionReconfigureService {
+		cluster.Services = UpgradedServices(services, action)
+	}
+	newExtraVars, err := aL.MakeExtraVars(aL.Db, cluster, &aL.Config, dockRegCreds, action.Action)
+	upgraded := cluster.Services
+	cluster.Services = services
+	if err != nil {
+		return utils.RunFail, err
+	}
+	for name, value := range actionVars {
+		newExtraVars[name] = value
+	}
+
+	newAnsibleArgs, jErr := json.Marshal(newExtraVars)
+	if jErr != nil {
+		return utils.RunFail, ErrMarshal
+	}
+
+	cmdArgs := []string{"--extra-vars", string(newAnsibleArgs)}
+
+	aL.Logger.Info("Running ansible...")
+	sendPhase(send, utils.OperationPhaseAction)
+	outWriter := io.MultiWriter(clusterLogsWriter, NewProgressWriter(utils.OperationPhaseAction, send))
+	res, runErr := aL.runPlaybook(ctx, playbook, cmdArgs, outWriter)
+	if runErr != nil {
+		aL.Logger.Warn(runErr)
+		return utils.RunFail, runErr
+	}
+	if !res {
+		if serviceChanged(action.Action) {
+			SetServicesStatus(cluster.Services, action.Service, utils.StatusFailed)
+		}
+		aL.Logger.Info("Ansible has failed, check logs for more information.")
+		return utils.AnsibleFail, nil
+	}
+
+	// removed service is deleted from the cluster services
+	if action.Action == utils.ActionRemoveService {
+		RemoveClusterService(cluster, action.Service)
+	}
+	if action.Action == utils.ActionReconfigureService && action.Restart {
+		restartPlaybook := utils.AnsibleRestartRolePrefix + action.Service + ".yml"
+		if _, err := os.Stat(restartPlaybook); err == nil {
+			res, runErr = aL.runPlaybook(ctx, restartPlaybook, cmdArgs, outWriter)
+			if runErr != nil {
+				aL.Logger.Warn(runErr)
+				return utils.RunFail, runErr
+			}
+			if !res {
+				SetServicesStatus(cluster.Services, action.Service, utils.StatusFailed)
+				aL.Logger.Info("Ansible has failed, check logs for more information.")
+				return utils.AnsibleFail, nil
+			}
+		} else {
+			aL.Logger.Info("Service ", action.Service, " has no restart playbook, it is restarted by its role")
+		}
+	}
+	if action.Action == utils.ActionUpgradeService || action.Action == utils.ActionReconfigureService {
+		cluster.Services = upgraded
+	}
+	switch {
+	case action.Action == utils.ActionStop:
+		SetServicesStatus(cluster.Services, "", utils.StatusStopped)
+	case action.Action == utils.ActionStart:
+		SetServicesStatus(cluster.Services, "", utils.StatusActive)
+	case serviceChanged(action.Action):
+		SetServicesStatus(cluster.Services, action.Service, utils.StatusActive)
+	}
+
+	// instances actions change statuses of the nodes
+	if action.Action != utils.ActionRestartService && action.Action != utils.ActionServiceAction &&
+		action.Action != utils.ActionRemoveService && action.Action != utils.ActionUpgradeService &&
+		action.Action != utils.ActionReconfigureService {
+		nodes, err := aL.RunGetNodes(ctx, cluster, send)
+		if err != nil {
+			return utils.RunFail, err
+		}
+		cluster.Nodes = nodes
+	}
+
+	aL.Logger.Info("Action ", action.Action, ": OK")
+	return utils.AnsibleOk, nil
+}
+
+// RunRepair re-runs instances and services playbooks of the cluster to restore its drifted instances and services,
+// drift of the repaired cluster is cleared
+func (aL LauncherServer) RunRepair(ctx context.Context, cluster *protobuf.Cluster, dockRegCreds *utils.DockerCredentials, clusterLogsWriter io.Writer, send ProgressSender) (string, error) {
+	ansibleStatus, err := aL.RunInstances(ctx, cluster, dockRegCreds, utils.ActionUpdate, clusterLogsWriter, send)
+	if err != nil || ansibleStatus != utils.AnsibleOk {
+		return ansibleStatus, err
+	}
+
+	sTypes, err := aL.Db.ReadServicesTypesList()
+	if err != nil {
+		return utils.RunFail, err
+	}
+	ansibleStatus, err = aL.RunServices(ctx, cluster, dockRegCreds, utils.ActionUpdate, clusterLogsWriter, sTypes, send)
+	if err != nil || ansibleStatus != utils.AnsibleOk {
+		return ansibleStatus, err
+	}
+
+	cluster.Drift = nil
+	cluster.DriftCheckedAt = ""
+	return ansibleStatus, nil
+}
+
+// serviceChanged checks if the lifecycle action restarts or redeploys the service of the action
+func serviceChanged(action string) bool {
+	return action == utils.ActionRestartService || action == utils.ActionUpgradeService ||
+		action == utils.ActionReconfigureService
+}
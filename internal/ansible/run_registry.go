@@ -0,0 +1,133 @@
+package ansible
+
+import (
+	"context"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"sync"
+)
+
+// clusterRun describes ansible action which is currently running for the cluster
+type clusterRun struct {
+	action      string
+	done        chan struct{}
+	cancel      context.CancelFunc
+	subscribers []ProgressSender
+	status      *protobuf.TaskStatus
+	err         error
+}
+
+// runRegistry keeps actions running by the launcher
+type runRegistry struct {
+	mutex sync.Mutex
+	runs  map[string]*clusterRun
+}
+
+var clusterRuns = &runRegistry{runs: make(map[string]*clusterRun)}
+
+// broadcast sends progress event to all subscribers of the run
+func (r *runRegistry) broadcast(cRun *clusterRun, event *protobuf.ProgressEvent) {
+	r.mutex.Lock()
+	subscribers := append([]ProgressSender(nil), cRun.subscribers...)
+	r.mutex.Unlock()
+
+	for _, send := range subscribers {
+		send(event)
+	}
+}
+
+// cancel stops action running for the cluster, returns false if nothing is running
+func (r *runRegistry) cancel(clusterID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cRun, ok := r.runs[clusterID]
+	if !ok {
+		return false
+	}
+	cRun.cancel()
+	return true
+}
+
+// attachRun runs action for the cluster if there is no running one. If the same action
+// is already running for the cluster, it waits for it and returns its result, so requests
+// repeated by the rest service after its restart don't start ansible twice.
+// Progress events of the run are sent to send function if it is not nil, the first event
+// is sent as soon as the run is registered, so the cancel requested after it is not lost.
+// Cancelled run returns CANCELLED task status.
+func (aL *LauncherServer) attachRun(clusterID string, action string, send ProgressSender,
+	run func(ctx context.Context, send ProgressSender) (*protobuf.TaskStatus, error)) (*protobuf.TaskStatus, error) {
+	clusterRuns.mutex.Lock()
+	if cRun, ok := clusterRuns.runs[clusterID]; ok {
+		if cRun.action != action {
+			clusterRuns.mutex.Unlock()
+			return nil, ErrClusterBusy(clusterID, cRun.action)
+		}
+		if send != nil {
+			cRun.subscribers = append(cRun.subscribers, send)
+		}
+		clusterRuns.mutex.Unlock()
+		if send != nil {
+			sendStarted(send)
+		}
+
+		aL.Logger.Infof("Action %s for cluster %s is already running, waiting for it", action, clusterID)
+		<-cRun.done
+		return cRun.status, cRun.err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cRun := &clusterRun{action: action, done: make(chan struct{}), cancel: cancel}
+	if send != nil {
+		cRun.subscribers = append(cRun.subscribers, send)
+	}
+	clusterRuns.runs[clusterID] = cRun
+	clusterRuns.mutex.Unlock()
+	if send != nil {
+		sendStarted(send)
+	}
+
+	cRun.status, cRun.err = run(ctx, func(event *protobuf.ProgressEvent) {
+		clusterRuns.broadcast(cRun, event)
+	})
+	if ctx.Err() != nil {
+		aL.Logger.Infof("Action %s for cluster %s was cancelled", action, clusterID)
+		cRun.status, cRun.err = &protobuf.TaskStatus{Status: utils.AnsibleCancelled}, nil
+	}
+
+	clusterRuns.mutex.Lock()
+	delete(clusterRuns.runs, clusterID)
+	clusterRuns.mutex.Unlock()
+	close(cRun.done)
+
+	return cRun.status, cRun.err
+}
+
+// progressStream is implemented by the server side of every streaming launcher rpc
+type progressStream interface {
+	Send(event *protobuf.ProgressEvent) error
+}
+
+// streamRun runs action for the cluster and sends its progress to the stream.
+// Last event in the stream is a finish event with the task status of the run.
+func (aL *LauncherServer) streamRun(clusterID string, action string, stream progressStream,
+	run func(ctx context.Context, send ProgressSender) (*protobuf.TaskStatus, error)) error {
+	streamOk := true
+	send := func(event *protobuf.ProgressEvent) {
+		if !streamOk {
+			return
+		}
+		if err := stream.Send(event); err != nil {
+			aL.Logger.Warn(ErrStreamSend(clusterID, err))
+			streamOk = false
+		}
+	}
+
+	status, err := aL.attachRun(clusterID, action, send, run)
+	if err != nil {
+		return err
+	}
+
+	send(&protobuf.ProgressEvent{Type: utils.ProgressEventFinish, Status: status.Status})
+	return nil
+}
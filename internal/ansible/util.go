@@ -1,6 +1,7 @@
 package ansible
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +11,8 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/ispras/michman/internal/database"
@@ -19,6 +22,9 @@ import (
 
 const (
 	LauncherDefaultPort = "5000"
+
+	// time given to ansible for stopping after cancellation before it is killed
+	AnsibleStopTimeout = 30 * time.Second
 )
 
 type InterfaceMap map[string]interface{}
@@ -266,12 +272,17 @@ func (aL LauncherServer) MakeExtraVars(db database.Database, cluster *protobuf.C
 	}
 	extraVars["ansible_user"] = image.AnsibleUser
 	extraVars["hadoop_user"] = image.AnsibleUser
-	extraVars["os_image"] = image.CloudImageID
 	extraVars["skip_packages"] = false
-	extraVars["os_project_name"] = aL.OsCreds[utils.OsProjectName]
 
-	extraVars["floating_ip_pool"] = osConfig.FloatingIP
-	extraVars["os_auth_url"] = aL.OsCreds[utils.OsAuthUrl]
+	//image, network and credentials of the cloud provider
+	providerVars, err := aL.Provider.InstanceVars(cluster, image)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range providerVars {
+		extraVars[key] = value
+	}
+
 	extraVars["use_oracle_java"] = false //must be always false
 	extraVars["ansible_ssh_private_key_file"] = utils.SshKeyPath
 
@@ -283,12 +294,6 @@ func (aL LauncherServer) MakeExtraVars(db database.Database, cluster *protobuf.C
 		extraVars["act"] = utils.AnsibleDestroy
 	}
 
-	extraVars["virtual_network"] = osConfig.VirtualNetwork
-	extraVars["os_key_name"] = osConfig.Key
-
-	extraVars["os_swift_user_name"] = aL.OsCreds[utils.OsSwiftUserName]
-	extraVars["os_swift_password"] = aL.OsCreds[utils.OsSwiftPassword]
-
 	//make extra jars
 	//TODO: change this
 	var extraJars []map[string]string
@@ -381,34 +386,52 @@ func ValidateIP(input string) bool {
 	return regEx.FindString(input) != ""
 }
 
-func FindIP(input string) string {
-	numBlock := "(25[0-5]|2[0-4][0-9]|1[0-9][0-9]|[1-9]?[0-9])"
-	regexPattern := numBlock + "\\." + numBlock + "\\." + numBlock + "\\." + numBlock
-
-	regEx := regexp.MustCompile(regexPattern)
-	return regEx.FindString(input)
-}
-
 func SetServiceUrl(ip string, port int32) string {
 	return ip + ":" + fmt.Sprintf("%d", port)
 }
 
-func (aL LauncherServer) RunAnsible(cmd string, args []string, stdout io.Writer, stderr io.Writer) (bool, error) {
+// RunAnsible runs ansible command and terminates its whole process tree if the context is cancelled
+func (aL LauncherServer) RunAnsible(ctx context.Context, cmd string, args []string, stdout io.Writer, stderr io.Writer) (bool, error) {
+	if ctx.Err() != nil {
+		return false, ErrCmdCancelled
+	}
+
 	prepCmd := exec.Command(cmd, args...)
 	prepCmd.Stdout = stdout
 	prepCmd.Stderr = stderr
+	// own process group allows to stop ansible together with all its children
+	prepCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// cloud provider credentials are passed only to the ansible process
+	prepCmd.Env = append(os.Environ(), utils.AnsibleConfigVar+"="+utils.AnsibleConfigPath)
+	prepCmd.Env = append(prepCmd.Env, aL.Provider.Env()...)
 
-	err := os.Setenv(utils.AnsibleConfigVar, utils.AnsibleConfigPath)
-	if err != nil {
-		return false, ErrSetEnv
-	}
-
-	err = prepCmd.Start()
+	err := prepCmd.Start()
 	if err != nil {
 		return false, ErrCmdStart
 	}
 
+	waitDone := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			pgid := prepCmd.Process.Pid
+			aL.Logger.Infof("Terminating ansible process group %d...", pgid)
+			_ = syscall.Kill(-pgid, syscall.SIGTERM)
+			select {
+			case <-waitDone:
+			case <-time.After(AnsibleStopTimeout):
+				aL.Logger.Warnf("Ansible process group %d is still running, killing it", pgid)
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			}
+		case <-waitDone:
+		}
+	}()
+
 	err = prepCmd.Wait()
+	close(waitDone)
+	if ctx.Err() != nil {
+		return false, ErrCmdCancelled
+	}
 	if err != nil {
 		return false, ErrCmdWait
 	}
@@ -421,22 +444,8 @@ func MakeOsCreds(keyName string, vaultClient *vaultapi.Client, version string) (
 		return nil, ErrCouchSecretsRead
 	}
 	osCreds := make(utils.OsCredentials)
-	var resVersion string
-	_, exists := utils.OpenstackSecretsKeys[version]
-	if exists {
-		resVersion = version
-	} else {
-		resVersion = utils.OsUssuriVersion
-	}
-	for key, value := range utils.OpenstackSecretsKeys[resVersion] {
-		osCred := secretValues.Data[value].(string)
-		if osCred != "" {
-			err := os.Setenv(value, osCred)
-			if err != nil {
-				return nil, ErrSetEnv
-			}
-		}
-		osCreds[key] = osCred
+	for key, value := range utils.OpenstackSecretsKeys[osVersion(version)] {
+		osCreds[key] = secretValues.Data[value].(string)
 	}
 	return osCreds, nil
 }
@@ -14,6 +14,16 @@ const (
 	serviceTypeBucketName string = "service_types"
 	imageBucketName       string = "images"
 	flavorBucketName      string = "flavors"
+	operationBucketName   string = "operations"
+	cloudBucketName       string = "clouds"
+	autoscalingBucketName string = "autoscaling_policies"
+	decisionBucketName    string = "scaling_decisions"
+	usageBucketName       string = "usage_records"
+	webhookBucketName     string = "webhooks"
+	deliveryBucketName    string = "webhook_deliveries"
+
+	// casRetries limits attempts to replace the document changed after it was read
+	casRetries = 3
 )
 
 type CouchDatabase struct {
@@ -25,6 +35,13 @@ type CouchDatabase struct {
 	serviceTypesBucket *gocb.Bucket
 	imageBucket        *gocb.Bucket
 	flavorBucket       *gocb.Bucket
+	operationsBucket   *gocb.Bucket
+	cloudsBucket       *gocb.Bucket
+	autoscalingBucket  *gocb.Bucket
+	decisionsBucket    *gocb.Bucket
+	usageBucket        *gocb.Bucket
+	webhooksBucket     *gocb.Bucket
+	deliveriesBucket   *gocb.Bucket
 	VaultCommunicator  utils.SecretStorage
 }
 
@@ -96,6 +113,48 @@ func NewCouchBase(vaultCom utils.SecretStorage) (Database, error) {
 	}
 	couchbase.flavorBucket = bucket
 
+	bucket, err = couchbase.couchCluster.OpenBucket(operationBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("operation")
+	}
+	couchbase.operationsBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(cloudBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("cloud")
+	}
+	couchbase.cloudsBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(autoscalingBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("autoscaling policy")
+	}
+	couchbase.autoscalingBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(decisionBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("scaling decision")
+	}
+	couchbase.decisionsBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(usageBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("usage record")
+	}
+	couchbase.usageBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(webhookBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("webhook")
+	}
+	couchbase.webhooksBucket = bucket
+
+	bucket, err = couchbase.couchCluster.OpenBucket(deliveryBucketName, "")
+	if err != nil {
+		return nil, ErrOpenParamBucket("webhook delivery")
+	}
+	couchbase.deliveriesBucket = bucket
+
 	return couchbase, nil
 }
 
@@ -386,6 +445,94 @@ func (db CouchDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 	return nil
 }
 
+// updateClusterDoc changes the saved cluster with the update function and replaces it only if
+// the cluster document is not changed meanwhile, update returns false to leave the cluster as it is
+func (db CouchDatabase) updateClusterDoc(clusterId string, update func(cluster *protobuf.Cluster) bool) (bool, error) {
+	for attempt := 0; attempt < casRetries; attempt++ {
+		var cluster protobuf.Cluster
+		cas, err := db.clustersBucket.Get(clusterId, &cluster)
+		if err != nil {
+			if err == gocb.ErrKeyNotFound {
+				return false, ErrObjectNotFound("cluster", clusterId)
+			}
+			return false, ErrReadObjectByKey
+		}
+		if !update(&cluster) {
+			return false, nil
+		}
+		_, err = db.clustersBucket.Replace(clusterId, &cluster, cas, 0)
+		if err == nil {
+			return true, nil
+		}
+		// cluster is changed after it was read
+		if err != gocb.ErrKeyExists {
+			return false, ErrUpdateObjectByKey
+		}
+	}
+	return false, ErrUpdateObjectByKey
+}
+
+// UpdateClusterStatus changes status of the cluster only if it is still in the fromStatus,
+// returns false if the cluster status was changed meanwhile
+func (db CouchDatabase) UpdateClusterStatus(clusterId string, fromStatus string, toStatus string) (bool, error) {
+	return db.updateClusterDoc(clusterId, func(cluster *protobuf.Cluster) bool {
+		if cluster.EntityStatus != fromStatus {
+			return false
+		}
+		cluster.EntityStatus = toStatus
+		return true
+	})
+}
+
+// UpdateClusterExpiryWarning saves the time when the cluster owner was warned about the cluster expiry
+// only if the cluster lease is not changed, returns false if the lease was changed meanwhile
+func (db CouchDatabase) UpdateClusterExpiryWarning(clusterId string, expiresAt string, warnedAt string) (bool, error) {
+	return db.updateClusterDoc(clusterId, func(cluster *protobuf.Cluster) bool {
+		if cluster.ExpiresAt != expiresAt {
+			return false
+		}
+		cluster.ExpiryWarnedAt = warnedAt
+		return true
+	})
+}
+
+// UpdateClusterHealth saves only health of the cluster and its services,
+// services removed from the cluster meanwhile are not saved again
+func (db CouchDatabase) UpdateClusterHealth(cluster *protobuf.Cluster) error {
+	health := make(map[string]*protobuf.Service)
+	for _, s := range cluster.Services {
+		health[s.ID] = s
+	}
+	_, err := db.updateClusterDoc(cluster.ID, func(saved *protobuf.Cluster) bool {
+		saved.Health = cluster.Health
+		saved.HealthCheckedAt = cluster.HealthCheckedAt
+		for _, s := range saved.Services {
+			if checked, ok := health[s.ID]; ok {
+				s.Health = checked.Health
+				s.HealthOutput = checked.HealthOutput
+				s.HealthCheckedAt = checked.HealthCheckedAt
+			}
+		}
+		return true
+	})
+	return err
+}
+
+// UpdateClusterDrift saves only drift, health and status of the cluster if it is still in the fromStatus,
+// returns false if the cluster status was changed meanwhile
+func (db CouchDatabase) UpdateClusterDrift(cluster *protobuf.Cluster, fromStatus string) (bool, error) {
+	return db.updateClusterDoc(cluster.ID, func(saved *protobuf.Cluster) bool {
+		if saved.EntityStatus != fromStatus {
+			return false
+		}
+		saved.Drift = cluster.Drift
+		saved.DriftCheckedAt = cluster.DriftCheckedAt
+		saved.Health = cluster.Health
+		saved.EntityStatus = cluster.EntityStatus
+		return true
+	})
+}
+
 func (db CouchDatabase) DeleteCluster(projectIdOrName, clusterIdOrName string) error {
 	isUuid := utils.IsUuid(clusterIdOrName)
 	var err error
@@ -397,6 +544,79 @@ func (db CouchDatabase) DeleteCluster(projectIdOrName, clusterIdOrName string) e
 	return err
 }
 
+// operation:
+
+func (db CouchDatabase) ReadOperation(operationId string) (*protobuf.Operation, error) {
+	var operation protobuf.Operation
+	_, err := db.operationsBucket.Get(operationId, &operation)
+	if err != nil {
+		if err == gocb.ErrKeyNotFound {
+			return nil, ErrObjectNotFound("operation", operationId)
+		}
+		return nil, ErrReadObjectByKey
+	}
+	return &operation, nil
+}
+
+func (db CouchDatabase) ReadOperationsList() ([]protobuf.Operation, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b ORDER BY CreatedAt", operationBucketName)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.Operation
+	var result []protobuf.Operation
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.Operation{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) ReadClusterOperations(clusterId string) ([]protobuf.Operation, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE ClusterID = '%s' ORDER BY CreatedAt", operationBucketName, clusterId)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.Operation
+	var result []protobuf.Operation
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.Operation{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) WriteOperation(operation *protobuf.Operation) error {
+	_, err := db.operationsBucket.Upsert(operation.ID, operation, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) UpdateOperation(operation *protobuf.Operation) error {
+	var cas gocb.Cas
+	_, err := db.operationsBucket.Replace(operation.ID, operation, cas, 0)
+	if err != nil {
+		return ErrUpdateObjectByKey
+	}
+	return nil
+}
+
 // service type:
 
 func readServiceTypeById(db CouchDatabase, serviceTypeID string) (*protobuf.ServiceType, error) {
@@ -940,6 +1160,308 @@ func (db CouchDatabase) DeleteFlavor(flavorIdOrName string) error {
 	return err
 }
 
+// cloud:
+
+func readCloudById(db CouchDatabase, cloudID string) (*protobuf.Cloud, error) {
+	var cloud protobuf.Cloud
+	_, err := db.cloudsBucket.Get(cloudID, &cloud)
+	if err != nil {
+		if err == gocb.ErrKeyNotFound {
+			return nil, ErrObjectNotFound("cloud", cloudID)
+		}
+		return nil, ErrReadObjectByKey
+	}
+	return &cloud, nil
+}
+
+func readCloudByName(db CouchDatabase, cloudName string) (*protobuf.Cloud, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE Name = '%s'", cloudBucketName, cloudName)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	defer rows.Close()
+	var cloud protobuf.Cloud
+	rows.Next(&cloud)
+	if cloud.ID == "" {
+		return nil, ErrObjectNotFound("cloud", cloudName)
+	}
+	return &cloud, nil
+}
+
+func (db CouchDatabase) ReadCloud(cloudIdOrName string) (*protobuf.Cloud, error) {
+	if utils.IsUuid(cloudIdOrName) {
+		return readCloudById(db, cloudIdOrName)
+	}
+	return readCloudByName(db, cloudIdOrName)
+}
+
+func (db CouchDatabase) ReadCloudsList() ([]protobuf.Cloud, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b", cloudBucketName)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.Cloud
+	var result []protobuf.Cloud
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.Cloud{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+
+	return result, nil
+}
+
+func (db CouchDatabase) WriteCloud(cloud *protobuf.Cloud) error {
+	_, err := db.cloudsBucket.Upsert(cloud.ID, cloud, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) UpdateCloud(cloud *protobuf.Cloud) error {
+	var cas gocb.Cas
+	_, err := db.cloudsBucket.Replace(cloud.ID, cloud, cas, 0)
+	if err != nil {
+		return ErrUpdateObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) DeleteCloud(cloudIdOrName string) error {
+	cloud, err := db.ReadCloud(cloudIdOrName)
+	if err != nil {
+		return err
+	}
+	_, err = db.cloudsBucket.Remove(cloud.ID, 0)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+// autoscaling:
+
+func (db CouchDatabase) ReadAutoscalingPolicy(clusterId string) (*protobuf.AutoscalingPolicy, error) {
+	var policy protobuf.AutoscalingPolicy
+	_, err := db.autoscalingBucket.Get(clusterId, &policy)
+	if err != nil {
+		if err == gocb.ErrKeyNotFound {
+			return nil, ErrObjectNotFound("autoscaling policy", clusterId)
+		}
+		return nil, ErrReadObjectByKey
+	}
+	return &policy, nil
+}
+
+func (db CouchDatabase) ReadAutoscalingPoliciesList() ([]protobuf.AutoscalingPolicy, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b", autoscalingBucketName)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.AutoscalingPolicy
+	var result []protobuf.AutoscalingPolicy
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.AutoscalingPolicy{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) WriteAutoscalingPolicy(policy *protobuf.AutoscalingPolicy) error {
+	_, err := db.autoscalingBucket.Upsert(policy.ClusterID, policy, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) DeleteAutoscalingPolicy(clusterId string) error {
+	_, err := db.autoscalingBucket.Remove(clusterId, 0)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) ReadClusterScalingDecisions(clusterId string) ([]protobuf.ScalingDecision, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE ClusterID = '%s' ORDER BY CreatedAt", decisionBucketName, clusterId)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.ScalingDecision
+	var result []protobuf.ScalingDecision
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.ScalingDecision{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) WriteScalingDecision(decision *protobuf.ScalingDecision) error {
+	_, err := db.decisionsBucket.Upsert(decision.ID, decision, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+// usage:
+
+func (db CouchDatabase) readUsageRecords(q string) ([]protobuf.UsageRecord, error) {
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.UsageRecord
+	var result []protobuf.UsageRecord
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.UsageRecord{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) ReadUsageRecords(projectId string, from string, to string) ([]protobuf.UsageRecord, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE StartedAt < '%s' AND (EndedAt IS MISSING OR EndedAt = '' OR EndedAt > '%s')",
+		usageBucketName, to, from)
+	if projectId != "" {
+		q += fmt.Sprintf(" AND ProjectID = '%s'", projectId)
+	}
+	return db.readUsageRecords(q + " ORDER BY StartedAt")
+}
+
+func (db CouchDatabase) ReadClusterOpenUsageRecords(clusterId string) ([]protobuf.UsageRecord, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE ClusterID = '%s' AND (EndedAt IS MISSING OR EndedAt = '')",
+		usageBucketName, clusterId)
+	return db.readUsageRecords(q)
+}
+
+func (db CouchDatabase) WriteUsageRecord(record *protobuf.UsageRecord) error {
+	_, err := db.usageBucket.Upsert(record.ID, record, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+// webhooks:
+
+func (db CouchDatabase) ReadWebhook(webhookId string) (*protobuf.Webhook, error) {
+	var webhook protobuf.Webhook
+	_, err := db.webhooksBucket.Get(webhookId, &webhook)
+	if err != nil {
+		if err == gocb.ErrKeyNotFound {
+			return nil, ErrObjectNotFound("webhook", webhookId)
+		}
+		return nil, ErrReadObjectByKey
+	}
+	return &webhook, nil
+}
+
+func (db CouchDatabase) ReadProjectWebhooks(projectId string) ([]protobuf.Webhook, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE ProjectID = '%s' ORDER BY CreatedAt", webhookBucketName, projectId)
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.Webhook
+	var result []protobuf.Webhook
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.Webhook{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) WriteWebhook(webhook *protobuf.Webhook) error {
+	_, err := db.webhooksBucket.Upsert(webhook.ID, webhook, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) DeleteWebhook(webhookId string) error {
+	_, err := db.webhooksBucket.Remove(webhookId, 0)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+func (db CouchDatabase) ReadWebhookDeliveries(webhookId string) ([]protobuf.WebhookDelivery, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE WebhookID = '%s' ORDER BY CreatedAt", deliveryBucketName, webhookId)
+	return db.queryWebhookDeliveries(q)
+}
+
+func (db CouchDatabase) ReadWebhookDeliveriesByStatus(status string) ([]protobuf.WebhookDelivery, error) {
+	q := fmt.Sprintf("SELECT b.* FROM %s b WHERE Status = '%s' ORDER BY CreatedAt", deliveryBucketName, status)
+	return db.queryWebhookDeliveries(q)
+}
+
+func (db CouchDatabase) queryWebhookDeliveries(q string) ([]protobuf.WebhookDelivery, error) {
+	query := gocb.NewN1qlQuery(q)
+	rows, err := db.couchCluster.ExecuteN1qlQuery(query, []interface{}{})
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	var row protobuf.WebhookDelivery
+	var result []protobuf.WebhookDelivery
+
+	for rows.Next(&row) {
+		result = append(result, row)
+		row = protobuf.WebhookDelivery{}
+	}
+	err = rows.Close()
+	if err != nil {
+		return nil, ErrCloseQuerySession
+	}
+	return result, nil
+}
+
+func (db CouchDatabase) WriteWebhookDelivery(delivery *protobuf.WebhookDelivery) error {
+	_, err := db.deliveriesBucket.Upsert(delivery.ID, delivery, 0)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
 // template:
 
 func (db CouchDatabase) WriteTemplate(template *protobuf.Template) error {
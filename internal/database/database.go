@@ -9,8 +9,18 @@ type Database interface {
 	WriteCluster(cluster *protobuf.Cluster) error
 	DeleteCluster(projectIdOrName, clusterIdOrName string) error
 	UpdateCluster(cluster *protobuf.Cluster) error
+	UpdateClusterStatus(clusterId string, fromStatus string, toStatus string) (bool, error)
+	UpdateClusterExpiryWarning(clusterId string, expiresAt string, warnedAt string) (bool, error)
+	UpdateClusterHealth(cluster *protobuf.Cluster) error
+	UpdateClusterDrift(cluster *protobuf.Cluster, fromStatus string) (bool, error)
 	ReadClustersList() ([]protobuf.Cluster, error)
 
+	ReadOperation(operationId string) (*protobuf.Operation, error)
+	ReadOperationsList() ([]protobuf.Operation, error)
+	ReadClusterOperations(clusterId string) ([]protobuf.Operation, error)
+	WriteOperation(operation *protobuf.Operation) error
+	UpdateOperation(operation *protobuf.Operation) error
+
 	ReadProject(projectIdOrName string) (*protobuf.Project, error)
 	ReadProjectsList() ([]protobuf.Project, error)
 	ReadProjectClusters(projectIdOrName string) ([]protobuf.Cluster, error)
@@ -49,4 +59,31 @@ type Database interface {
 	DeleteFlavor(flavorName string) error
 	UpdateFlavor(name string, Flavor *protobuf.Flavor) error
 	ReadFlavorsList() ([]protobuf.Flavor, error)
+
+	ReadCloud(cloudIdOrName string) (*protobuf.Cloud, error)
+	WriteCloud(cloud *protobuf.Cloud) error
+	DeleteCloud(cloudIdOrName string) error
+	UpdateCloud(cloud *protobuf.Cloud) error
+	ReadCloudsList() ([]protobuf.Cloud, error)
+
+	ReadAutoscalingPolicy(clusterId string) (*protobuf.AutoscalingPolicy, error)
+	ReadAutoscalingPoliciesList() ([]protobuf.AutoscalingPolicy, error)
+	WriteAutoscalingPolicy(policy *protobuf.AutoscalingPolicy) error
+	DeleteAutoscalingPolicy(clusterId string) error
+
+	ReadClusterScalingDecisions(clusterId string) ([]protobuf.ScalingDecision, error)
+	WriteScalingDecision(decision *protobuf.ScalingDecision) error
+
+	ReadUsageRecords(projectId string, from string, to string) ([]protobuf.UsageRecord, error)
+	ReadClusterOpenUsageRecords(clusterId string) ([]protobuf.UsageRecord, error)
+	WriteUsageRecord(record *protobuf.UsageRecord) error
+
+	ReadWebhook(webhookId string) (*protobuf.Webhook, error)
+	ReadProjectWebhooks(projectId string) ([]protobuf.Webhook, error)
+	WriteWebhook(webhook *protobuf.Webhook) error
+	DeleteWebhook(webhookId string) error
+
+	ReadWebhookDeliveries(webhookId string) ([]protobuf.WebhookDelivery, error)
+	ReadWebhookDeliveriesByStatus(status string) ([]protobuf.WebhookDelivery, error)
+	WriteWebhookDelivery(delivery *protobuf.WebhookDelivery) error
 }
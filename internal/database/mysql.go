@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
@@ -72,16 +73,19 @@ func readClusterbyId(db MySqlDatabase, id string) (*protobuf.Cluster, error) {
 	q := `SELECT
     		ID, Name, DisplayName, HostURL, EntityStatus, ClusterType,
     		NSlaves, MasterIP, ProjectID, Description, Image, Monitoring,
-    		MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys
+    		MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys, COALESCE(Cloud, ''),
+			COALESCE(TTL, 0), COALESCE(ExpiresAt, ''), COALESCE(ExpiryWarnedAt, ''),
+			COALESCE(Health, ''), COALESCE(HealthCheckedAt, ''), Drift, COALESCE(DriftCheckedAt, '')
 		FROM cluster 
 		WHERE ID = ?`
 
 	c := protobuf.Cluster{ID: "", Name: "", DisplayName: ""}
-	var ssh_keys []byte
+	var ssh_keys, drift []byte
 	res := db.connection.QueryRow(q, id)
 	if err := res.Scan(&c.ID, &c.Name, &c.DisplayName, &c.HostURL, &c.EntityStatus, &c.ClusterType,
 		&c.NSlaves, &c.MasterIP, &c.ProjectID, &c.Description, &c.Image, &c.Monitoring,
-		&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys); err != nil {
+		&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys, &c.Cloud,
+		&c.TTL, &c.ExpiresAt, &c.ExpiryWarnedAt, &c.Health, &c.HealthCheckedAt, &drift, &c.DriftCheckedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("cluster", id)
 		}
@@ -94,10 +98,17 @@ func readClusterbyId(db MySqlDatabase, id string) (*protobuf.Cluster, error) {
 			return nil, ErrUnmarshalJson
 		}
 	}
+	if len(drift) > 0 {
+		err := json.Unmarshal(drift, &c.Drift)
+		if err != nil {
+			return nil, ErrUnmarshalJson
+		}
+	}
 	//get service for cluster
 	sq := `SELECT ID, Name, Type, ClusterRef, COALESCE(Config,''), DisplayName, 
 		COALESCE(EntityStatus,''),  Version, COALESCE(URL, ''),  
-		COALESCE(Description, '')  FROM service WHERE ClusterRef = ?`
+		COALESCE(Description, ''), COALESCE(Health, ''), COALESCE(HealthOutput, ''), COALESCE(HealthCheckedAt, '')
+		FROM service WHERE ClusterRef = ?`
 	srows, err := db.connection.Query(sq, c.ID)
 	if err != nil {
 		return nil, ErrReadIncludedObject("service", "cluster", c.ID)
@@ -111,7 +122,8 @@ func readClusterbyId(db MySqlDatabase, id string) (*protobuf.Cluster, error) {
 		var s protobuf.Service
 		var config string
 		if err := srows.Scan(&s.ID, &s.Name, &s.Type, &s.ClusterRef, &config, &s.DisplayName,
-			&s.EntityStatus, &s.Version, &s.URL, &s.Description); err != nil {
+			&s.EntityStatus, &s.Version, &s.URL, &s.Description,
+			&s.Health, &s.HealthOutput, &s.HealthCheckedAt); err != nil {
 			return nil, ErrScanRows
 		}
 		err = json.Unmarshal([]byte(config), &s.Config)
@@ -125,6 +137,12 @@ func readClusterbyId(db MySqlDatabase, id string) (*protobuf.Cluster, error) {
 	//add srvice array to cluster structure
 	c.Services = ss
 
+	nodes, err := readClusterNodes(db, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	c.Nodes = nodes
+
 	return &c, nil
 }
 
@@ -133,16 +151,19 @@ func readClusterbyName(db MySqlDatabase, name string) (*protobuf.Cluster, error)
 	q := `SELECT 
     		ID, Name, DisplayName, HostURL, EntityStatus, ClusterType, 
     		NSlaves, MasterIP, ProjectID, Description, Image, Monitoring,
-    		MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys 
+    		MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys, COALESCE(Cloud, ''),
+			COALESCE(TTL, 0), COALESCE(ExpiresAt, ''), COALESCE(ExpiryWarnedAt, ''),
+			COALESCE(Health, ''), COALESCE(HealthCheckedAt, ''), Drift, COALESCE(DriftCheckedAt, '')
 		FROM cluster
 		WHERE Name = ?`
 
 	c := protobuf.Cluster{ID: "", Name: "", DisplayName: ""}
-	var ssh_keys []byte
+	var ssh_keys, drift []byte
 	res := db.connection.QueryRow(q, name)
 	if err := res.Scan(&c.ID, &c.Name, &c.DisplayName, &c.HostURL, &c.EntityStatus, &c.ClusterType,
 		&c.NSlaves, &c.MasterIP, &c.ProjectID, &c.Description, &c.Image, &c.Monitoring,
-		&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys); err != nil {
+		&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys, &c.Cloud,
+		&c.TTL, &c.ExpiresAt, &c.ExpiryWarnedAt, &c.Health, &c.HealthCheckedAt, &drift, &c.DriftCheckedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("cluster", name)
 		}
@@ -155,11 +176,18 @@ func readClusterbyName(db MySqlDatabase, name string) (*protobuf.Cluster, error)
 			return nil, ErrUnmarshalJson
 		}
 	}
+	if len(drift) > 0 {
+		err := json.Unmarshal(drift, &c.Drift)
+		if err != nil {
+			return nil, ErrUnmarshalJson
+		}
+	}
 
 	//get service for cluster
 	sq := `SELECT ID, Name, Type, ClusterRef, COALESCE(Config,''), DisplayName, 
 		COALESCE(EntityStatus,''),  Version, COALESCE(URL, ''),  
-		COALESCE(Description, '')  FROM service WHERE ClusterRef = ?`
+		COALESCE(Description, ''), COALESCE(Health, ''), COALESCE(HealthOutput, ''), COALESCE(HealthCheckedAt, '')
+		FROM service WHERE ClusterRef = ?`
 	srows, err := db.connection.Query(sq, c.ID)
 	if err != nil {
 		return nil, ErrQueryExecution
@@ -174,7 +202,8 @@ func readClusterbyName(db MySqlDatabase, name string) (*protobuf.Cluster, error)
 		var s protobuf.Service
 		var config string
 		if err := srows.Scan(&s.ID, &s.Name, &s.Type, &s.ClusterRef, &config, &s.DisplayName,
-			&s.EntityStatus, &s.Version, &s.URL, &s.Description); err != nil {
+			&s.EntityStatus, &s.Version, &s.URL, &s.Description,
+			&s.Health, &s.HealthOutput, &s.HealthCheckedAt); err != nil {
 			return nil, ErrScanRows
 		}
 		err = json.Unmarshal([]byte(config), &s.Config)
@@ -188,6 +217,12 @@ func readClusterbyName(db MySqlDatabase, name string) (*protobuf.Cluster, error)
 	//add srvice array to cluster structure
 	c.Services = ss
 
+	nodes, err := readClusterNodes(db, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	c.Nodes = nodes
+
 	return &c, nil
 }
 
@@ -202,26 +237,33 @@ func (db MySqlDatabase) WriteCluster(cluster *protobuf.Cluster) error {
 	q := `INSERT INTO cluster (
                      ID, Name, DisplayName, HostURL, EntityStatus, ClusterType, 
                      NSlaves, MasterIP, ProjectID, Description, Image, Monitoring,
-                     MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys
-        ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
+                     MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys, Cloud,
+                     TTL, ExpiresAt, ExpiryWarnedAt, Health, HealthCheckedAt, Drift, DriftCheckedAt
+        ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 	ssh_keys, err := json.Marshal(cluster.Keys)
 	if err != nil {
 		return ErrUnmarshalJson
 	}
+	drift, err := json.Marshal(cluster.Drift)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
 
 	_, err = tx.Exec(
 		q, cluster.ID, cluster.Name, cluster.DisplayName, cluster.HostURL, cluster.EntityStatus, cluster.ClusterType,
 		cluster.NSlaves, cluster.MasterIP, cluster.ProjectID, cluster.Description, cluster.Image, cluster.Monitoring,
-		cluster.MasterFlavor, cluster.SlavesFlavor, cluster.StorageFlavor, cluster.MonitoringFlavor, ssh_keys)
+		cluster.MasterFlavor, cluster.SlavesFlavor, cluster.StorageFlavor, cluster.MonitoringFlavor, ssh_keys, cluster.Cloud,
+		cluster.TTL, cluster.ExpiresAt, cluster.ExpiryWarnedAt, cluster.Health, cluster.HealthCheckedAt,
+		drift, cluster.DriftCheckedAt)
 	if err != nil {
 		return ErrTransactionQuery
 	}
 	for _, s := range cluster.Services {
 		sq := `INSERT INTO service (
                      ID, Name, Type, ClusterRef, Config, DisplayName, 
-                     EntityStatus,  Version, URL, Description
-            ) VALUES (?,?,?,?,?,?,?,?,?,?)`
+                     EntityStatus,  Version, URL, Description, Health, HealthOutput, HealthCheckedAt
+            ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 		sConfig, err := json.Marshal(s.Config)
 		if err != nil {
@@ -230,12 +272,17 @@ func (db MySqlDatabase) WriteCluster(cluster *protobuf.Cluster) error {
 
 		_, err = tx.Exec(
 			sq, s.ID, s.Name, s.Type, cluster.ID, string(sConfig), s.DisplayName,
-			s.EntityStatus, s.Version, s.URL, s.Description)
+			s.EntityStatus, s.Version, s.URL, s.Description, s.Health, s.HealthOutput, s.HealthCheckedAt)
 		if err != nil {
 			return ErrTransactionQuery
 		}
 	}
 
+	err = writeClusterNodes(tx, cluster)
+	if err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return ErrTransactionCommit
 	}
@@ -256,9 +303,15 @@ func (db MySqlDatabase) DeleteCluster(projectIdOrName string, clusterIdOrName st
 }
 
 func deleteClusterbyId(db MySqlDatabase, id string) error {
+	nq := `DELETE FROM node WHERE ClusterRef = ?`
+	_, err := db.connection.Exec(nq, id)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+
 	q := `DELETE FROM cluster WHERE ID = ?`
 
-	_, err := db.connection.Exec(q, id)
+	_, err = db.connection.Exec(q, id)
 	if err != nil {
 		return ErrDeleteObjectByKey
 	}
@@ -267,9 +320,15 @@ func deleteClusterbyId(db MySqlDatabase, id string) error {
 }
 
 func deleteClusterbyName(db MySqlDatabase, name string) error {
+	nq := `DELETE FROM node WHERE ClusterRef IN (SELECT ID FROM cluster WHERE Name = ?)`
+	_, err := db.connection.Exec(nq, name)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+
 	q := `DELETE FROM cluster WHERE Name = ?`
 
-	_, err := db.connection.Exec(q, name)
+	_, err = db.connection.Exec(q, name)
 	if err != nil {
 		return ErrDeleteObjectByKey
 	}
@@ -278,6 +337,53 @@ func deleteClusterbyName(db MySqlDatabase, name string) error {
 
 }
 
+// readClusterNodes reads nodes of the cluster sorted by name
+func readClusterNodes(db MySqlDatabase, clusterID string) ([]*protobuf.Node, error) {
+	q := `SELECT Name, Role, COALESCE(PrivateIP, ''), COALESCE(FloatingIP, ''), COALESCE(IPv6, ''),
+			COALESCE(Flavor, ''), COALESCE(Image, ''), COALESCE(Status, '')
+		FROM node WHERE ClusterRef = ? ORDER BY Name`
+	rows, err := db.connection.Query(q, clusterID)
+	if err != nil {
+		return nil, ErrReadIncludedObject("node", "cluster", clusterID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrQueryRows
+	}
+	defer rows.Close()
+
+	var nodes []*protobuf.Node
+	for rows.Next() {
+		var n protobuf.Node
+		if err := rows.Scan(&n.Name, &n.Role, &n.PrivateIP, &n.FloatingIP, &n.IPv6,
+			&n.Flavor, &n.Image, &n.Status); err != nil {
+			return nil, ErrScanRows
+		}
+		nodes = append(nodes, &n)
+	}
+	return nodes, nil
+}
+
+// writeClusterNodes replaces nodes of the cluster in the transaction
+func writeClusterNodes(tx *sql.Tx, cluster *protobuf.Cluster) error {
+	dq := `DELETE FROM node WHERE ClusterRef = ?`
+	_, err := tx.Exec(dq, cluster.ID)
+	if err != nil {
+		return ErrTransactionQuery
+	}
+
+	for _, n := range cluster.Nodes {
+		q := `INSERT INTO node (
+                     ClusterRef, Name, Role, PrivateIP, FloatingIP, IPv6, Flavor, Image, Status
+            ) VALUES (?,?,?,?,?,?,?,?,?)`
+		_, err = tx.Exec(q, cluster.ID, n.Name, n.Role, n.PrivateIP, n.FloatingIP, n.IPv6,
+			n.Flavor, n.Image, n.Status)
+		if err != nil {
+			return ErrTransactionQuery
+		}
+	}
+	return nil
+}
+
 func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 	tx, err := db.connection.Begin()
 	if err != nil {
@@ -286,6 +392,23 @@ func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 
 	//rollback in case of error
 	defer tx.Rollback()
+
+	//delete services removed from the cluster
+	serviceIds := []interface{}{cluster.ID}
+	dsq := `DELETE FROM service WHERE ClusterRef = ?`
+	for _, s := range cluster.Services {
+		if s.ID != "" {
+			serviceIds = append(serviceIds, s.ID)
+		}
+	}
+	if len(serviceIds) > 1 {
+		dsq += ` AND ID NOT IN (?` + strings.Repeat(`,?`, len(serviceIds)-2) + `)`
+	}
+	_, err = tx.Exec(dsq, serviceIds...)
+	if err != nil {
+		return ErrTransactionQuery
+	}
+
 	for _, s := range cluster.Services { //replace because there might be new services for cluster
 		sq := `SELECT Name FROM service WHERE ID = ?`
 		res := db.connection.QueryRow(sq, s.ID)
@@ -293,12 +416,16 @@ func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 		if err := res.Scan(&sId); err != nil {
 			if err == sql.ErrNoRows {
 				scq := `INSERT INTO service (
-                     		ID, Name, Type, ClusterRef, Config, DisplayName, EntityStatus,  Version, URL, Description
-                     	) VALUES (?,?,?,?,?,?,?,?,?,?)`
+                     		ID, Name, Type, ClusterRef, Config, DisplayName, EntityStatus,  Version, URL, Description,
+                     		Health, HealthOutput, HealthCheckedAt
+                     	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
-				sId, err := uuid.NewRandom()
-				if err != nil {
-					return ErrNewUuid
+				if s.ID == "" {
+					sId, err := uuid.NewRandom()
+					if err != nil {
+						return ErrNewUuid
+					}
+					s.ID = sId.String()
 				}
 
 				sConfig, err := json.Marshal(s.Config)
@@ -307,8 +434,9 @@ func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 				}
 
 				_, err = tx.Exec(
-					scq, sId.String(), s.Name, s.Type, cluster.ID, string(sConfig),
-					s.DisplayName, s.EntityStatus, s.Version, s.URL, s.Description)
+					scq, s.ID, s.Name, s.Type, cluster.ID, string(sConfig),
+					s.DisplayName, s.EntityStatus, s.Version, s.URL, s.Description,
+					s.Health, s.HealthOutput, s.HealthCheckedAt)
 				if err != nil {
 					return ErrTransactionQuery
 				}
@@ -317,12 +445,20 @@ func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 			}
 		} else {
 			suq := `UPDATE service SET 
-						   Name = ?, Type = ?, ClusterRef = ?, DisplayName = ?, 
-						   EntityStatus = ?, Version = ?, URL = ?, Description = ?
+						   Name = ?, Type = ?, ClusterRef = ?, Config = ?, DisplayName = ?, 
+						   EntityStatus = ?, Version = ?, URL = ?, Description = ?,
+						   Health = ?, HealthOutput = ?, HealthCheckedAt = ?
                			WHERE ID = ?`
+
+			sConfig, err := json.Marshal(s.Config)
+			if err != nil {
+				return ErrUnmarshalJson
+			}
+
 			_, err = tx.Exec(
-				suq, s.Name, s.Type, cluster.ID, s.DisplayName,
-				s.EntityStatus, s.Version, s.URL, s.Description, s.ID)
+				suq, s.Name, s.Type, cluster.ID, string(sConfig), s.DisplayName,
+				s.EntityStatus, s.Version, s.URL, s.Description,
+				s.Health, s.HealthOutput, s.HealthCheckedAt, s.ID)
 			if err != nil {
 				return ErrTransactionQuery
 			}
@@ -332,32 +468,131 @@ func (db MySqlDatabase) UpdateCluster(cluster *protobuf.Cluster) error {
 	q := `UPDATE cluster SET 
                    Name = ?, DisplayName = ?, MasterIP = ?, HostURL = ?, EntityStatus = ?, ClusterType = ?, 
                    NSlaves = ?, Description = ?,  Image = ?, 
-                   MasterFlavor = ?, SlavesFlavor = ?, StorageFlavor = ?, SSH_Keys = ?
+                   MasterFlavor = ?, SlavesFlavor = ?, StorageFlavor = ?, SSH_Keys = ?,
+                   TTL = ?, ExpiresAt = ?, ExpiryWarnedAt = ?, Health = ?, HealthCheckedAt = ?,
+                   Drift = ?, DriftCheckedAt = ?
           WHERE ID = ?`
 
 	ssh_keys, err := json.Marshal(cluster.Keys)
 	if err != nil {
 		return ErrTransactionQuery
 	}
+	drift, err := json.Marshal(cluster.Drift)
+	if err != nil {
+		return ErrTransactionQuery
+	}
 
 	_, err = tx.Exec(
 		q, cluster.Name, cluster.DisplayName, cluster.MasterIP, cluster.HostURL, cluster.EntityStatus, cluster.ClusterType,
 		cluster.NSlaves, cluster.Description, cluster.Image,
-		cluster.MasterFlavor, cluster.SlavesFlavor, cluster.StorageFlavor, ssh_keys, cluster.ID)
+		cluster.MasterFlavor, cluster.SlavesFlavor, cluster.StorageFlavor, ssh_keys,
+		cluster.TTL, cluster.ExpiresAt, cluster.ExpiryWarnedAt, cluster.Health, cluster.HealthCheckedAt,
+		drift, cluster.DriftCheckedAt, cluster.ID)
+	if err != nil {
+		return ErrTransactionQuery
+	}
+
+	err = writeClusterNodes(tx, cluster)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return ErrTransactionCommit
+	}
+	return nil
+}
+
+// UpdateClusterStatus changes status of the cluster only if it is still in the fromStatus,
+// returns false if the cluster status was changed meanwhile
+func (db MySqlDatabase) UpdateClusterStatus(clusterId string, fromStatus string, toStatus string) (bool, error) {
+	q := `UPDATE cluster SET EntityStatus = ? WHERE ID = ? AND EntityStatus = ?`
+	res, err := db.connection.Exec(q, toStatus, clusterId, fromStatus)
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	return updated > 0, nil
+}
+
+// UpdateClusterExpiryWarning saves the time when the cluster owner was warned about the cluster expiry
+// only if the cluster lease is not changed, returns false if the lease was changed meanwhile
+func (db MySqlDatabase) UpdateClusterExpiryWarning(clusterId string, expiresAt string, warnedAt string) (bool, error) {
+	q := `UPDATE cluster SET ExpiryWarnedAt = ? WHERE ID = ? AND ExpiresAt = ?`
+	res, err := db.connection.Exec(q, warnedAt, clusterId, expiresAt)
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	return updated > 0, nil
+}
+
+// UpdateClusterHealth saves only health of the cluster and its services,
+// services removed from the cluster meanwhile are not saved again
+func (db MySqlDatabase) UpdateClusterHealth(cluster *protobuf.Cluster) error {
+	tx, err := db.connection.Begin()
+	if err != nil {
+		return ErrStartQueryConnection
+	}
+
+	//rollback in case of error
+	defer tx.Rollback()
+
+	q := `UPDATE cluster SET Health = ?, HealthCheckedAt = ? WHERE ID = ?`
+	_, err = tx.Exec(q, cluster.Health, cluster.HealthCheckedAt, cluster.ID)
 	if err != nil {
 		return ErrTransactionQuery
 	}
+
+	sq := `UPDATE service SET Health = ?, HealthOutput = ?, HealthCheckedAt = ? WHERE ID = ? AND ClusterRef = ?`
+	for _, s := range cluster.Services {
+		_, err = tx.Exec(sq, s.Health, s.HealthOutput, s.HealthCheckedAt, s.ID, cluster.ID)
+		if err != nil {
+			return ErrTransactionQuery
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return ErrTransactionCommit
 	}
 	return nil
 }
 
+// UpdateClusterDrift saves only drift, health and status of the cluster if it is still in the fromStatus,
+// returns false if the cluster status was changed meanwhile
+func (db MySqlDatabase) UpdateClusterDrift(cluster *protobuf.Cluster, fromStatus string) (bool, error) {
+	drift, err := json.Marshal(cluster.Drift)
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+
+	q := `UPDATE cluster SET Drift = ?, DriftCheckedAt = ?, Health = ?, EntityStatus = ?
+          WHERE ID = ? AND EntityStatus = ?`
+	res, err := db.connection.Exec(q, drift, cluster.DriftCheckedAt, cluster.Health, cluster.EntityStatus,
+		cluster.ID, fromStatus)
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return false, ErrQueryExecution
+	}
+	return updated > 0, nil
+}
+
 func (db MySqlDatabase) ReadClustersList() ([]protobuf.Cluster, error) {
 	//make a query to select all clusters
 	q := `SELECT ID, Name, DisplayName, HostURL, EntityStatus, ClusterType,
 			NSlaves, MasterIP, ProjectID, Description, Image, Monitoring,
-			MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys
+			MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys, COALESCE(Cloud, ''),
+			COALESCE(TTL, 0), COALESCE(ExpiresAt, ''), COALESCE(ExpiryWarnedAt, ''),
+			COALESCE(Health, ''), COALESCE(HealthCheckedAt, ''), Drift, COALESCE(DriftCheckedAt, '')
 		  FROM cluster`
 
 	rows, err := db.connection.Query(q)
@@ -372,11 +607,12 @@ func (db MySqlDatabase) ReadClustersList() ([]protobuf.Cluster, error) {
 	var result []protobuf.Cluster
 	for rows.Next() {
 		var c protobuf.Cluster
-		var ssh_keys []byte
+		var ssh_keys, drift []byte
 		//select one cluster
 		if err := rows.Scan(&c.ID, &c.Name, &c.DisplayName, &c.HostURL, &c.EntityStatus, &c.ClusterType,
 			&c.NSlaves, &c.MasterIP, &c.ProjectID, &c.Description, &c.Image, &c.Monitoring,
-			&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys); err != nil {
+			&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys, &c.Cloud,
+			&c.TTL, &c.ExpiresAt, &c.ExpiryWarnedAt, &c.Health, &c.HealthCheckedAt, &drift, &c.DriftCheckedAt); err != nil {
 			return nil, ErrQueryRows
 		}
 
@@ -386,11 +622,17 @@ func (db MySqlDatabase) ReadClustersList() ([]protobuf.Cluster, error) {
 				return nil, ErrUnmarshalJson
 			}
 		}
+		if len(drift) > 0 {
+			err = json.Unmarshal(drift, &c.Drift)
+			if err != nil {
+				return nil, ErrUnmarshalJson
+			}
+		}
 
 		//select list of services for particular cluster
 		sq := `SELECT ID, Name, Type, ClusterRef, COALESCE(Config,''), DisplayName, 
 					COALESCE(EntityStatus,''),  Version, COALESCE(URL, ''),  
-					COALESCE(Description, '') 
+					COALESCE(Description, ''), COALESCE(Health, ''), COALESCE(HealthOutput, ''), COALESCE(HealthCheckedAt, '')
 			   FROM service
 			   WHERE ClusterRef = ?`
 		srows, err := db.connection.Query(sq, c.ID)
@@ -409,7 +651,8 @@ func (db MySqlDatabase) ReadClustersList() ([]protobuf.Cluster, error) {
 			var config string
 			//select one cluster
 			if err := srows.Scan(&s.ID, &s.Name, &s.Type, &s.ClusterRef, &config, &s.DisplayName,
-				&s.EntityStatus, &s.Version, &s.URL, &s.Description); err != nil {
+				&s.EntityStatus, &s.Version, &s.URL, &s.Description,
+				&s.Health, &s.HealthOutput, &s.HealthCheckedAt); err != nil {
 				return nil, ErrScanRows
 			}
 			err = json.Unmarshal([]byte(config), &s.Config)
@@ -423,6 +666,12 @@ func (db MySqlDatabase) ReadClustersList() ([]protobuf.Cluster, error) {
 		//add service array to cluster structure
 		c.Services = ss
 
+		nodes, err := readClusterNodes(db, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		c.Nodes = nodes
+
 		//add particular cluster to cluster array
 		result = append(result, c)
 	}
@@ -445,14 +694,18 @@ func (db MySqlDatabase) ReadProject(projectIdOrName string) (*protobuf.Project,
 func readProjectbyId(db MySqlDatabase, id string) (*protobuf.Project, error) {
 	q := `SELECT ID, Name, DisplayName, COALESCE(GroupID, ''), 
 			DefaultImage, COALESCE(Description, ''), DefaultMasterFlavor, DefaultSlavesFlavor,
-			DefaultStorageFlavor, DefaultMonitoringFlavor FROM project WHERE ID = ?`
+			DefaultStorageFlavor, DefaultMonitoringFlavor, COALESCE(DefaultCloud, ''),
+			COALESCE(DefaultClusterTTL, 0), COALESCE(MaxClusterTTL, 0),
+			COALESCE(MaxClusters, 0), COALESCE(MaxVCPUs, 0), COALESCE(MaxRAM, 0), COALESCE(MaxDisk, 0), COALESCE(MaxNodes, 0) FROM project WHERE ID = ?`
 
 	pr := protobuf.Project{ID: "", Name: "", DisplayName: ""}
 	res := db.connection.QueryRow(q, id)
 	if err := res.Scan(
 		&pr.ID, &pr.Name, &pr.DisplayName, &pr.GroupID, &pr.Description,
 		&pr.DefaultImage, &pr.DefaultMasterFlavor,
-		&pr.DefaultSlavesFlavor, &pr.DefaultStorageFlavor, &pr.DefaultMonitoringFlavor); err != nil {
+		&pr.DefaultSlavesFlavor, &pr.DefaultStorageFlavor, &pr.DefaultMonitoringFlavor, &pr.DefaultCloud,
+		&pr.DefaultClusterTTL, &pr.MaxClusterTTL,
+		&pr.MaxClusters, &pr.MaxVCPUs, &pr.MaxRAM, &pr.MaxDisk, &pr.MaxNodes); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("project", id)
 		}
@@ -465,14 +718,18 @@ func readProjectbyId(db MySqlDatabase, id string) (*protobuf.Project, error) {
 func readProjectbyName(db MySqlDatabase, name string) (*protobuf.Project, error) {
 	q := `SELECT ID, Name, DisplayName, COALESCE(GroupID, ''), COALESCE(Description, ''), 
 			DefaultImage, DefaultMasterFlavor, DefaultSlavesFlavor,
-			DefaultStorageFlavor, DefaultMonitoringFlavor FROM project WHERE Name = ?`
+			DefaultStorageFlavor, DefaultMonitoringFlavor, COALESCE(DefaultCloud, ''),
+			COALESCE(DefaultClusterTTL, 0), COALESCE(MaxClusterTTL, 0),
+			COALESCE(MaxClusters, 0), COALESCE(MaxVCPUs, 0), COALESCE(MaxRAM, 0), COALESCE(MaxDisk, 0), COALESCE(MaxNodes, 0) FROM project WHERE Name = ?`
 
 	pr := protobuf.Project{ID: "", Name: "", DisplayName: ""}
 	res := db.connection.QueryRow(q, name)
 	if err := res.Scan(
 		&pr.ID, &pr.Name, &pr.DisplayName, &pr.GroupID, &pr.Description,
 		&pr.DefaultImage, &pr.DefaultMasterFlavor,
-		&pr.DefaultSlavesFlavor, &pr.DefaultStorageFlavor, &pr.DefaultMonitoringFlavor); err != nil {
+		&pr.DefaultSlavesFlavor, &pr.DefaultStorageFlavor, &pr.DefaultMonitoringFlavor, &pr.DefaultCloud,
+		&pr.DefaultClusterTTL, &pr.MaxClusterTTL,
+		&pr.MaxClusters, &pr.MaxVCPUs, &pr.MaxRAM, &pr.MaxDisk, &pr.MaxNodes); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("project", name)
 		}
@@ -485,7 +742,9 @@ func readProjectbyName(db MySqlDatabase, name string) (*protobuf.Project, error)
 func (db MySqlDatabase) ReadProjectsList() ([]protobuf.Project, error) {
 	q := `SELECT ID, Name, DisplayName, COALESCE(GroupID, ''), COALESCE(Description, ''), 
 	DefaultImage, DefaultMasterFlavor, DefaultSlavesFlavor,
-	DefaultStorageFlavor, DefaultMonitoringFlavor  FROM project`
+	DefaultStorageFlavor, DefaultMonitoringFlavor, COALESCE(DefaultCloud, ''),
+			COALESCE(DefaultClusterTTL, 0), COALESCE(MaxClusterTTL, 0),
+			COALESCE(MaxClusters, 0), COALESCE(MaxVCPUs, 0), COALESCE(MaxRAM, 0), COALESCE(MaxDisk, 0), COALESCE(MaxNodes, 0) FROM project`
 	rows, err := db.connection.Query(q)
 	if err != nil {
 		return nil, ErrQueryExecution
@@ -500,7 +759,9 @@ func (db MySqlDatabase) ReadProjectsList() ([]protobuf.Project, error) {
 		if err := rows.Scan(
 			&row.ID, &row.Name, &row.DisplayName, &row.GroupID, &row.Description,
 			&row.DefaultImage, &row.DefaultMasterFlavor, &row.DefaultSlavesFlavor,
-			&row.DefaultStorageFlavor, &row.DefaultMonitoringFlavor); err != nil && err != sql.ErrNoRows {
+			&row.DefaultStorageFlavor, &row.DefaultMonitoringFlavor, &row.DefaultCloud,
+			&row.DefaultClusterTTL, &row.MaxClusterTTL,
+			&row.MaxClusters, &row.MaxVCPUs, &row.MaxRAM, &row.MaxDisk, &row.MaxNodes); err != nil && err != sql.ErrNoRows {
 			return nil, ErrReadObjectList
 		}
 		result = append(result, row)
@@ -512,7 +773,9 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 	q := `SELECT 
 			ID, Name, DisplayName, HostURL, EntityStatus, ClusterType, 
 			NSlaves, MasterIP, Description, ProjectID, Image, Monitoring,
-			MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys
+			MasterFlavor, SlavesFlavor, StorageFlavor, MonitoringFlavor, SSH_Keys, COALESCE(Cloud, ''),
+			COALESCE(TTL, 0), COALESCE(ExpiresAt, ''), COALESCE(ExpiryWarnedAt, ''),
+			COALESCE(Health, ''), COALESCE(HealthCheckedAt, ''), Drift, COALESCE(DriftCheckedAt, '')
 		  FROM cluster
 		  WHERE ProjectID = ?`
 
@@ -528,11 +791,12 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 	var result []protobuf.Cluster
 	for rows.Next() {
 		var c protobuf.Cluster
-		var ssh_keys []byte
+		var ssh_keys, drift []byte
 		if err := rows.Scan(
 			&c.ID, &c.Name, &c.DisplayName, &c.HostURL, &c.EntityStatus, &c.ClusterType, &c.NSlaves, &c.MasterIP,
 			&c.Description, &c.ProjectID, &c.Image, &c.Monitoring,
-			&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys); err != nil {
+			&c.MasterFlavor, &c.SlavesFlavor, &c.StorageFlavor, &c.MonitoringFlavor, &ssh_keys, &c.Cloud,
+			&c.TTL, &c.ExpiresAt, &c.ExpiryWarnedAt, &c.Health, &c.HealthCheckedAt, &drift, &c.DriftCheckedAt); err != nil {
 			return nil, ErrReadIncludedObject("cluster", "project", projectID)
 		}
 
@@ -542,9 +806,16 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 				return nil, ErrUnmarshalJson
 			}
 		}
+		if len(drift) > 0 {
+			err = json.Unmarshal(drift, &c.Drift)
+			if err != nil {
+				return nil, ErrUnmarshalJson
+			}
+		}
 
 		sq := `SELECT ID, Name, Type, COALESCE(Config,''), DisplayName, COALESCE(EntityStatus,''), Version, 
-				COALESCE(URL, ''), COALESCE(Description, '') FROM service WHERE ClusterRef = ?`
+				COALESCE(URL, ''), COALESCE(Description, ''), COALESCE(Health, ''), COALESCE(HealthOutput, ''), COALESCE(HealthCheckedAt, '')
+				FROM service WHERE ClusterRef = ?`
 		srows, err := db.connection.Query(sq, c.ID)
 		if err != nil {
 			return nil, ErrQueryExecution
@@ -559,7 +830,8 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 			var s protobuf.Service
 			var config string
 			if err := srows.Scan(&s.ID, &s.Name, &s.Type, &config, &s.DisplayName, &s.EntityStatus, &s.Version,
-				&s.URL, &s.Description); err != nil {
+				&s.URL, &s.Description,
+				&s.Health, &s.HealthOutput, &s.HealthCheckedAt); err != nil {
 				return nil, ErrScanRows
 			}
 			err = json.Unmarshal([]byte(config), &s.Config)
@@ -571,6 +843,12 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 
 		c.Services = ss
 
+		nodes, err := readClusterNodes(db, c.ID)
+		if err != nil {
+			return nil, err
+		}
+		c.Nodes = nodes
+
 		result = append(result, c)
 	}
 	return result, nil
@@ -579,13 +857,16 @@ func (db MySqlDatabase) ReadProjectClusters(projectID string) ([]protobuf.Cluste
 func (db MySqlDatabase) WriteProject(project *protobuf.Project) error {
 	q := `INSERT INTO project (
                 ID, Name, DisplayName, GroupID, Description, DefaultImage,
-                DefaultMasterFlavor, DefaultSlavesFlavor, DefaultStorageFlavor, DefaultMonitoringFlavor
-		  ) VALUES (?,?,?,?,?,?,?,?,?,?)`
+                DefaultMasterFlavor, DefaultSlavesFlavor, DefaultStorageFlavor, DefaultMonitoringFlavor, DefaultCloud,
+                DefaultClusterTTL, MaxClusterTTL, MaxClusters, MaxVCPUs, MaxRAM, MaxDisk, MaxNodes
+		  ) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
 
 	_, err := db.connection.Exec(
 		q, project.ID, project.Name, project.DisplayName, project.GroupID,
 		project.Description, project.DefaultImage, project.DefaultMasterFlavor,
-		project.DefaultSlavesFlavor, project.DefaultStorageFlavor, project.DefaultMonitoringFlavor)
+		project.DefaultSlavesFlavor, project.DefaultStorageFlavor, project.DefaultMonitoringFlavor, project.DefaultCloud,
+		project.DefaultClusterTTL, project.MaxClusterTTL,
+		project.MaxClusters, project.MaxVCPUs, project.MaxRAM, project.MaxDisk, project.MaxNodes)
 	if err != nil {
 		return ErrWriteObjectByKey
 	}
@@ -595,12 +876,16 @@ func (db MySqlDatabase) WriteProject(project *protobuf.Project) error {
 func (db MySqlDatabase) UpdateProject(project *protobuf.Project) error {
 	q := `UPDATE project SET 
             	Name = ?, DisplayName = ?,  GroupID = ?, Description = ?, DefaultImage = ?, 
-          		DefaultMasterFlavor = ?, DefaultSlavesFlavor = ?, DefaultStorageFlavor = ?, DefaultMonitoringFlavor = ?
+          		DefaultMasterFlavor = ?, DefaultSlavesFlavor = ?, DefaultStorageFlavor = ?, DefaultMonitoringFlavor = ?,
+          		DefaultCloud = ?, DefaultClusterTTL = ?, MaxClusterTTL = ?,
+          		MaxClusters = ?, MaxVCPUs = ?, MaxRAM = ?, MaxDisk = ?, MaxNodes = ?
           WHERE ID = ?`
 	_, err := db.connection.Exec(
 		q, project.Name, project.DisplayName, project.GroupID,
 		project.Description, project.DefaultImage, project.DefaultMasterFlavor,
-		project.DefaultSlavesFlavor, project.DefaultStorageFlavor, project.DefaultMonitoringFlavor, project.ID)
+		project.DefaultSlavesFlavor, project.DefaultStorageFlavor, project.DefaultMonitoringFlavor,
+		project.DefaultCloud, project.DefaultClusterTTL, project.MaxClusterTTL,
+		project.MaxClusters, project.MaxVCPUs, project.MaxRAM, project.MaxDisk, project.MaxNodes, project.ID)
 	if err != nil {
 		return ErrUpdateObjectByKey
 	}
@@ -985,18 +1270,23 @@ func (db MySqlDatabase) ReadServiceType(serviceTypeIdOrName string) (*protobuf.S
 }
 
 func readServiceTypebyName(db MySqlDatabase, name string) (*protobuf.ServiceType, error) {
-	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,'')
+	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,''), SupportedActions
 			FROM service_type WHERE Type = ?`
 	st := protobuf.ServiceType{ID: "", Type: ""}
+	var supported []byte
 	res := db.connection.QueryRow(q, name)
-	if err := res.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort); err != nil {
+	if err := res.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort, &supported); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("service_type", name)
 		}
 		return nil, ErrReadObjectByKey
 	}
 
-	err := db.readServiceTypeInfo(&st)
+	err := unmarshalSupportedActions(&st, supported)
+	if err != nil {
+		return &st, err
+	}
+	err = db.readServiceTypeInfo(&st)
 	if err != nil {
 		return &st, err
 	}
@@ -1005,18 +1295,23 @@ func readServiceTypebyName(db MySqlDatabase, name string) (*protobuf.ServiceType
 }
 
 func readServiceTypebyId(db MySqlDatabase, id string) (*protobuf.ServiceType, error) {
-	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,'')
+	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,''), SupportedActions
 			FROM service_type WHERE ID = ?`
 	st := protobuf.ServiceType{ID: "", Type: ""}
+	var supported []byte
 	res := db.connection.QueryRow(q, id)
-	if err := res.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort); err != nil {
+	if err := res.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort, &supported); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("service_type", id)
 		}
 		return nil, ErrReadObjectByKey
 	}
 
-	err := db.readServiceTypeInfo(&st)
+	err := unmarshalSupportedActions(&st, supported)
+	if err != nil {
+		return &st, err
+	}
+	err = db.readServiceTypeInfo(&st)
 	if err != nil {
 		return &st, err
 	}
@@ -1116,12 +1411,83 @@ func (db MySqlDatabase) readServiceTypeInfo(st *protobuf.ServiceType) error {
 	}
 	//add port array to service_type structure
 	st.Ports = sports
+
+	//read actions
+	return db.readServiceActions(st)
+}
+
+// unmarshalSupportedActions sets cluster actions supported by the service type, they are stored as json
+func unmarshalSupportedActions(st *protobuf.ServiceType, supported []byte) error {
+	if len(supported) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(supported, &st.SupportedActions); err != nil {
+		return ErrUnmarshalJson
+	}
+	return nil
+}
+
+// readServiceActions reads actions declared by the service type,
+// their parameters and allowed statuses are stored as json
+func (db MySqlDatabase) readServiceActions(st *protobuf.ServiceType) error {
+	aq := `SELECT ID, Name, COALESCE(Description,''), Playbook, Parameters, AllowedStatuses
+			 FROM service_action WHERE ServiceTypeID = ?`
+	arows, err := db.connection.Query(aq, st.ID)
+	if err != nil {
+		return ErrReadIncludedObject("service_action", "service_type", st.ID)
+	}
+	if err := arows.Err(); err != nil {
+		return ErrReadIncludedObject("service_action", "service_type", st.ID)
+	}
+	defer arows.Close()
+
+	actions := []*protobuf.ServiceAction{}
+	for arows.Next() {
+		var a protobuf.ServiceAction
+		var parameters, statuses []byte
+		if err := arows.Scan(&a.ID, &a.Name, &a.Description, &a.Playbook, &parameters, &statuses); err != nil {
+			return ErrReadIncludedObject("service_action", "service_type", st.ID)
+		}
+		if len(parameters) > 0 {
+			if err := json.Unmarshal(parameters, &a.Parameters); err != nil {
+				return ErrUnmarshalJson
+			}
+		}
+		if len(statuses) > 0 {
+			if err := json.Unmarshal(statuses, &a.AllowedStatuses); err != nil {
+				return ErrUnmarshalJson
+			}
+		}
+		actions = append(actions, &a)
+	}
+	st.Actions = actions
+	return nil
+}
+
+// writeServiceActions saves actions declared by the service type in the transaction
+func writeServiceActions(tx *sql.Tx, st *protobuf.ServiceType) error {
+	for _, a := range st.Actions {
+		aq := `INSERT INTO service_action (ID, Name, Description, Playbook, Parameters, AllowedStatuses, ServiceTypeID)
+				VALUES (?,?,?,?,?,?,?)`
+		parameters, err := json.Marshal(a.Parameters)
+		if err != nil {
+			return ErrUnmarshalJson
+		}
+		statuses, err := json.Marshal(a.AllowedStatuses)
+		if err != nil {
+			return ErrUnmarshalJson
+		}
+		_, err = tx.Exec(aq, a.ID, a.Name, a.Description, a.Playbook, parameters, statuses, st.ID)
+		if err != nil {
+			return ErrInsertIncludedObject("service_action", "service_type", st.ID)
+		}
+	}
 	return nil
 }
 
 func (db MySqlDatabase) ReadServicesTypesList() ([]protobuf.ServiceType, error) {
 	//make a query to read all service types
-	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,'')
+	q := `SELECT ID, Type, COALESCE(Description,''), DefaultVersion, Class, COALESCE(AccessPort,''), SupportedActions
  			 FROM service_type`
 	rows, err := db.connection.Query(q)
 	if err != nil {
@@ -1135,9 +1501,13 @@ func (db MySqlDatabase) ReadServicesTypesList() ([]protobuf.ServiceType, error)
 	sTypes := []protobuf.ServiceType{}
 	for rows.Next() {
 		var st protobuf.ServiceType
-		if err := rows.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort); err != nil {
+		var supported []byte
+		if err := rows.Scan(&st.ID, &st.Type, &st.Description, &st.DefaultVersion, &st.Class, &st.AccessPort, &supported); err != nil {
 			return nil, ErrReadObjectList
 		}
+		if err := unmarshalSupportedActions(&st, supported); err != nil {
+			return nil, err
+		}
 		err := db.readServiceTypeInfo(&st)
 		if err != nil {
 			return nil, err
@@ -1176,8 +1546,13 @@ func (db MySqlDatabase) UpdateServiceType(st *protobuf.ServiceType) error {
 	}
 
 	//update service type info
-	q := `UPDATE service_type SET Type = ?, DefaultVersion = ?, Class = ?, AccessPort = ?, Description = ? WHERE ID = ?`
-	_, err = tx.Exec(q, st.Type, st.DefaultVersion, st.Class, st.AccessPort, st.Description, st.ID)
+	supported, err := json.Marshal(st.SupportedActions)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
+	q := `UPDATE service_type SET Type = ?, DefaultVersion = ?, Class = ?, AccessPort = ?, Description = ?,
+          SupportedActions = ? WHERE ID = ?`
+	_, err = tx.Exec(q, st.Type, st.DefaultVersion, st.Class, st.AccessPort, st.Description, supported, st.ID)
 	if err != nil {
 		return ErrUpdateObjectByKey
 	}
@@ -1231,8 +1606,8 @@ func (db MySqlDatabase) UpdateServiceType(st *protobuf.ServiceType) error {
 				for _, sc := range sv.Configs {
 					q := `INSERT INTO service_config (
                             	ID, ParameterName, Type, PossibleValues, DefaultValue, Required,   
-			  			   		Description, AnsibleVarName, IsList, VersionID
-			  			   ) VALUES (?,?,?,?,?,?,?,?,?,?)`
+			  			   		Description, AnsibleVarName, IsList, RequiresRestart, VersionID
+			  			   ) VALUES (?,?,?,?,?,?,?,?,?,?,?)`
 					pv, err := json.Marshal(sc.PossibleValues)
 					if err != nil {
 						return ErrUnmarshalJson
@@ -1242,7 +1617,7 @@ func (db MySqlDatabase) UpdateServiceType(st *protobuf.ServiceType) error {
 						return ErrNewUuid
 					}
 					_, err = tx.Exec(q, scId, sc.ParameterName, sc.Type, string(pv), sc.DefaultValue,
-						sc.Required, sc.Description, sc.AnsibleVarName, sc.IsList, svId)
+						sc.Required, sc.Description, sc.AnsibleVarName, sc.IsList, sc.RequiresRestart, svId)
 					if err != nil {
 						return ErrUpdateIncludedObject("service_config", "service_type", st.ID)
 					}
@@ -1320,6 +1695,17 @@ func (db MySqlDatabase) UpdateServiceType(st *protobuf.ServiceType) error {
 		}
 	}
 
+	//actions are replaced as a whole
+	daq := `DELETE FROM service_action WHERE ServiceTypeID = ?`
+	_, err = tx.Exec(daq, st.ID)
+	if err != nil {
+		return ErrUpdateIncludedObject("service_action", "service_type", st.ID)
+	}
+	err = writeServiceActions(tx, st)
+	if err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return ErrTransactionCommit
 	}
@@ -1474,8 +1860,8 @@ func (db MySqlDatabase) UpdateServiceTypeVersion(serviceTypeIdOrName string, ver
 
 	for _, sc := range version.Configs {
 		q := `INSERT INTO service_config (ID, ParameterName, Type, PossibleValues, DefaultValue, Required,   
-				Description, AnsibleVarName, IsList, VersionID)
-			  VALUES (?,?,?,?,?,?,?,?,?,?)`
+				Description, AnsibleVarName, IsList, RequiresRestart, VersionID)
+			  VALUES (?,?,?,?,?,?,?,?,?,?,?)`
 
 		pv, err := json.Marshal(sc.PossibleValues)
 		if err != nil {
@@ -1488,7 +1874,7 @@ func (db MySqlDatabase) UpdateServiceTypeVersion(serviceTypeIdOrName string, ver
 		}
 
 		_, err = db.connection.Exec(q, scId.String(), sc.ParameterName, sc.Type, string(pv), sc.DefaultValue,
-			sc.Required, sc.Description, sc.AnsibleVarName, sc.IsList, version.ID)
+			sc.Required, sc.Description, sc.AnsibleVarName, sc.IsList, sc.RequiresRestart, version.ID)
 		if err != nil {
 			return ErrUpdateIncludedObject("service_config", "service_type_version", serviceTypeIdOrName)
 		}
@@ -1544,14 +1930,14 @@ func (db MySqlDatabase) ReadServiceTypeVersionConfig(serviceTypeIdOrName string,
 	}
 
 	cq := `SELECT ID, ParameterName, Type,  COALESCE(PossibleValues, ''), DefaultValue,  Required, 
-				COALESCE(Description, ''), AnsibleVarName,  IsList 
+				COALESCE(Description, ''), AnsibleVarName,  IsList, COALESCE(RequiresRestart, FALSE)
 		   FROM service_config 
 		   WHERE VersionID = ? AND ParameterName = ?`
 	var c protobuf.ServiceConfig
 	res := db.connection.QueryRow(cq, VersionId, parameterName)
 	var posVals string
 	if err := res.Scan(&c.ID, &c.ParameterName, &c.Type, &posVals, &c.DefaultValue, &c.Required, &c.Description,
-		&c.AnsibleVarName, &c.IsList); err != nil {
+		&c.AnsibleVarName, &c.IsList, &c.RequiresRestart); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrObjectNotFound("service_config", parameterName)
 		}
@@ -1573,14 +1959,16 @@ func (db MySqlDatabase) UpdateServiceTypeVersionConfig(serviceTypeIdOrName strin
 		return err
 	}
 	q := `UPDATE service_config SET 
-				Type = ?, PossibleValues = ?, DefaultValue = ?, Required = ?, Description = ?, IsList = ?  
+				Type = ?, PossibleValues = ?, DefaultValue = ?, Required = ?, Description = ?, IsList = ?,
+				RequiresRestart = ?
           WHERE VersionID = ? AND ParameterName = ?`
 
 	pv, err := json.Marshal(config.PossibleValues)
 	if err != nil {
 		return ErrUnmarshalJson
 	}
-	_, err = db.connection.Exec(q, config.Type, string(pv), config.DefaultValue, config.Required, config.Description, config.IsList, VersionId, config.ParameterName)
+	_, err = db.connection.Exec(q, config.Type, string(pv), config.DefaultValue, config.Required, config.Description, config.IsList,
+		config.RequiresRestart, VersionId, config.ParameterName)
 	if err != nil {
 		return ErrUpdateObjectByKey
 	}
@@ -1637,7 +2025,7 @@ func (db MySqlDatabase) readHealthCheckInfo(sh *protobuf.ServiceHealthCheck) err
 func (db MySqlDatabase) readServiceVersionInfo(sv *protobuf.ServiceVersion) error {
 	// read configs for version
 	cq := `SELECT ID, ParameterName, Type,  COALESCE(PossibleValues, ''), DefaultValue,  Required, 
-				COALESCE(Description, ''), AnsibleVarName,  IsList
+				COALESCE(Description, ''), AnsibleVarName,  IsList, COALESCE(RequiresRestart, FALSE)
 		   FROM service_config 
 		   WHERE VersionID = ?`
 	// read all config rows
@@ -1656,7 +2044,7 @@ func (db MySqlDatabase) readServiceVersionInfo(sv *protobuf.ServiceVersion) erro
 		var sc protobuf.ServiceConfig
 		var posVals string
 		if err := crows.Scan(&sc.ID, &sc.ParameterName, &sc.Type, &posVals, &sc.DefaultValue, &sc.Required, &sc.Description,
-			&sc.AnsibleVarName, &sc.IsList); err != nil {
+			&sc.AnsibleVarName, &sc.IsList, &sc.RequiresRestart); err != nil {
 			return ErrReadIncludedObject("service_config", "service_version", sv.ID)
 		}
 		err = json.Unmarshal([]byte(posVals), &sc.PossibleValues)
@@ -1730,8 +2118,14 @@ func (db MySqlDatabase) WriteServiceType(sType *protobuf.ServiceType) error {
 	defer tx.Rollback()
 
 	//save service type info
-	q := `INSERT INTO service_type (ID, Type, DefaultVersion, Class, AccessPort, Description) VALUES (?,?,?,?,?,?)`
-	_, err = tx.Exec(q, sType.ID, sType.Type, sType.DefaultVersion, sType.Class, sType.AccessPort, sType.Description)
+	supported, err := json.Marshal(sType.SupportedActions)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
+	q := `INSERT INTO service_type (ID, Type, DefaultVersion, Class, AccessPort, Description, SupportedActions)
+          VALUES (?,?,?,?,?,?,?)`
+	_, err = tx.Exec(q, sType.ID, sType.Type, sType.DefaultVersion, sType.Class, sType.AccessPort, sType.Description,
+		supported)
 	if err != nil {
 		return ErrWriteObjectByKey
 	}
@@ -1779,8 +2173,8 @@ func (db MySqlDatabase) WriteServiceType(sType *protobuf.ServiceType) error {
 		for _, sc := range sv.Configs {
 			q := `INSERT INTO service_config (
                             ID, ParameterName, AnsibleVarName, Type, DefaultValue, PossibleValues, Required, 
-							IsList, Description, VersionID) 
-				  VALUES (?,?,?,?,?,?,?,?,?,?)`
+							IsList, Description, RequiresRestart, VersionID) 
+				  VALUES (?,?,?,?,?,?,?,?,?,?,?)`
 
 			pv, err := json.Marshal(sc.PossibleValues)
 			if err != nil {
@@ -1794,7 +2188,7 @@ func (db MySqlDatabase) WriteServiceType(sType *protobuf.ServiceType) error {
 
 			_, err = tx.Exec(
 				q, scId, sc.ParameterName, sc.AnsibleVarName, sc.Type, sc.DefaultValue, string(pv),
-				sc.Required, sc.IsList, sc.Description, sv.ID)
+				sc.Required, sc.IsList, sc.Description, sc.RequiresRestart, sv.ID)
 			if err != nil {
 				return ErrInsertIncludedObject("service_config", "service_type", sType.ID)
 			}
@@ -1850,9 +2244,522 @@ func (db MySqlDatabase) WriteServiceType(sType *protobuf.ServiceType) error {
 		}
 	}
 
+	//save actions info
+	err = writeServiceActions(tx, sType)
+	if err != nil {
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		return ErrTransactionCommit
 	}
 
 	return nil
 }
+
+const operationColumns = `ID, ClusterID, ProjectID, Action, Status, CreatedAt, COALESCE(OwnerID, ''),
+		COALESCE(StartedAt, ''), COALESCE(FinishedAt, ''), COALESCE(Phase, ''), COALESCE(Result, ''), COALESCE(Error, ''),
+		COALESCE(Play, ''), COALESCE(Task, ''), HostResults,
+		COALESCE(CancelRequested, FALSE), COALESCE(CleanupOnCancel, FALSE), COALESCE(Service, ''), COALESCE(Node, ''),
+		COALESCE(ServiceAction, ''), Params, COALESCE(ServiceVersion, ''), COALESCE(Restart, FALSE)`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOperation(row rowScanner, op *protobuf.Operation) error {
+	var hostResults, params []byte
+	err := row.Scan(&op.ID, &op.ClusterID, &op.ProjectID, &op.Action, &op.Status, &op.CreatedAt, &op.OwnerID,
+		&op.StartedAt, &op.FinishedAt, &op.Phase, &op.Result, &op.Error, &op.Play, &op.Task, &hostResults,
+		&op.CancelRequested, &op.CleanupOnCancel, &op.Service, &op.Node, &op.ServiceAction, &params,
+		&op.ServiceVersion, &op.Restart)
+	if err != nil {
+		return err
+	}
+	if len(hostResults) > 0 {
+		err = json.Unmarshal(hostResults, &op.HostResults)
+		if err != nil {
+			return ErrUnmarshalJson
+		}
+	}
+	if len(params) > 0 {
+		err = json.Unmarshal(params, &op.Params)
+		if err != nil {
+			return ErrUnmarshalJson
+		}
+	}
+	return nil
+}
+
+func (db MySqlDatabase) ReadOperation(operationId string) (*protobuf.Operation, error) {
+	q := `SELECT ` + operationColumns + ` FROM operation WHERE ID = ?`
+
+	var op protobuf.Operation
+	res := db.connection.QueryRow(q, operationId)
+	if err := scanOperation(res, &op); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrObjectNotFound("operation", operationId)
+		}
+		if err == ErrUnmarshalJson {
+			return nil, err
+		}
+		return nil, ErrScanRows
+	}
+
+	return &op, nil
+}
+
+func (db MySqlDatabase) readOperations(q string, args ...interface{}) ([]protobuf.Operation, error) {
+	rows, err := db.connection.Query(q, args...)
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.Operation
+	for rows.Next() {
+		var op protobuf.Operation
+		if err := scanOperation(rows, &op); err != nil {
+			if err == ErrUnmarshalJson {
+				return nil, err
+			}
+			return nil, ErrScanRows
+		}
+		result = append(result, op)
+	}
+	return result, nil
+}
+
+func (db MySqlDatabase) ReadOperationsList() ([]protobuf.Operation, error) {
+	q := `SELECT ` + operationColumns + ` FROM operation ORDER BY CreatedAt`
+	return db.readOperations(q)
+}
+
+func (db MySqlDatabase) ReadClusterOperations(clusterId string) ([]protobuf.Operation, error) {
+	q := `SELECT ` + operationColumns + ` FROM operation WHERE ClusterID = ? ORDER BY CreatedAt`
+	return db.readOperations(q, clusterId)
+}
+
+func (db MySqlDatabase) WriteOperation(operation *protobuf.Operation) error {
+	q := `INSERT INTO operation (
+				ID, ClusterID, ProjectID, Action, Status, CreatedAt, OwnerID,
+				StartedAt, FinishedAt, Phase, Result, Error, Service, Node, ServiceAction, Params, ServiceVersion,
+				Restart
+		) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`
+
+	params, err := json.Marshal(operation.Params)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
+
+	_, err = db.connection.Exec(q, operation.ID, operation.ClusterID, operation.ProjectID,
+		operation.Action, operation.Status, operation.CreatedAt, operation.OwnerID,
+		operation.StartedAt, operation.FinishedAt, operation.Phase, operation.Result, operation.Error,
+		operation.Service, operation.Node, operation.ServiceAction, params, operation.ServiceVersion,
+		operation.Restart)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+
+	return nil
+}
+
+func (db MySqlDatabase) UpdateOperation(operation *protobuf.Operation) error {
+	q := `UPDATE operation SET 
+				Status = ?, StartedAt = ?, FinishedAt = ?, Phase = ?, Result = ?, Error = ?,
+				Play = ?, Task = ?, HostResults = ?, CancelRequested = ?, CleanupOnCancel = ?
+		  WHERE ID = ?`
+
+	hostResults, err := json.Marshal(operation.HostResults)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
+
+	_, err = db.connection.Exec(q, operation.Status, operation.StartedAt, operation.FinishedAt,
+		operation.Phase, operation.Result, operation.Error,
+		operation.Play, operation.Task, hostResults,
+		operation.CancelRequested, operation.CleanupOnCancel, operation.ID)
+	if err != nil {
+		return ErrUpdateObjectByKey
+	}
+
+	return nil
+}
+
+const cloudColumns = `ID, Name, Type, COALESCE(Description, ''), COALESCE(VaultKey, ''), COALESCE(OsVersion, ''),
+		COALESCE(VirtualNetwork, ''), COALESCE(FloatingIP, ''), COALESCE(KeyName, '')`
+
+func scanCloud(row rowScanner, cloud *protobuf.Cloud) error {
+	return row.Scan(&cloud.ID, &cloud.Name, &cloud.Type, &cloud.Description, &cloud.VaultKey, &cloud.OsVersion,
+		&cloud.VirtualNetwork, &cloud.FloatingIP, &cloud.KeyName)
+}
+
+func (db MySqlDatabase) ReadCloud(cloudIdOrName string) (*protobuf.Cloud, error) {
+	q := `SELECT ` + cloudColumns + ` FROM cloud WHERE Name = ?`
+	if utils.IsUuid(cloudIdOrName) {
+		q = `SELECT ` + cloudColumns + ` FROM cloud WHERE ID = ?`
+	}
+
+	var cloud protobuf.Cloud
+	res := db.connection.QueryRow(q, cloudIdOrName)
+	if err := scanCloud(res, &cloud); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrObjectNotFound("cloud", cloudIdOrName)
+		}
+		return nil, ErrScanRows
+	}
+	return &cloud, nil
+}
+
+func (db MySqlDatabase) ReadCloudsList() ([]protobuf.Cloud, error) {
+	q := `SELECT ` + cloudColumns + ` FROM cloud`
+	rows, err := db.connection.Query(q)
+	if err != nil {
+		return nil, ErrReadObjectList
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	clouds := []protobuf.Cloud{}
+	for rows.Next() {
+		var cloud protobuf.Cloud
+		if err := scanCloud(rows, &cloud); err != nil {
+			return nil, ErrReadObjectList
+		}
+		clouds = append(clouds, cloud)
+	}
+	return clouds, nil
+}
+
+func (db MySqlDatabase) WriteCloud(cloud *protobuf.Cloud) error {
+	q := `INSERT INTO cloud (
+				ID, Name, Type, Description, VaultKey, OsVersion, VirtualNetwork, FloatingIP, KeyName
+		) VALUES (?,?,?,?,?,?,?,?,?)`
+	_, err := db.connection.Exec(q, cloud.ID, cloud.Name, cloud.Type, cloud.Description, cloud.VaultKey,
+		cloud.OsVersion, cloud.VirtualNetwork, cloud.FloatingIP, cloud.KeyName)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db MySqlDatabase) UpdateCloud(cloud *protobuf.Cloud) error {
+	q := `UPDATE cloud SET 
+				Name = ?, Type = ?, Description = ?, VaultKey = ?, OsVersion = ?,
+				VirtualNetwork = ?, FloatingIP = ?, KeyName = ?
+		  WHERE ID = ?`
+	_, err := db.connection.Exec(q, cloud.Name, cloud.Type, cloud.Description, cloud.VaultKey,
+		cloud.OsVersion, cloud.VirtualNetwork, cloud.FloatingIP, cloud.KeyName, cloud.ID)
+	if err != nil {
+		return ErrUpdateObjectByKey
+	}
+	return nil
+}
+
+func (db MySqlDatabase) DeleteCloud(cloudIdOrName string) error {
+	q := `DELETE FROM cloud WHERE Name = ?`
+	if utils.IsUuid(cloudIdOrName) {
+		q = `DELETE FROM cloud WHERE ID = ?`
+	}
+	_, err := db.connection.Exec(q, cloudIdOrName)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+const autoscalingPolicyColumns = `ClusterID, ProjectID, Enabled, MinSlaves, MaxSlaves, MetricSource, MetricURL,
+		COALESCE(MetricQuery, ''), ScaleUpThreshold, ScaleDownThreshold, ScaleStep, Cooldown, COALESCE(LastScaledAt, '')`
+
+func scanAutoscalingPolicy(row rowScanner, policy *protobuf.AutoscalingPolicy) error {
+	return row.Scan(&policy.ClusterID, &policy.ProjectID, &policy.Enabled, &policy.MinSlaves, &policy.MaxSlaves,
+		&policy.MetricSource, &policy.MetricURL, &policy.MetricQuery, &policy.ScaleUpThreshold,
+		&policy.ScaleDownThreshold, &policy.ScaleStep, &policy.Cooldown, &policy.LastScaledAt)
+}
+
+func (db MySqlDatabase) ReadAutoscalingPolicy(clusterId string) (*protobuf.AutoscalingPolicy, error) {
+	q := `SELECT ` + autoscalingPolicyColumns + ` FROM autoscaling_policy WHERE ClusterID = ?`
+
+	var policy protobuf.AutoscalingPolicy
+	res := db.connection.QueryRow(q, clusterId)
+	if err := scanAutoscalingPolicy(res, &policy); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrObjectNotFound("autoscaling policy", clusterId)
+		}
+		return nil, ErrScanRows
+	}
+	return &policy, nil
+}
+
+func (db MySqlDatabase) ReadAutoscalingPoliciesList() ([]protobuf.AutoscalingPolicy, error) {
+	q := `SELECT ` + autoscalingPolicyColumns + ` FROM autoscaling_policy`
+	rows, err := db.connection.Query(q)
+	if err != nil {
+		return nil, ErrReadObjectList
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.AutoscalingPolicy
+	for rows.Next() {
+		var policy protobuf.AutoscalingPolicy
+		if err := scanAutoscalingPolicy(rows, &policy); err != nil {
+			return nil, ErrReadObjectList
+		}
+		result = append(result, policy)
+	}
+	return result, nil
+}
+
+func (db MySqlDatabase) WriteAutoscalingPolicy(policy *protobuf.AutoscalingPolicy) error {
+	q := `REPLACE INTO autoscaling_policy (
+				ClusterID, ProjectID, Enabled, MinSlaves, MaxSlaves, MetricSource, MetricURL, MetricQuery,
+				ScaleUpThreshold, ScaleDownThreshold, ScaleStep, Cooldown, LastScaledAt
+		) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)`
+	_, err := db.connection.Exec(q, policy.ClusterID, policy.ProjectID, policy.Enabled, policy.MinSlaves,
+		policy.MaxSlaves, policy.MetricSource, policy.MetricURL, policy.MetricQuery, policy.ScaleUpThreshold,
+		policy.ScaleDownThreshold, policy.ScaleStep, policy.Cooldown, policy.LastScaledAt)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db MySqlDatabase) DeleteAutoscalingPolicy(clusterId string) error {
+	q := `DELETE FROM autoscaling_policy WHERE ClusterID = ?`
+	_, err := db.connection.Exec(q, clusterId)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+const scalingDecisionColumns = `ID, ClusterID, ProjectID, CreatedAt, MetricValue, FromSlaves, ToSlaves,
+		COALESCE(Reason, ''), COALESCE(OperationID, ''), COALESCE(Error, '')`
+
+func (db MySqlDatabase) ReadClusterScalingDecisions(clusterId string) ([]protobuf.ScalingDecision, error) {
+	q := `SELECT ` + scalingDecisionColumns + ` FROM scaling_decision WHERE ClusterID = ? ORDER BY CreatedAt`
+	rows, err := db.connection.Query(q, clusterId)
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.ScalingDecision
+	for rows.Next() {
+		var d protobuf.ScalingDecision
+		err := rows.Scan(&d.ID, &d.ClusterID, &d.ProjectID, &d.CreatedAt, &d.MetricValue, &d.FromSlaves,
+			&d.ToSlaves, &d.Reason, &d.OperationID, &d.Error)
+		if err != nil {
+			return nil, ErrScanRows
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (db MySqlDatabase) WriteScalingDecision(decision *protobuf.ScalingDecision) error {
+	q := `INSERT INTO scaling_decision (
+				ID, ClusterID, ProjectID, CreatedAt, MetricValue, FromSlaves, ToSlaves, Reason, OperationID, Error
+		) VALUES (?,?,?,?,?,?,?,?,?,?)`
+	_, err := db.connection.Exec(q, decision.ID, decision.ClusterID, decision.ProjectID, decision.CreatedAt,
+		decision.MetricValue, decision.FromSlaves, decision.ToSlaves, decision.Reason, decision.OperationID,
+		decision.Error)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+const usageRecordColumns = `ID, ProjectID, ClusterID, ClusterName, Flavor, Nodes, VCPUs, RAM, StartedAt, COALESCE(EndedAt, '')`
+
+func readUsageRecords(db MySqlDatabase, q string, args ...interface{}) ([]protobuf.UsageRecord, error) {
+	rows, err := db.connection.Query(q, args...)
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.UsageRecord
+	for rows.Next() {
+		var r protobuf.UsageRecord
+		err := rows.Scan(&r.ID, &r.ProjectID, &r.ClusterID, &r.ClusterName, &r.Flavor, &r.Nodes, &r.VCPUs, &r.RAM,
+			&r.StartedAt, &r.EndedAt)
+		if err != nil {
+			return nil, ErrScanRows
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// ReadUsageRecords returns usage records of the project which overlap the period,
+// records of all projects are returned if the project is not set
+func (db MySqlDatabase) ReadUsageRecords(projectId string, from string, to string) ([]protobuf.UsageRecord, error) {
+	q := `SELECT ` + usageRecordColumns + ` FROM usage_record
+			WHERE StartedAt < ? AND (EndedAt IS NULL OR EndedAt = '' OR EndedAt > ?)`
+	args := []interface{}{to, from}
+	if projectId != "" {
+		q += ` AND ProjectID = ?`
+		args = append(args, projectId)
+	}
+	return readUsageRecords(db, q+` ORDER BY StartedAt`, args...)
+}
+
+func (db MySqlDatabase) ReadClusterOpenUsageRecords(clusterId string) ([]protobuf.UsageRecord, error) {
+	q := `SELECT ` + usageRecordColumns + ` FROM usage_record
+			WHERE ClusterID = ? AND (EndedAt IS NULL OR EndedAt = '')`
+	return readUsageRecords(db, q, clusterId)
+}
+
+func (db MySqlDatabase) WriteUsageRecord(record *protobuf.UsageRecord) error {
+	q := `REPLACE INTO usage_record (
+				ID, ProjectID, ClusterID, ClusterName, Flavor, Nodes, VCPUs, RAM, StartedAt, EndedAt
+		) VALUES (?,?,?,?,?,?,?,?,?,?)`
+	_, err := db.connection.Exec(q, record.ID, record.ProjectID, record.ClusterID, record.ClusterName,
+		record.Flavor, record.Nodes, record.VCPUs, record.RAM, record.StartedAt, record.EndedAt)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+const webhookColumns = `ID, ProjectID, URL, COALESCE(Secret, ''), Events, COALESCE(Description, ''), CreatedAt`
+
+func scanWebhook(row rowScanner, webhook *protobuf.Webhook) error {
+	var events []byte
+	err := row.Scan(&webhook.ID, &webhook.ProjectID, &webhook.URL, &webhook.Secret, &events,
+		&webhook.Description, &webhook.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if len(events) > 0 {
+		if err := json.Unmarshal(events, &webhook.Events); err != nil {
+			return ErrUnmarshalJson
+		}
+	}
+	return nil
+}
+
+func (db MySqlDatabase) ReadWebhook(webhookId string) (*protobuf.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhook WHERE ID = ?`
+
+	var webhook protobuf.Webhook
+	res := db.connection.QueryRow(q, webhookId)
+	if err := scanWebhook(res, &webhook); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrObjectNotFound("webhook", webhookId)
+		}
+		return nil, ErrScanRows
+	}
+	return &webhook, nil
+}
+
+func (db MySqlDatabase) ReadProjectWebhooks(projectId string) ([]protobuf.Webhook, error) {
+	q := `SELECT ` + webhookColumns + ` FROM webhook WHERE ProjectID = ? ORDER BY CreatedAt`
+	rows, err := db.connection.Query(q, projectId)
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.Webhook
+	for rows.Next() {
+		var webhook protobuf.Webhook
+		if err := scanWebhook(rows, &webhook); err != nil {
+			return nil, ErrScanRows
+		}
+		result = append(result, webhook)
+	}
+	return result, nil
+}
+
+func (db MySqlDatabase) WriteWebhook(webhook *protobuf.Webhook) error {
+	events, err := json.Marshal(webhook.Events)
+	if err != nil {
+		return ErrUnmarshalJson
+	}
+	q := `REPLACE INTO webhook (
+				ID, ProjectID, URL, Secret, Events, Description, CreatedAt
+		) VALUES (?,?,?,?,?,?,?)`
+	_, err = db.connection.Exec(q, webhook.ID, webhook.ProjectID, webhook.URL, webhook.Secret, events,
+		webhook.Description, webhook.CreatedAt)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
+
+func (db MySqlDatabase) DeleteWebhook(webhookId string) error {
+	q := `DELETE FROM webhook WHERE ID = ?`
+	_, err := db.connection.Exec(q, webhookId)
+	if err != nil {
+		return ErrDeleteObjectByKey
+	}
+	return nil
+}
+
+const webhookDeliveryColumns = `ID, WebhookID, Event, ClusterID, Payload, Status, Attempts,
+		COALESCE(ResponseStatus, 0), COALESCE(Error, ''), CreatedAt, COALESCE(DeliveredAt, '')`
+
+func (db MySqlDatabase) ReadWebhookDeliveries(webhookId string) ([]protobuf.WebhookDelivery, error) {
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_delivery WHERE WebhookID = ? ORDER BY CreatedAt`
+	return db.queryWebhookDeliveries(q, webhookId)
+}
+
+func (db MySqlDatabase) ReadWebhookDeliveriesByStatus(status string) ([]protobuf.WebhookDelivery, error) {
+	q := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_delivery WHERE Status = ? ORDER BY CreatedAt`
+	return db.queryWebhookDeliveries(q, status)
+}
+
+func (db MySqlDatabase) queryWebhookDeliveries(q string, arg string) ([]protobuf.WebhookDelivery, error) {
+	rows, err := db.connection.Query(q, arg)
+	if err != nil {
+		return nil, ErrQueryExecution
+	}
+	if err := rows.Err(); err != nil {
+		return nil, ErrReadObjectList
+	}
+	defer rows.Close()
+
+	var result []protobuf.WebhookDelivery
+	for rows.Next() {
+		var d protobuf.WebhookDelivery
+		err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &d.ClusterID, &d.Payload, &d.Status, &d.Attempts,
+			&d.ResponseStatus, &d.Error, &d.CreatedAt, &d.DeliveredAt)
+		if err != nil {
+			return nil, ErrScanRows
+		}
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (db MySqlDatabase) WriteWebhookDelivery(delivery *protobuf.WebhookDelivery) error {
+	q := `REPLACE INTO webhook_delivery (
+				ID, WebhookID, Event, ClusterID, Payload, Status, Attempts, ResponseStatus, Error, CreatedAt, DeliveredAt
+		) VALUES (?,?,?,?,?,?,?,?,?,?,?)`
+	_, err := db.connection.Exec(q, delivery.ID, delivery.WebhookID, delivery.Event, delivery.ClusterID,
+		delivery.Payload, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.Error,
+		delivery.CreatedAt, delivery.DeliveredAt)
+	if err != nil {
+		return ErrWriteObjectByKey
+	}
+	return nil
+}
@@ -1,14 +1,17 @@
 package logger
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"github.com/ispras/michman/internal/utils"
 	"io"
 	"io/ioutil"
 	"os"
 )
 
+// maxLogsChunkSize limits the size of the logs read from the file at once
+const maxLogsChunkSize = 1 << 20
+
 type FileLogger struct {
 	filePath  string
 	logFile   *os.File
@@ -16,14 +19,10 @@ type FileLogger struct {
 	action    string
 }
 
-// NewFileLogger create new cluster logger file
-func NewFileLogger(clusterID string, action string) (Logger, error) {
+// NewFileLogger create new cluster logger file in the logs directory
+func NewFileLogger(filePath string, clusterID string, action string) (Logger, error) {
 	fl := new(FileLogger)
-	config := utils.Config{}
-	if err := config.MakeCfg(); err != nil {
-		return nil, err
-	}
-	fl.filePath = config.LogsFilePath
+	fl.filePath = filePath
 
 	fl.clusterID = clusterID
 	fl.action = action
@@ -65,3 +64,34 @@ func (fl FileLogger) ReadClusterLogs() (string, error) {
 	}
 	return string(clusterLogs), nil
 }
+
+// ReadClusterLogsFrom read cluster logs from file starting with the byte offset
+func (fl FileLogger) ReadClusterLogsFrom(offset int64) (string, int64, error) {
+	info, err := fl.logFile.Stat()
+	if err != nil {
+		return "", offset, err
+	}
+	if info.Size() <= offset {
+		return "", offset, nil
+	}
+
+	size := info.Size() - offset
+	if size > maxLogsChunkSize {
+		size = maxLogsChunkSize
+	}
+	buf := make([]byte, size)
+	n, err := fl.logFile.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return "", offset, err
+	}
+
+	// the last line is read when it is completely written, unless it doesn't fit into the chunk
+	end := bytes.LastIndexByte(buf[:n], '\n')
+	if end < 0 {
+		if n < maxLogsChunkSize {
+			return "", offset, nil
+		}
+		end = n - 1
+	}
+	return string(buf[:end+1]), offset + int64(end+1), nil
+}
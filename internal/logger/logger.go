@@ -9,6 +9,9 @@ type Logger interface {
 	PrepClusterLogsWriter() (io.Writer, error)
 	FinClusterLogsWriter() error
 	ReadClusterLogs() (string, error)
+	// ReadClusterLogsFrom reads complete log lines written after the offset and returns the offset for the next read.
+	// Offset is an opaque position returned by the previous call, 0 reads logs from the beginning.
+	ReadClusterLogsFrom(offset int64) (string, int64, error)
 }
 
 // MakeNewClusterLogger create new cluster logger file or logstash logger (depending on 'logs_output' in configuration file)
@@ -16,12 +19,12 @@ func MakeNewClusterLogger(cfg utils.Config, clusterID string, action string) (Lo
 	var clusterLogger Logger
 	var err error
 	if cfg.LogsOutput == utils.LogsFileOutput {
-		clusterLogger, err = NewFileLogger(clusterID, action)
+		clusterLogger, err = NewFileLogger(cfg.LogsFilePath, clusterID, action)
 		if err != nil {
 			return nil, err
 		}
 	} else if cfg.LogsOutput == utils.LogsLogstashOutput {
-		clusterLogger, err = NewLogstashLogger(clusterID, action)
+		clusterLogger, err = NewLogstashLogger(cfg.LogstashAddr, cfg.ElasticAddr, clusterID, action)
 		if err != nil {
 			return nil, err
 		}
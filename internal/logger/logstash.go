@@ -3,23 +3,36 @@ package logger
 import (
 	"bytes"
 	"encoding/json"
-	"github.com/ispras/michman/internal/utils"
+	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 )
 
+// clusterLog is a chunk of cluster logs sent to logstash, Seq orders chunks of the cluster action
 type clusterLog struct {
 	ClusterName string `json:"Cluster_name"`
 	Data        string `json:"Data"`
+	Seq         int64  `json:"Seq"`
 }
 
 const (
-	sqlQueryPath = "/_sql?format=txt"
+	sqlQueryPath     = "/_sql?format=json"
+	sqlFetchSize     = 1000
+	logsSendInterval = 2 * time.Second
 )
 
 type queryLog struct {
-	Query string `json:"query"`
+	Query     string `json:"query,omitempty"`
+	FetchSize int    `json:"fetch_size,omitempty"`
+	Cursor    string `json:"cursor,omitempty"`
+}
+
+type queryLogResult struct {
+	Rows   [][]interface{} `json:"rows"`
+	Cursor string          `json:"cursor"`
 }
 
 type LogstashLogger struct {
@@ -27,21 +40,26 @@ type LogstashLogger struct {
 	elasticAddr  string
 	clusterID    string
 	action       string
-	logsBuffer   *bytes.Buffer
+	writer       *logstashWriter
+}
+
+// logstashWriter buffers cluster logs and periodically sends complete lines to logstash
+type logstashWriter struct {
+	mutex   sync.Mutex
+	buf     bytes.Buffer
+	send    func(data string) error
+	stop    chan struct{}
+	stopped chan struct{}
 }
 
 // NewLogstashLogger create new cluster logstash logger
-func NewLogstashLogger(clusterID string, action string) (Logger, error) {
+func NewLogstashLogger(logstashAddr string, elasticAddr string, clusterID string, action string) (Logger, error) {
 	ll := new(LogstashLogger)
-	config := utils.Config{}
-	if err := config.MakeCfg(); err != nil {
-		return nil, err
-	}
-	ll.logstashAddr = config.LogstashAddr
-	ll.elasticAddr = config.ElasticAddr
+	ll.logstashAddr = logstashAddr
+	ll.elasticAddr = elasticAddr
 	ll.clusterID = clusterID
 	ll.action = action
-	ll.logsBuffer = new(bytes.Buffer)
+	ll.writer = &logstashWriter{send: ll.sendLogs}
 	return ll, nil
 }
 
@@ -51,11 +69,24 @@ func makeClusterName(clusterID string, action string) string {
 }
 
 func (ll LogstashLogger) PrepClusterLogsWriter() (io.Writer, error) {
-	return ll.logsBuffer, nil
+	ll.writer.stop = make(chan struct{})
+	ll.writer.stopped = make(chan struct{})
+	go ll.writer.run()
+	return ll.writer, nil
 }
 
 func (ll LogstashLogger) FinClusterLogsWriter() error {
-	cLog := clusterLog{ClusterName: makeClusterName(ll.clusterID, ll.action), Data: ll.logsBuffer.String()}
+	if ll.writer.stop == nil {
+		return nil
+	}
+	close(ll.writer.stop)
+	<-ll.writer.stopped
+	return ll.writer.flush(true)
+}
+
+// sendLogs sends chunk of cluster logs to logstash
+func (ll LogstashLogger) sendLogs(data string) error {
+	cLog := clusterLog{ClusterName: makeClusterName(ll.clusterID, ll.action), Data: data, Seq: time.Now().UnixNano()}
 	client := http.Client{}
 
 	jsonLogs, err := json.Marshal(cLog)
@@ -71,40 +102,135 @@ func (ll LogstashLogger) FinClusterLogsWriter() error {
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "*/*")
 
-	if _, err := client.Do(req); err != nil {
+	resp, err := client.Do(req)
+	if err != nil {
 		return err
 	}
+	resp.Body.Close()
 	return nil
 }
 
 // ReadClusterLogs read cluster logs from logstash
 func (ll LogstashLogger) ReadClusterLogs() (string, error) {
-	getLogQuery := "SELECT * FROM \"" + makeClusterName(ll.clusterID, ll.action) + "\""
-	logs := queryLog{Query: getLogQuery}
+	logs, _, err := ll.ReadClusterLogsFrom(0)
+	return logs, err
+}
+
+// ReadClusterLogsFrom read cluster logs from logstash sent after the chunk with the offset sequence number
+func (ll LogstashLogger) ReadClusterLogsFrom(offset int64) (string, int64, error) {
+	getLogQuery := fmt.Sprintf("SELECT Seq, Data FROM \"%s\" WHERE Seq > %d ORDER BY Seq",
+		makeClusterName(ll.clusterID, ll.action), offset)
+	if offset == 0 {
+		// logs saved before chunks got sequence numbers are read too
+		getLogQuery = fmt.Sprintf("SELECT Seq, Data FROM \"%s\" ORDER BY Seq", makeClusterName(ll.clusterID, ll.action))
+	}
 
+	var logs strings.Builder
+	query := queryLog{Query: getLogQuery, FetchSize: sqlFetchSize}
+	for {
+		res, err := ll.query(query)
+		if err != nil {
+			return "", offset, err
+		}
+		for _, row := range res.Rows {
+			if len(row) != 2 {
+				continue
+			}
+			if seq, ok := row[0].(json.Number); ok {
+				if n, err := seq.Int64(); err == nil && n > offset {
+					offset = n
+				}
+			}
+			if data, ok := row[1].(string); ok {
+				logs.WriteString(data)
+			}
+		}
+		if res.Cursor == "" {
+			break
+		}
+		query = queryLog{Cursor: res.Cursor}
+	}
+	return logs.String(), offset, nil
+}
+
+// query runs elastic sql query and returns a page of its result
+func (ll LogstashLogger) query(query queryLog) (*queryLogResult, error) {
 	client := http.Client{}
 
-	jsonLog, err := json.Marshal(logs)
+	jsonLog, err := json.Marshal(query)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequest(http.MethodPost, ll.elasticAddr+sqlQueryPath, bytes.NewBuffer(jsonLog))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := client.Do(req)
-	if err != nil || resp.StatusCode != http.StatusOK {
-		return "", err
+	if err != nil {
+		return nil, err
 	}
 	defer resp.Body.Close()
-	bodyBytes, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("elastic sql query failed with status %s", resp.Status)
+	}
+
+	// sequence numbers don't fit into float64, so numbers are decoded as they are
+	res := new(queryLogResult)
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (w *logstashWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.buf.Write(p)
+}
+
+// run sends buffered logs until the writer is stopped
+func (w *logstashWriter) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(logsSendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			// logs which failed to be sent are sent again with the next chunk
+			_ = w.flush(false)
+		}
+	}
+}
+
+// flush sends complete lines from the buffer, or the whole buffer if all is set
+func (w *logstashWriter) flush(all bool) error {
+	w.mutex.Lock()
+	data := w.buf.Bytes()
+	if !all {
+		data = data[:bytes.LastIndexByte(data, '\n')+1]
+	}
+	chunk := string(data)
+	w.buf.Next(len(data))
+	w.mutex.Unlock()
+
+	if chunk == "" {
+		return nil
+	}
+	err := w.send(chunk)
 	if err != nil {
-		return "", err
+		w.mutex.Lock()
+		rest := w.buf.String()
+		w.buf.Reset()
+		w.buf.WriteString(chunk + rest)
+		w.mutex.Unlock()
 	}
-	bodyString := string(bodyBytes)
-	return bodyString, nil
+	return err
 }
@@ -0,0 +1,233 @@
+package autoscaler
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const DefaultInterval = time.Minute
+
+// OperationQueue starts cluster operations requested by the autoscaler
+type OperationQueue interface {
+	Enqueue(cluster *protobuf.Cluster, action string, ownerId string) (*protobuf.Operation, error)
+}
+
+// Autoscaler periodically evaluates autoscaling policies of the clusters
+// and changes their number of slaves through the operation queue
+type Autoscaler struct {
+	Db       database.Database
+	Queue    OperationQueue
+	Metrics  MetricReader
+	Logger   *logrus.Logger
+	Interval time.Duration
+}
+
+// Run evaluates autoscaling policies until the rest service stops
+func (a Autoscaler) Run() {
+	interval := a.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		a.Evaluate()
+	}
+}
+
+// Evaluate runs one evaluation of all enabled autoscaling policies
+func (a Autoscaler) Evaluate() {
+	policies, err := a.Db.ReadAutoscalingPoliciesList()
+	if err != nil {
+		a.Logger.Warn(err)
+		return
+	}
+	for i := range policies {
+		policy := &policies[i]
+		if !policy.Enabled || InCooldown(policy, time.Now()) {
+			continue
+		}
+		a.evaluatePolicy(policy)
+	}
+}
+
+// evaluatePolicy scales the cluster of the policy if its metric crossed a threshold
+func (a Autoscaler) evaluatePolicy(policy *protobuf.AutoscalingPolicy) {
+	cluster, err := a.Db.ReadCluster(policy.ProjectID, policy.ClusterID)
+	if err != nil {
+		a.Logger.Warn(err)
+		return
+	}
+	// cluster with the running operation is evaluated after the operation is finished
+	if cluster.EntityStatus != utils.StatusActive {
+		return
+	}
+
+	value, err := a.Metrics.Read(policy)
+	if err != nil {
+		a.Logger.Warnf("Metric of cluster %s autoscaling policy can't be read: %s", cluster.Name, err.Error())
+		return
+	}
+
+	target, reason := Decide(policy, cluster.NSlaves, value)
+	if target == cluster.NSlaves {
+		return
+	}
+
+	decisionUuid, err := uuid.NewRandom()
+	if err != nil {
+		a.Logger.Warn(ErrUuidLibError)
+		return
+	}
+	decision := &protobuf.ScalingDecision{
+		ID:          decisionUuid.String(),
+		ClusterID:   cluster.ID,
+		ProjectID:   cluster.ProjectID,
+		CreatedAt:   now(),
+		MetricValue: value,
+		FromSlaves:  cluster.NSlaves,
+		ToSlaves:    target,
+		Reason:      reason,
+	}
+
+	a.Logger.Infof("Scaling cluster %s from %d to %d slaves: %s", cluster.Name, cluster.NSlaves, target, reason)
+	op, err := a.scale(cluster, target)
+	if err != nil {
+		a.Logger.Warn(err)
+		decision.Error = err.Error()
+	} else {
+		decision.OperationID = op.ID
+	}
+
+	err = a.Db.WriteScalingDecision(decision)
+	if err != nil {
+		a.Logger.Warn(err)
+	}
+
+	// failed decisions also start the cooldown, so they are not repeated on every evaluation;
+	// policy is read again not to overwrite its changes made by the user meanwhile
+	policy, err = a.Db.ReadAutoscalingPolicy(cluster.ID)
+	if err == nil {
+		policy.LastScaledAt = decision.CreatedAt
+		err = a.Db.WriteAutoscalingPolicy(policy)
+	}
+	if err != nil {
+		a.Logger.Warn(err)
+	}
+}
+
+// scale saves the new number of slaves of the cluster and enqueues its operation:
+// new slaves are added by the cluster creation, as on the cluster update,
+// and extra slaves are removed by the scale operation.
+// Cluster is claimed for the operation by its status before it is changed, so concurrent requests
+// are rejected by the cluster validation and the cluster evaluated by the autoscaler is not overwritten
+func (a Autoscaler) scale(cluster *protobuf.Cluster, target int32) (*protobuf.Operation, error) {
+	claimed, err := a.Db.UpdateClusterStatus(cluster.ID, utils.StatusActive, utils.StatusInited)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrClusterChanged(cluster.Name)
+	}
+
+	op, err := a.scaleClaimed(cluster, target)
+	if err != nil {
+		// cluster is released, if its operation is not started
+		if _, releaseErr := a.Db.UpdateClusterStatus(cluster.ID, utils.StatusInited, utils.StatusActive); releaseErr != nil {
+			a.Logger.Warn(releaseErr)
+		}
+		return nil, err
+	}
+	return op, nil
+}
+
+// scaleClaimed changes the cluster claimed for the scaling as it is saved now
+func (a Autoscaler) scaleClaimed(cluster *protobuf.Cluster, target int32) (*protobuf.Operation, error) {
+	current, err := a.Db.ReadCluster(cluster.ProjectID, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	// decision is made for the evaluated number of slaves
+	if current.NSlaves != cluster.NSlaves {
+		return nil, ErrClusterChanged(cluster.Name)
+	}
+
+	action := utils.ActionCreate
+	if target > current.NSlaves {
+		current.NSlaves = target
+		// added slaves must fit into the project quotas
+		project, err := a.Db.ReadProject(current.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		err = validate.ClusterQuotas(a.Db, project, current)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if len(helpfunc.ClusterSlaveNodes(current)) == 0 {
+			return nil, ErrNoSlaveNodes
+		}
+		helpfunc.MarkRemovedNodes(current, &protobuf.ScaleRequest{NSlaves: target})
+		action = utils.ActionScale
+	}
+
+	current.EntityStatus = utils.StatusInited
+	err = a.Db.UpdateCluster(current)
+	if err != nil {
+		return nil, err
+	}
+	return a.Queue.Enqueue(current, action, utils.AutoscalerOwnerID)
+}
+
+// Decide returns the number of slaves the cluster should have according to the policy
+// and the current metric value, together with the reason of the change
+func Decide(policy *protobuf.AutoscalingPolicy, nSlaves int32, value float64) (int32, string) {
+	step := policy.ScaleStep
+	if step <= 0 {
+		step = 1
+	}
+
+	switch {
+	case nSlaves < policy.MinSlaves:
+		return policy.MinSlaves, fmt.Sprintf("number of slaves is less than the policy minimum %d", policy.MinSlaves)
+	case nSlaves > policy.MaxSlaves:
+		return policy.MaxSlaves, fmt.Sprintf("number of slaves is greater than the policy maximum %d", policy.MaxSlaves)
+	case value >= policy.ScaleUpThreshold && nSlaves < policy.MaxSlaves:
+		target := nSlaves + step
+		if target > policy.MaxSlaves {
+			target = policy.MaxSlaves
+		}
+		return target, fmt.Sprintf("metric value %g reached the scale up threshold %g", value, policy.ScaleUpThreshold)
+	case value <= policy.ScaleDownThreshold && nSlaves > policy.MinSlaves:
+		target := nSlaves - step
+		if target < policy.MinSlaves {
+			target = policy.MinSlaves
+		}
+		return target, fmt.Sprintf("metric value %g reached the scale down threshold %g", value, policy.ScaleDownThreshold)
+	}
+	return nSlaves, ""
+}
+
+// InCooldown checks whether the cooldown period after the last scaling of the policy is not over
+func InCooldown(policy *protobuf.AutoscalingPolicy, t time.Time) bool {
+	if policy.LastScaledAt == "" {
+		return false
+	}
+	lastScaledAt, err := time.Parse(time.RFC3339, policy.LastScaledAt)
+	if err != nil {
+		return false
+	}
+	return t.Before(lastScaledAt.Add(time.Duration(policy.Cooldown) * time.Second))
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,36 @@
+package autoscaler
+
+import (
+	"fmt"
+	"github.com/ispras/michman/internal/rest"
+	"github.com/ispras/michman/internal/utils"
+)
+
+const (
+	errUuidLibError     = "uuid generation error"
+	errMetricResult     = "metric query must return a single value"
+	errNoSlaveNodes     = "cluster has no discovered slave nodes to remove"
+	errPrometheusStatus = "prometheus query has not succeeded"
+)
+
+var (
+	ErrUuidLibError     = rest.MakeError(errUuidLibError, utils.LibError)
+	ErrMetricResult     = rest.MakeError(errMetricResult, utils.ParseError)
+	ErrNoSlaveNodes     = rest.MakeError(errNoSlaveNodes, utils.ObjectUnmodified)
+	ErrPrometheusStatus = rest.MakeError(errPrometheusStatus, utils.UnexpectedError)
+)
+
+func ErrMetricSource(source string) error {
+	errMessage := fmt.Sprintf("unknown autoscaling metric source: %s", source)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrMetricStatus(status string) error {
+	errMessage := fmt.Sprintf("metric source responded with status %s", status)
+	return rest.MakeError(errMessage, utils.UnexpectedError)
+}
+
+func ErrClusterChanged(clusterName string) error {
+	errMessage := fmt.Sprintf("cluster %s is changed while its autoscaling policy is evaluated", clusterName)
+	return rest.MakeError(errMessage, utils.ObjectUnmodified)
+}
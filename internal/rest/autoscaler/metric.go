@@ -0,0 +1,124 @@
+package autoscaler
+
+import (
+	"encoding/json"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	prometheusQueryPath = "/api/v1/query"
+	metricReadTimeout   = 10 * time.Second
+)
+
+// MetricReader reads current value of the autoscaling policy metric
+type MetricReader interface {
+	Read(policy *protobuf.AutoscalingPolicy) (float64, error)
+}
+
+// HttpMetricReader reads metrics from prometheus or from plain http endpoints returning a number
+type HttpMetricReader struct {
+	Client *http.Client
+}
+
+// prometheusResponse is a result of the prometheus instant query
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+func (r HttpMetricReader) Read(policy *protobuf.AutoscalingPolicy) (float64, error) {
+	switch policy.MetricSource {
+	case utils.MetricSourcePrometheus:
+		query := url.Values{"query": {policy.MetricQuery}}
+		body, err := r.get(strings.TrimRight(policy.MetricURL, "/") + prometheusQueryPath + "?" + query.Encode())
+		if err != nil {
+			return 0, err
+		}
+		return ParsePrometheusValue(body)
+	case utils.MetricSourceHttp:
+		body, err := r.get(policy.MetricURL)
+		if err != nil {
+			return 0, err
+		}
+		return ParseHttpValue(body)
+	}
+	return 0, ErrMetricSource(policy.MetricSource)
+}
+
+func (r HttpMetricReader) get(address string) ([]byte, error) {
+	client := r.Client
+	if client == nil {
+		client = &http.Client{Timeout: metricReadTimeout}
+	}
+
+	resp, err := client.Get(address)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrMetricStatus(resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ParsePrometheusValue returns value of the prometheus query result, which must be a scalar or a single sample vector
+func ParsePrometheusValue(body []byte) (float64, error) {
+	var resp prometheusResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, err
+	}
+	if resp.Status != "success" {
+		return 0, ErrPrometheusStatus
+	}
+
+	// sample value is a pair of timestamp and string number
+	var sample []interface{}
+	switch resp.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(resp.Data.Result, &sample); err != nil {
+			return 0, err
+		}
+	case "vector":
+		var vector []struct {
+			Value []interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(resp.Data.Result, &vector); err != nil {
+			return 0, err
+		}
+		if len(vector) != 1 {
+			return 0, ErrMetricResult
+		}
+		sample = vector[0].Value
+	default:
+		return 0, ErrMetricResult
+	}
+
+	if len(sample) != 2 {
+		return 0, ErrMetricResult
+	}
+	value, ok := sample[1].(string)
+	if !ok {
+		return 0, ErrMetricResult
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// ParseHttpValue returns number from the body of the http metric endpoint
+func ParseHttpValue(body []byte) (float64, error) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(body)), 64)
+	if err != nil {
+		return 0, ErrMetricResult
+	}
+	return value, nil
+}
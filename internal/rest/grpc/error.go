@@ -1,19 +1,38 @@
 package grpc
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 const (
 	errServerUnavailable = "gRPC server is currently unavailable"
 	errGrpcConnection    = "gRPC client connection error"
 	errCreate            = "error occurred while executing create request"
 	errModify            = "error occurred while executing update request"
+	errScale             = "error occurred while executing scale request"
 	errDestroy           = "error occurred while executing delete request"
+	errCancel            = "error occurred while executing cancel request"
+	errAction            = "error occurred while executing lifecycle action request"
+	errPlan              = "error occurred while executing plan request"
+	errInspect           = "error occurred while executing inspect request"
+	errStreamUnfinished  = "ansible-service closed progress stream without task status"
 )
 
+func ErrAnsibleStatus(status string) error {
+	return fmt.Errorf("ansible-service finished with status %s", status)
+}
+
 var (
 	ErrServerUnavailable = errors.New(errServerUnavailable)
 	ErrGrpcConnection    = errors.New(errGrpcConnection)
 	ErrCreate            = errors.New(errCreate)
 	ErrModify            = errors.New(errModify)
+	ErrScale             = errors.New(errScale)
 	ErrDestroy           = errors.New(errDestroy)
+	ErrCancel            = errors.New(errCancel)
+	ErrAction            = errors.New(errAction)
+	ErrPlan              = errors.New(errPlan)
+	ErrInspect           = errors.New(errInspect)
+	ErrStreamUnfinished  = errors.New(errStreamUnfinished)
 )
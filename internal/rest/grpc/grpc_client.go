@@ -9,6 +9,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"io"
 	"time"
 )
 
@@ -16,6 +17,14 @@ const (
 	WAITING_TIME = 100
 )
 
+// ProgressHandler receives progress events of the cluster action from ansible-service
+type ProgressHandler func(event *protobuf.ProgressEvent)
+
+// progressStream is implemented by the client side of every streaming launcher rpc
+type progressStream interface {
+	Recv() (*protobuf.ProgressEvent, error)
+}
+
 type GrpcClient struct {
 	ansibleServiceClient protobuf.AnsibleRunnerClient
 	logger               *logrus.Logger
@@ -37,147 +46,344 @@ func (gc *GrpcClient) SetConnection(ansibleServiceAddr string) error {
 }
 
 // StartClusterCreation will send cluster struct to ansible-service for run ansible
-func (gc GrpcClient) StartClusterCreation(c *protobuf.Cluster) {
+func (gc GrpcClient) StartClusterCreation(c *protobuf.Cluster, progress ProgressHandler) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Minute)
 	defer cancel()
 
 	gc.logger.Info("Sending request to ansible-service")
-	message, err := gc.ansibleServiceClient.Create(ctx, c)
+	stream, err := gc.ansibleServiceClient.CreateStream(ctx, c)
+	var taskStatus string
+	if err == nil {
+		taskStatus, err = gc.receiveProgress(stream, progress)
+	}
 
 	if err != nil {
 		errStatus, _ := status.FromError(err)
 		if errStatus.Code() == codes.Unavailable {
-			gc.logger.Warn(ErrServerUnavailable)
+			err = ErrServerUnavailable
 		} else {
-			gc.logger.Warn(ErrCreate)
+			err = ErrCreate
 		}
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
-		if err != nil {
-			gc.logger.Warn(err)
-		}
-		return
+		gc.logger.Warn(err)
+		gc.setClusterFailed(c)
+		return "", err
 	}
 
-	gc.logger.Infof("From ansible-service: %s", message.Status)
+	gc.logger.Infof("From ansible-service: %s", taskStatus)
+
+	if taskStatus == utils.AnsibleCancelled {
+		gc.setClusterCancelled(c)
+		return taskStatus, nil
+	}
 
-	if message.Status != utils.AnsibleOk {
+	if taskStatus != utils.AnsibleOk {
 		// request to db-service about errors with ansible service
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
-		if err != nil {
-			gc.logger.Warn(err)
-		}
-		return
+		gc.setClusterFailed(c)
+		return taskStatus, ErrAnsibleStatus(taskStatus)
 	}
 
-	newC, err := gc.Db.ReadCluster(c.ProjectID, c.ID)
+	return taskStatus, gc.setClusterActive(c)
+}
+
+// StartClusterDestroying will send cluster struct to ansible-service for run ansible delete
+func (gc GrpcClient) StartClusterDestroying(c *protobuf.Cluster, progress ProgressHandler) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Minute)
+	defer cancel()
+
+	gc.logger.Print("Sending request to ansible-service")
+	stream, err := gc.ansibleServiceClient.DeleteStream(ctx, c)
+	var taskStatus string
+	if err == nil {
+		taskStatus, err = gc.receiveProgress(stream, progress)
+	}
 	if err != nil {
+		errStatus, _ := status.FromError(err)
+		if errStatus.Code() == codes.Unavailable {
+			err = ErrServerUnavailable
+		} else {
+			err = ErrDestroy
+		}
 		gc.logger.Warn(err)
-		return
+		gc.setClusterFailed(c)
+		return "", err
 	}
-	if newC.Name == "" {
-		gc.logger.Warn("Cluster with ID %v not found", c.ID)
-		return
+
+	gc.logger.Infof("From ansible-service: %s", taskStatus)
+
+	if taskStatus == utils.AnsibleCancelled {
+		gc.setClusterCancelled(c)
+		return taskStatus, nil
 	}
 
-	gc.logger.Infof("Sending to db-service new status for %s cluster", c.Name)
-	newC.EntityStatus = utils.StatusActive
-	err = gc.Db.UpdateCluster(newC)
+	if taskStatus != utils.AnsibleOk {
+		// request to db-service about errors with ansible service
+		gc.setClusterFailed(c)
+		return taskStatus, ErrAnsibleStatus(taskStatus)
+	}
+
+	gc.logger.Infof("Sending to db-service delete request for %s cluster", c.Name)
+	err = gc.Db.DeleteCluster(c.ProjectID, c.ID)
 	if err != nil {
 		gc.logger.Warn(err)
+		return taskStatus, err
 	}
-	return
+	return taskStatus, nil
 }
 
-// StartClusterDestroying will send cluster struct to ansible-service for run ansible delete
-func (gc GrpcClient) StartClusterDestroying(c *protobuf.Cluster) {
+// StartClusterModification will send cluster struct to ansible-service for run ansible update
+func (gc GrpcClient) StartClusterModification(c *protobuf.Cluster, progress ProgressHandler) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Minute)
 	defer cancel()
 
-	gc.logger.Print("Sending request to ansible-service")
-	message, err := gc.ansibleServiceClient.Delete(ctx, c)
+	gc.logger.Info("Sending request to ansible-service")
+	stream, err := gc.ansibleServiceClient.UpdateStream(ctx, c)
+	var taskStatus string
+	if err == nil {
+		taskStatus, err = gc.receiveProgress(stream, progress)
+	}
 	if err != nil {
 		errStatus, _ := status.FromError(err)
 		if errStatus.Code() == codes.Unavailable {
-			gc.logger.Warn(ErrServerUnavailable)
+			err = ErrServerUnavailable
 		} else {
-			gc.logger.Warn(ErrDestroy)
+			err = ErrModify
 		}
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
-		if err != nil {
-			gc.logger.Warn(err)
-		}
-		return
+		gc.logger.Warn(err)
+		gc.setClusterFailed(c)
+		return "", err
 	}
 
-	gc.logger.Infof("From ansible-service: %s", message.Status)
+	gc.logger.Infof("From ansible-service: %s", taskStatus)
 
-	if message.Status != utils.AnsibleOk {
+	if taskStatus == utils.AnsibleCancelled {
+		gc.setClusterCancelled(c)
+		return taskStatus, nil
+	}
+
+	if taskStatus != utils.AnsibleOk {
 		// request to db-service about errors with ansible service
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
-		if err != nil {
-			gc.logger.Warn(err)
-		}
-		return
+		gc.setClusterFailed(c)
+		return taskStatus, ErrAnsibleStatus(taskStatus)
 	}
 
-	gc.logger.Infof("Sending to db-service delete request for %s cluster", c.Name)
-	err = gc.Db.DeleteCluster(c.ProjectID, c.ID)
+	return taskStatus, gc.setClusterActive(c)
+}
+
+// StartClusterScaling will send cluster struct with nodes marked for removal to ansible-service for run ansible scale
+func (gc GrpcClient) StartClusterScaling(c *protobuf.Cluster, progress ProgressHandler) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Minute)
+	defer cancel()
+
+	gc.logger.Info("Sending request to ansible-service")
+	stream, err := gc.ansibleServiceClient.ScaleStream(ctx, c)
+	var taskStatus string
+	if err == nil {
+		taskStatus, err = gc.receiveProgress(stream, progress)
+	}
 	if err != nil {
+		errStatus, _ := status.FromError(err)
+		if errStatus.Code() == codes.Unavailable {
+			err = ErrServerUnavailable
+		} else {
+			err = ErrScale
+		}
 		gc.logger.Warn(err)
+		gc.setClusterFailed(c)
+		return "", err
 	}
-	return
+
+	gc.logger.Infof("From ansible-service: %s", taskStatus)
+
+	if taskStatus == utils.AnsibleCancelled {
+		gc.setClusterCancelled(c)
+		return taskStatus, nil
+	}
+
+	if taskStatus != utils.AnsibleOk {
+		// request to db-service about errors with ansible service
+		gc.setClusterFailed(c)
+		return taskStatus, ErrAnsibleStatus(taskStatus)
+	}
+
+	return taskStatus, gc.setClusterActive(c)
 }
 
-// StartClusterDestroying will send cluster struct to ansible-service for run ansible delete
-func (gc GrpcClient) StartClusterModification(c *protobuf.Cluster) {
+// StartClusterAction sends lifecycle action of the cluster to ansible-service,
+// cluster becomes stopped after the stop action and active after other ones
+func (gc GrpcClient) StartClusterAction(c *protobuf.Cluster, action *protobuf.ClusterAction, progress ProgressHandler) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Minute)
 	defer cancel()
 
 	gc.logger.Info("Sending request to ansible-service")
-	message, err := gc.ansibleServiceClient.Update(ctx, c)
+	action.Cluster = c
+	stream, err := gc.ansibleServiceClient.RunActionStream(ctx, action)
+	var taskStatus string
+	if err == nil {
+		taskStatus, err = gc.receiveProgress(stream, progress)
+	}
 	if err != nil {
 		errStatus, _ := status.FromError(err)
 		if errStatus.Code() == codes.Unavailable {
-			gc.logger.Warn(ErrServerUnavailable)
+			err = ErrServerUnavailable
 		} else {
-			gc.logger.Warn(ErrModify)
+			err = ErrAction
 		}
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
-		if err != nil {
-			gc.logger.Warn(err)
-		}
-		return
+		gc.logger.Warn(err)
+		gc.setClusterFailed(c)
+		return "", err
 	}
-	
-	gc.logger.Infof("From ansible-service: %s", message.Status)
 
-	if message.Status != utils.AnsibleOk {
+	gc.logger.Infof("From ansible-service: %s", taskStatus)
+
+	if taskStatus == utils.AnsibleCancelled {
+		gc.setClusterCancelled(c)
+		return taskStatus, nil
+	}
+
+	if taskStatus != utils.AnsibleOk {
 		// request to db-service about errors with ansible service
-		c.EntityStatus = utils.StatusFailed
-		err = gc.Db.UpdateCluster(c)
+		gc.setClusterFailed(c)
+		return taskStatus, ErrAnsibleStatus(taskStatus)
+	}
+
+	if action.Action == utils.ActionStop {
+		return taskStatus, gc.setClusterStatus(c, utils.StatusStopped)
+	}
+	return taskStatus, gc.setClusterActive(c)
+}
+
+// CancelCluster asks ansible-service to stop the action running for the cluster
+func (gc GrpcClient) CancelCluster(c *protobuf.Cluster) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Second)
+	defer cancel()
+
+	gc.logger.Infof("Sending cancel request for %s cluster to ansible-service", c.Name)
+	res, err := gc.ansibleServiceClient.Cancel(ctx, c)
+	if err != nil {
+		errStatus, _ := status.FromError(err)
+		if errStatus.Code() == codes.Unavailable {
+			err = ErrServerUnavailable
+		} else {
+			err = ErrCancel
+		}
+		gc.logger.Warn(err)
+		return "", err
+	}
+
+	gc.logger.Infof("From ansible-service: %s", res.Status)
+	return res.Status, nil
+}
+
+// PlanCluster asks ansible-service for extra vars which the action would pass to the playbooks
+func (gc GrpcClient) PlanCluster(c *protobuf.Cluster, action string) (*protobuf.ClusterPlan, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Second)
+	defer cancel()
+
+	gc.logger.Infof("Sending %s plan request for %s cluster to ansible-service", action, c.Name)
+	res, err := gc.ansibleServiceClient.Plan(ctx, &protobuf.PlanRequest{Cluster: c, Action: action})
+	if err != nil {
+		errStatus, _ := status.FromError(err)
+		if errStatus.Code() == codes.Unavailable {
+			err = ErrServerUnavailable
+		} else {
+			err = ErrPlan
+		}
+		gc.logger.Warn(err)
+		return nil, err
+	}
+	return res, nil
+}
+
+// InspectCluster asks ansible-service to compare the cluster with its instances and services
+func (gc GrpcClient) InspectCluster(c *protobuf.Cluster) (*protobuf.ClusterDrift, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), WAITING_TIME*time.Second)
+	defer cancel()
+
+	gc.logger.Infof("Sending inspect request for %s cluster to ansible-service", c.Name)
+	res, err := gc.ansibleServiceClient.Inspect(ctx, c)
+	if err != nil {
+		errStatus, _ := status.FromError(err)
+		if errStatus.Code() == codes.Unavailable {
+			err = ErrServerUnavailable
+		} else {
+			err = ErrInspect
+		}
+		gc.logger.Warn(err)
+		return nil, err
+	}
+	return res, nil
+}
+
+// receiveProgress passes progress events from the stream to the handler
+// and returns task status from the finish event
+func (gc GrpcClient) receiveProgress(stream progressStream, progress ProgressHandler) (string, error) {
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			return "", ErrStreamUnfinished
+		}
 		if err != nil {
-			gc.logger.Warn(err)
+			return "", err
+		}
+		if event.Type == utils.ProgressEventFinish {
+			return event.Status, nil
+		}
+		if progress != nil {
+			progress(event)
 		}
-		return
 	}
+}
+
+// setClusterFailed marks cluster as failed in db keeping data saved by ansible-service, such as statuses of the services
+func (gc GrpcClient) setClusterFailed(c *protobuf.Cluster) {
+	newC, err := gc.Db.ReadCluster(c.ProjectID, c.ID)
+	if err != nil {
+		gc.logger.Warn(err)
+		newC = c
+	}
+
+	newC.EntityStatus = utils.StatusFailed
+	err = gc.Db.UpdateCluster(newC)
+	if err != nil {
+		gc.logger.Warn(err)
+	}
+}
 
+// setClusterCancelled marks cluster as cancelled in db keeping data saved by ansible-service
+func (gc GrpcClient) setClusterCancelled(c *protobuf.Cluster) {
 	newC, err := gc.Db.ReadCluster(c.ProjectID, c.ID)
 	if err != nil {
 		gc.logger.Warn(err)
+		newC = c
 	}
-	if newC.Name == "" {
-		gc.logger.Warn("Cluster with ID %v not found", c.ID)
+
+	gc.logger.Infof("Sending to db-service cancelled status for %s cluster", c.Name)
+	newC.EntityStatus = utils.StatusCancelled
+	err = gc.Db.UpdateCluster(newC)
+	if err != nil {
+		gc.logger.Warn(err)
+	}
+}
+
+// setClusterActive reads cluster saved by ansible-service and marks it as active
+func (gc GrpcClient) setClusterActive(c *protobuf.Cluster) error {
+	return gc.setClusterStatus(c, utils.StatusActive)
+}
+
+// setClusterStatus reads cluster saved by ansible-service and sets its new status
+func (gc GrpcClient) setClusterStatus(c *protobuf.Cluster, entityStatus string) error {
+	newC, err := gc.Db.ReadCluster(c.ProjectID, c.ID)
+	if err != nil {
+		gc.logger.Warn(err)
+		return err
 	}
 
 	gc.logger.Infof("Sending to db-service new status for %s cluster", c.Name)
-	newC.EntityStatus = utils.StatusActive
+	newC.EntityStatus = entityStatus
 	err = gc.Db.UpdateCluster(newC)
 	if err != nil {
 		gc.logger.Warn(err)
+		return err
 	}
+	return nil
 }
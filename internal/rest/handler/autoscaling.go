@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"encoding/json"
+	proto "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// AutoscalingPolicyGet processes a request to get the autoscaling policy of the cluster
+func (hS HttpServer) AutoscalingPolicyGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/autoscaling"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	policy, err := hS.Db.ReadAutoscalingPolicy(cluster.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, policy, request)
+}
+
+// AutoscalingPolicySet processes a request to create or replace the autoscaling policy of the cluster
+func (hS HttpServer) AutoscalingPolicySet(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "PUT /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/autoscaling"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var policy proto.AutoscalingPolicy
+	err = json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating autoscaling policy...")
+	err = validate.AutoscalingPolicy(hS.Db, cluster, &policy)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	policy.ClusterID = cluster.ID
+	policy.ProjectID = project.ID
+	if policy.ScaleStep == 0 {
+		policy.ScaleStep = 1
+	}
+
+	// cooldown of the replaced policy is kept
+	oldPolicy, err := hS.Db.ReadAutoscalingPolicy(cluster.ID)
+	if err == nil {
+		policy.LastScaledAt = oldPolicy.LastScaledAt
+	}
+
+	err = hS.Db.WriteAutoscalingPolicy(&policy)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, &policy, request)
+}
+
+// AutoscalingPolicyDelete processes a request to delete the autoscaling policy of the cluster
+func (hS HttpServer) AutoscalingPolicyDelete(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "DELETE /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/autoscaling"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	policy, err := hS.Db.ReadAutoscalingPolicy(cluster.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = hS.Db.DeleteAutoscalingPolicy(policy.ClusterID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, policy, request)
+}
+
+// ScalingDecisionsGetList processes a request to get a list of scaling decisions made for the cluster
+func (hS HttpServer) ScalingDecisionsGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/autoscaling/decisions"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	decisions, err := hS.Db.ReadClusterScalingDecisions(cluster.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, decisions, request)
+}
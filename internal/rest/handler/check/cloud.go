@@ -0,0 +1,28 @@
+package check
+
+import (
+	"github.com/ispras/michman/internal/database"
+)
+
+// CloudUsed checks whether the cloud is used in any of the clusters or projects
+func CloudUsed(db database.Database, name string) (bool, error) {
+	clusters, err := db.ReadClustersList()
+	if err != nil {
+		return false, err
+	}
+	for i := range clusters {
+		if clusters[i].Cloud == name {
+			return true, nil
+		}
+	}
+	projects, err := db.ReadProjectsList()
+	if err != nil {
+		return false, err
+	}
+	for i := range projects {
+		if projects[i].DefaultCloud == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -31,7 +31,7 @@ func ClusterExist(db database.Database, clusterRes *protobuf.Cluster, project *p
 	}
 	cluster, err := db.ReadCluster(project.ID, searchName)
 	if cluster != nil {
-		if cluster.EntityStatus != utils.StatusFailed {
+		if cluster.EntityStatus != utils.StatusFailed && cluster.EntityStatus != utils.StatusCancelled {
 			return true, nil, ErrObjectExists("cluster", searchName)
 		}
 		return true, cluster, nil
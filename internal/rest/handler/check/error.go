@@ -137,3 +137,53 @@ func ErrClusterServiceConfigNotSupported(param string, service string) error {
 	errMessage := fmt.Sprintf("'%s' service config param name '%s' is not supported", service, param)
 	return rest.MakeError(errMessage, utils.ValidationError)
 }
+
+func ErrServiceTypeActionParamEmpty(param string) error {
+	errMessage := fmt.Sprintf("service type action param '%s' can't be empty", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceTypeActionUnique(param string) error {
+	errMessage := fmt.Sprintf("service type action %s is not unique", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceTypeActionPlaybook(param string) error {
+	errMessage := fmt.Sprintf("service type action playbook '%s' must be a name of .yml file in ansible/actions directory", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceTypeSupportedAction(param string) error {
+	errMessage := fmt.Sprintf("service type could not support cluster action %s, only restart-service and remove-service are supported", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceTypeSupportedActionUnique(param string) error {
+	errMessage := fmt.Sprintf("service type supported action %s is not unique", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceTypeActionStatus(param string) error {
+	errMessage := fmt.Sprintf("service type action could not be allowed in cluster status %s, only ACTIVE and FAILED are supported", param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceActionParamIncorrectType(param string, action string) error {
+	errMessage := fmt.Sprintf("'%s' action param '%s' has incorrect value type", action, param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceActionParamNotPossibleValue(param string, action string) error {
+	errMessage := fmt.Sprintf("'%s' action param '%s' value is not supported", action, param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceActionParamNotSupported(param string, action string) error {
+	errMessage := fmt.Sprintf("'%s' action param name '%s' is not supported", action, param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceActionParamRequired(param string, action string) error {
+	errMessage := fmt.Sprintf("'%s' action param '%s' is required", action, param)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
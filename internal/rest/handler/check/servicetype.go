@@ -4,6 +4,7 @@ import (
 	"github.com/ispras/michman/internal/database"
 	"github.com/ispras/michman/internal/protobuf"
 	"github.com/ispras/michman/internal/utils"
+	"path/filepath"
 )
 
 // ServiceTypeClass checks that service type class belongs to one of the classes:
@@ -169,7 +170,8 @@ func ServiceTypeVersions(db database.Database, sTypeVersions []*protobuf.Service
 
 // ServiceTypeVersionDependencyNotExists checks that service type version not present in all service types dependencies
 func ServiceTypeVersionDependencyNotExists(serviceTypes []protobuf.ServiceType, serviceType *protobuf.ServiceType, serviceTypeVersion *protobuf.ServiceVersion) error {
-	for _, curServiceType := range serviceTypes {
+	for i := range serviceTypes {
+		curServiceType := &serviceTypes[i]
 		for _, serviceVersion := range curServiceType.Versions {
 			for _, serviceVersionDependency := range serviceVersion.Dependencies {
 				if serviceVersionDependency.ServiceType == serviceType.Type {
@@ -187,7 +189,8 @@ func ServiceTypeVersionDependencyNotExists(serviceTypes []protobuf.ServiceType,
 
 // ServiceTypeDependencyNotExists checks that service type not present in all versions and their dependencies
 func ServiceTypeDependencyNotExists(serviceType string, serviceTypes []protobuf.ServiceType) error {
-	for _, curServiceType := range serviceTypes {
+	for i := range serviceTypes {
+		curServiceType := &serviceTypes[i]
 		for _, serviceVersion := range curServiceType.Versions {
 			for _, serviceVersionDependency := range serviceVersion.Dependencies {
 				if serviceVersionDependency.ServiceType == serviceType {
@@ -283,3 +286,103 @@ func ServiceTypeVersionDependencies(db database.Database, serviceDependencies []
 	}
 	return nil
 }
+
+// ServiceTypeActionStatus checks that the cluster status allows to run service type actions:
+// services could be operated only on running instances
+func ServiceTypeActionStatus(status string) error {
+	if status == utils.StatusActive || status == utils.StatusFailed {
+		return nil
+	}
+	return ErrServiceTypeActionStatus(status)
+}
+
+// ServiceTypeAction checks that service type action is unique by name, its playbook is a file name
+// from the actions directory and its parameters follow the rules of service configs
+func ServiceTypeAction(action *protobuf.ServiceAction, actions []*protobuf.ServiceAction) error {
+	if action.Name == "" {
+		return ErrServiceTypeActionParamEmpty("Name")
+	}
+	for _, curAction := range actions {
+		if curAction.Name == action.Name {
+			return ErrServiceTypeActionUnique(action.Name)
+		}
+	}
+
+	if action.Playbook == "" {
+		return ErrServiceTypeActionParamEmpty("Playbook")
+	}
+	if filepath.Base(action.Playbook) != action.Playbook || filepath.Ext(action.Playbook) != ".yml" {
+		return ErrServiceTypeActionPlaybook(action.Playbook)
+	}
+
+	for _, status := range action.AllowedStatuses {
+		err := ServiceTypeActionStatus(status)
+		if err != nil {
+			return err
+		}
+	}
+
+	return ServiceTypeVersionConfigs(action.Parameters)
+}
+
+// ServiceTypeActions checks all actions
+func ServiceTypeActions(actions []*protobuf.ServiceAction) error {
+	for i, curAction := range actions {
+		err := ServiceTypeAction(curAction, actions[i+1:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceTypeSupportedActions checks that cluster actions supported by the service type are known and not repeated
+func ServiceTypeSupportedActions(actions []string) error {
+	for i, action := range actions {
+		if action != utils.ActionRestartService && action != utils.ActionRemoveService {
+			return ErrServiceTypeSupportedAction(action)
+		}
+		for _, curAction := range actions[i+1:] {
+			if curAction == action {
+				return ErrServiceTypeSupportedActionUnique(action)
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceActionParams checks that parameters of the service type action run are declared by the action,
+// have values of the declared types and required parameters without default values are set
+func ServiceActionParams(params map[string]string, action *protobuf.ServiceAction) error {
+	for paramName, paramValue := range params {
+		flagPN := false
+		for _, param := range action.Parameters {
+			if paramName == param.ParameterName {
+				flagPN = true
+
+				if err := CorrectType(paramValue, param.Type, param.IsList); err != nil {
+					return ErrServiceActionParamIncorrectType(paramName, action.Name)
+				}
+
+				//check for possible values
+				if param.PossibleValues != nil {
+					if !ValuesAllowed(paramValue, param.PossibleValues, param.IsList) {
+						return ErrServiceActionParamNotPossibleValue(paramName, action.Name)
+					}
+				}
+
+				break
+			}
+		}
+		if !flagPN {
+			return ErrServiceActionParamNotSupported(paramName, action.Name)
+		}
+	}
+
+	for _, param := range action.Parameters {
+		if _, ok := params[param.ParameterName]; !ok && param.Required && param.DefaultValue == "" {
+			return ErrServiceActionParamRequired(param.ParameterName, action.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/rest/response"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// CloudsGetList processes a request to get a list of all clouds in database
+func (hS HttpServer) CloudsGetList(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	request := "GET /clouds"
+	hS.Logger.Info(request)
+
+	clouds, err := hS.Db.ReadCloudsList()
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, clouds, request)
+}
+
+// CloudGet processes a request to get a cloud struct by id or name from database
+func (hS HttpServer) CloudGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	cloudIdOrName := params.ByName("cloudIdOrName")
+	request := "GET /clouds/" + cloudIdOrName
+	hS.Logger.Info(request)
+
+	cloud, err := hS.Db.ReadCloud(cloudIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cloud, request)
+}
+
+// CloudCreate processes a request to create a cloud struct in database
+func (hS HttpServer) CloudCreate(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	request := "POST /clouds"
+	hS.Logger.Info(request)
+
+	var cloud protobuf.Cloud
+	err := json.NewDecoder(r.Body).Decode(&cloud)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	_, err = hS.Db.ReadCloud(cloud.Name)
+	if err == nil {
+		err = ErrObjectExists("cloud", cloud.Name)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	} else if response.ErrorClass(err) != utils.ObjectNotFound {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating cloud...")
+	err = validate.CloudCreate(hS.Db, &cloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// generating UUID for new cloud
+	cUuid, err := uuid.NewRandom()
+	if err != nil {
+		err = ErrUuidLibError
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	cloud.ID = cUuid.String()
+
+	err = hS.Db.WriteCloud(&cloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusCreated)
+	response.Created(w, &cloud, request)
+}
+
+// CloudUpdate processes a request to update a cloud struct in database
+func (hS HttpServer) CloudUpdate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	cloudIdOrName := params.ByName("cloudIdOrName")
+	request := "PUT /clouds/" + cloudIdOrName
+	hS.Logger.Info(request)
+
+	oldCloud, err := hS.Db.ReadCloud(cloudIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var newCloud protobuf.Cloud
+	err = json.NewDecoder(r.Body).Decode(&newCloud)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating updated values of the cloud fields...")
+	err = validate.CloudUpdate(hS.Db, oldCloud, &newCloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	resCloud := oldCloud
+	if newCloud.Name != "" {
+		resCloud.Name = newCloud.Name
+	}
+	if newCloud.Type != "" {
+		resCloud.Type = newCloud.Type
+	}
+	if newCloud.Description != "" {
+		resCloud.Description = newCloud.Description
+	}
+	if newCloud.VaultKey != "" {
+		resCloud.VaultKey = newCloud.VaultKey
+	}
+	if newCloud.OsVersion != "" {
+		resCloud.OsVersion = newCloud.OsVersion
+	}
+	if newCloud.VirtualNetwork != "" {
+		resCloud.VirtualNetwork = newCloud.VirtualNetwork
+	}
+	if newCloud.FloatingIP != "" {
+		resCloud.FloatingIP = newCloud.FloatingIP
+	}
+	if newCloud.KeyName != "" {
+		resCloud.KeyName = newCloud.KeyName
+	}
+
+	err = validate.CloudResult(resCloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = hS.Db.UpdateCloud(resCloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, resCloud, request)
+}
+
+// CloudDelete processes a request to delete a cloud struct from database
+func (hS HttpServer) CloudDelete(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	cloudIdOrName := params.ByName("cloudIdOrName")
+	request := "DELETE /clouds/" + cloudIdOrName
+	hS.Logger.Info(request)
+
+	cloud, err := hS.Db.ReadCloud(cloudIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = validate.CloudDelete(hS.Db, cloud)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = hS.Db.DeleteCloud(cloud.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusNoContent)
+	response.NoContent(w)
+}
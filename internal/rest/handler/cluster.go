@@ -9,7 +9,10 @@ import (
 	response "github.com/ispras/michman/internal/rest/response"
 	"github.com/ispras/michman/internal/utils"
 	"github.com/julienschmidt/httprouter"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 // ClustersGetList processes a request to get a list of all clusters in database
@@ -51,6 +54,14 @@ func (hS HttpServer) ClusterCreate(w http.ResponseWriter, r *http.Request, param
 		return
 	}
 
+	// dry run validates the cluster and returns its plan without creating it
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
 	var resCluster *proto.Cluster
 	err = json.NewDecoder(r.Body).Decode(&resCluster)
 	if err != nil {
@@ -60,11 +71,23 @@ func (hS HttpServer) ClusterCreate(w http.ResponseWriter, r *http.Request, param
 		return
 	}
 
+	hS.createCluster(w, r, request, project, resCluster, dryRun)
+}
+
+// createCluster validates the new cluster, saves it and starts its deployment,
+// cluster failed before is deployed again
+func (hS HttpServer) createCluster(w http.ResponseWriter, r *http.Request, request string, project *proto.Project, resCluster *proto.Cluster, dryRun bool) {
 	// set fields by defaults if not specified by user
 	helpfunc.SetClusterDefaults(resCluster, project)
 
 	hS.Logger.Infof("Validating cluster %s general info...", resCluster.Name)
-	err = validate.ClusterCreateGeneral(hS.Db, resCluster)
+	err := validate.ClusterCreateGeneral(hS.Db, resCluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	err = validate.ClusterLease(project, resCluster.TTL)
 	if err != nil {
 		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
 		response.Error(w, err)
@@ -79,6 +102,7 @@ func (hS HttpServer) ClusterCreate(w http.ResponseWriter, r *http.Request, param
 		return
 	}
 	// If cluster was failed
+	var added []*proto.Service
 	if clusterExists {
 		resCluster = oldCluster
 	} else {
@@ -91,13 +115,17 @@ func (hS HttpServer) ClusterCreate(w http.ResponseWriter, r *http.Request, param
 		}
 		// Set OwnerID from the request
 		resCluster.OwnerID = helpfunc.GetClusterOwnerId(r)
+		// Lease of the cluster starts from its creation
+		helpfunc.SetClusterLease(resCluster, resCluster.TTL)
 
 		// add services from user request and from dependencies
+		requested := resCluster.Services
 		if err := helpfunc.SetServices(hS.Db, resCluster); err != nil {
 			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
 			response.Error(w, err)
 			return
 		}
+		added = addedServices(resCluster.Services, requested)
 		// cluster should be validated after addition services from dependencies
 		err = validate.ClusterServices(hS.Db, resCluster)
 		if err != nil {
@@ -114,17 +142,39 @@ func (hS HttpServer) ClusterCreate(w http.ResponseWriter, r *http.Request, param
 		response.Error(w, err)
 		return
 	}
+
+	hS.Logger.Info("Checking project quotas...")
+	err = validate.ClusterQuotas(hS.Db, project, resCluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	if dryRun {
+		hS.clusterPlan(w, request, resCluster, utils.ActionCreate, added)
+		return
+	}
 	resCluster.EntityStatus = utils.StatusInited
 
 	if !clusterExists {
 		err = hS.Db.WriteCluster(resCluster)
-		if err != nil {
-			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
-			response.Error(w, err)
-			return
-		}
+	} else {
+		err = hS.Db.UpdateCluster(resCluster)
+	}
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	op, err := hS.Queue.Enqueue(resCluster, utils.ActionCreate, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
 	}
-	go hS.Gc.StartClusterCreation(resCluster)
+	w.Header().Set(utils.OperationIdHeader, op.ID)
 
 	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusCreated)
 	response.Created(w, resCluster, request)
@@ -184,6 +234,131 @@ func (hS HttpServer) ClusterStatusGet(w http.ResponseWriter, _ *http.Request, pa
 	response.Ok(w, cluster.EntityStatus, request)
 }
 
+// ClusterNodesGetList processes a request to get a list of the cluster nodes with their roles, IPs and statuses
+func (hS HttpServer) ClusterNodesGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/nodes"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	nodes := cluster.Nodes
+	if nodes == nil {
+		nodes = []*proto.Node{}
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, nodes, request)
+}
+
+// clusterServiceInfo returns the cluster service with its config resolved by the deployed service version
+func (hS HttpServer) clusterServiceInfo(service *proto.Service) (*proto.ServiceInfo, error) {
+	version, err := hS.Db.ReadServiceTypeVersion(service.Type, service.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.ServiceInfo{
+		Service:        service,
+		ResolvedConfig: helpfunc.ResolveServiceConfig(service.Config, version.Configs),
+	}, nil
+}
+
+// ClusterServicesGetList processes a request to get the list of the cluster services with their statuses
+func (hS HttpServer) ClusterServicesGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	services := []*proto.ServiceInfo{}
+	for _, service := range cluster.Services {
+		info, err := hS.clusterServiceInfo(service)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+		services = append(services, info)
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, services, request)
+}
+
+// ClusterServiceGet processes a request to get the cluster service by its ID, name or type
+func (hS HttpServer) ClusterServiceGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	serviceIdOrName := params.ByName("serviceIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services/" + serviceIdOrName
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	service := helpfunc.FindClusterService(cluster, serviceIdOrName)
+	if service == nil {
+		err = ErrClusterServiceNotFound(serviceIdOrName, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	info, err := hS.clusterServiceInfo(service)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, info, request)
+}
+
 // ClustersUpdate processes a request to update a cluster struct in database
 func (hS HttpServer) ClustersUpdate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	projectIdOrName := params.ByName("projectIdOrName")
@@ -207,6 +382,14 @@ func (hS HttpServer) ClustersUpdate(w http.ResponseWriter, r *http.Request, para
 		return
 	}
 
+	// dry run validates the cluster update and returns its plan without changing the cluster
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
 	var newCluster proto.Cluster
 	err = json.NewDecoder(r.Body).Decode(&newCluster)
 	if err != nil {
@@ -262,6 +445,16 @@ func (hS HttpServer) ClustersUpdate(w http.ResponseWriter, r *http.Request, para
 		return
 	}
 
+	// new services may add cluster instances
+	if oldServiceNumber != len(resCluster.Services) {
+		err = validate.ClusterQuotas(hS.Db, project, resCluster)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+	}
+
 	if newCluster.Description != "" {
 		resCluster.Description = newCluster.Description
 	}
@@ -276,22 +469,43 @@ func (hS HttpServer) ClustersUpdate(w http.ResponseWriter, r *http.Request, para
 		}
 	}
 
-	resCluster.EntityStatus = utils.StatusInited
+	action := utils.ActionUpdate
 	if newCluster.NSlaves != 0 || newHost {
-		go hS.Gc.StartClusterCreation(resCluster)
-	} else {
-		go hS.Gc.StartClusterModification(resCluster)
+		action = utils.ActionCreate
+	}
+
+	if dryRun {
+		added := addedServices(resCluster.Services[oldServiceNumber:], newCluster.Services)
+		hS.clusterPlan(w, request, resCluster, action, added)
+		return
+	}
+
+	resCluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(resCluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	op, err := hS.Queue.Enqueue(resCluster, action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
 	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
 
 	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
 	response.Ok(w, resCluster, request)
 }
 
-// ClustersDelete processes a request to delete a cluster struct from database
-func (hS HttpServer) ClustersDelete(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+// ClusterSpecApply processes a request to converge the cluster to the desired spec: cluster which doesn't exist
+// is created, otherwise operation adding services, changing their configs or scaling the cluster is started
+func (hS HttpServer) ClusterSpecApply(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	projectIdOrName := params.ByName("projectIdOrName")
 	clusterIdOrName := params.ByName("clusterIdOrName")
-	request := "DELETE /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName
+	request := "PUT /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/spec"
 	hS.Logger.Info(request)
 
 	// reading project info from database
@@ -302,25 +516,751 @@ func (hS HttpServer) ClustersDelete(w http.ResponseWriter, _ *http.Request, para
 		return
 	}
 
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var spec proto.Cluster
+	err = json.NewDecoder(r.Body).Decode(&spec)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
 	// reading cluster info from database
 	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		if response.ErrorClass(err) != utils.ObjectNotFound {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+
+		// new cluster gets the name from the request path
+		if spec.DisplayName == "" {
+			spec.DisplayName = strings.TrimSuffix(clusterIdOrName, "-"+project.Name)
+		}
+		if (spec.Name != "" && spec.Name != clusterIdOrName) || spec.DisplayName+"-"+project.Name != clusterIdOrName {
+			err = ErrClusterSpecName(clusterIdOrName, project.Name)
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+		spec.Name = ""
+		hS.createCluster(w, r, request, project, &spec, dryRun)
+		return
+	}
+
+	hS.Logger.Info("Validating cluster spec...")
+	err = validate.ClusterSpec(hS.Db, cluster, &spec)
 	if err != nil {
 		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
 		response.Error(w, err)
 		return
 	}
 
-	err = validate.ClusterDelete(cluster)
+	oldServiceNumber := len(cluster.Services)
+	action, changed, err := helpfunc.ApplyClusterSpec(hS.Db, cluster, &spec)
 	if err != nil {
 		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
 		response.Error(w, err)
 		return
 	}
 
-	cluster.EntityStatus = utils.StatusStopping
+	if action == utils.ActionCreate || action == utils.ActionUpdate {
+		// cluster should be validated after addition services from dependencies
+		err = validate.ClusterCreateServices(hS.Db, cluster)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+		err = validate.ClusterQuotas(hS.Db, project, cluster)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+	}
+
+	if dryRun {
+		if action == "" {
+			action = utils.ActionUpdate
+		}
+		added := addedServices(cluster.Services[oldServiceNumber:], spec.Services)
+		hS.clusterPlan(w, request, cluster, action, added)
+		return
+	}
+
+	// cluster already matches the spec
+	if !changed {
+		hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+		response.Ok(w, cluster, request)
+		return
+	}
+
+	if action != "" {
+		cluster.EntityStatus = utils.StatusInited
+	}
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
 
-	go hS.Gc.StartClusterDestroying(cluster)
+	if action != "" {
+		op, err := hS.Queue.Enqueue(cluster, action, helpfunc.GetClusterOwnerId(r))
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+		w.Header().Set(utils.OperationIdHeader, op.ID)
+	}
 
 	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
 	response.Ok(w, cluster, request)
 }
+
+// ClustersDelete processes a request to delete a cluster struct from database
+func (hS HttpServer) ClustersDelete(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "DELETE /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = validate.ClusterDelete(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	cluster.EntityStatus = utils.StatusStopping
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// autoscaling policy of the deleted cluster is not evaluated anymore
+	if _, err = hS.Db.ReadAutoscalingPolicy(cluster.ID); err == nil {
+		err = hS.Db.DeleteAutoscalingPolicy(cluster.ID)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+	}
+
+	op, err := hS.Queue.Enqueue(cluster, utils.ActionDelete, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterLeaseExtend processes a request to extend the cluster lease, so the cluster expires in TTL hours from now
+func (hS HttpServer) ClusterLeaseExtend(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/lease"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var lease proto.LeaseRequest
+	err = json.NewDecoder(r.Body).Decode(&lease)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating cluster lease...")
+	err = validate.ClusterLeaseExtend(project, cluster, &lease)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// owner is warned again before the new expiry
+	helpfunc.SetClusterLease(cluster, lease.TTL)
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterScale processes a request to remove slave nodes of the cluster without redeploying it
+func (hS HttpServer) ClusterScale(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/scale"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var scale proto.ScaleRequest
+	err = json.NewDecoder(r.Body).Decode(&scale)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating scale request...")
+	err = validate.ClusterScale(hS.Db, cluster, &scale)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// nodes marked for removal are saved, so the scale operation could be resumed
+	helpfunc.MarkRemovedNodes(cluster, &scale)
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	op, err := hS.Queue.Enqueue(cluster, utils.ActionScale, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterAction processes a request to run the lifecycle action of the cluster: stop or start its instances,
+// restart the service, reboot the node or repair the drifted cluster
+func (hS HttpServer) ClusterAction(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/actions"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var action proto.ClusterAction
+	err = json.NewDecoder(r.Body).Decode(&action)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating cluster action...")
+	err = validate.ClusterAction(hS.Db, cluster, &action)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	op, err := hS.Queue.EnqueueAction(cluster, &action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterServiceAction processes a request to run the action declared by the service type for the cluster service,
+// request body contains values of the action parameters and may be empty
+func (hS HttpServer) ClusterServiceAction(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	serviceIdOrName := params.ByName("serviceIdOrName")
+	actionName := params.ByName("actionName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services/" + serviceIdOrName +
+		"/actions/" + actionName
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	service := helpfunc.FindClusterService(cluster, serviceIdOrName)
+	if service == nil {
+		err = ErrClusterServiceNotFound(serviceIdOrName, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading service type with its actions from database
+	sType, err := hS.Db.ReadServiceType(service.Type)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	action := proto.ClusterAction{
+		Action:        utils.ActionServiceAction,
+		Service:       sType.Type,
+		ServiceAction: actionName,
+	}
+	err = json.NewDecoder(r.Body).Decode(&action.Params)
+	if err != nil && err != io.EOF {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating service action...")
+	err = validate.ServiceAction(cluster, sType, &action)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	op, err := hS.Queue.EnqueueAction(cluster, &action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterServiceDelete processes a request to uninstall the service and remove it from the cluster services
+func (hS HttpServer) ClusterServiceDelete(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	serviceIdOrName := params.ByName("serviceIdOrName")
+	request := "DELETE /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services/" + serviceIdOrName
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	service := helpfunc.FindClusterService(cluster, serviceIdOrName)
+	if service == nil {
+		err = ErrClusterServiceNotFound(serviceIdOrName, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating service removal...")
+	err = validate.ClusterServiceRemove(hS.Db, cluster, service.Type)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// service is removed from the cluster services by the launcher after its uninstallation
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	action := proto.ClusterAction{Action: utils.ActionRemoveService, Service: service.Type}
+	op, err := hS.Queue.EnqueueAction(cluster, &action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, cluster, request)
+}
+
+// ClusterServiceUpgrade processes a request to upgrade the cluster service to the newer version of its service type,
+// config parameters which don't exist in the new version are removed from the service config
+func (hS HttpServer) ClusterServiceUpgrade(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	serviceIdOrName := params.ByName("serviceIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services/" + serviceIdOrName + "/upgrade"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	service := helpfunc.FindClusterService(cluster, serviceIdOrName)
+	if service == nil {
+		err = ErrClusterServiceNotFound(serviceIdOrName, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading service type with its versions from database
+	sType, err := hS.Db.ReadServiceType(service.Type)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var upgradeRequest proto.ServiceUpgradeRequest
+	err = json.NewDecoder(r.Body).Decode(&upgradeRequest)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating service upgrade...")
+	err = validate.ServiceUpgrade(hS.Db, cluster, sType, upgradeRequest.Version)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var version *proto.ServiceVersion
+	for _, sv := range sType.Versions {
+		if sv.Version == upgradeRequest.Version {
+			version = sv
+			break
+		}
+	}
+
+	// carried over config must be correct for the new version
+	config, removed := helpfunc.UpgradeServiceConfig(service.Config, version)
+	err = check.ServiceConfigCorrectValue(&proto.Service{Type: service.Type, Config: config}, version.Configs)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// service is saved with the new version by the launcher after the upgrade
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	action := proto.ClusterAction{
+		Action:         utils.ActionUpgradeService,
+		Service:        sType.Type,
+		ServiceVersion: version.Version,
+		Params:         config,
+	}
+	op, err := hS.Queue.EnqueueAction(cluster, &action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	upgrade := proto.ServiceUpgrade{
+		Cluster:        cluster,
+		Service:        sType.Type,
+		FromVersion:    service.Version,
+		ToVersion:      version.Version,
+		RemovedConfigs: removed,
+	}
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, &upgrade, request)
+}
+
+// ClusterCancel processes a request to cancel the running operation of the cluster
+func (hS HttpServer) ClusterCancel(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/cancel"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// instances left by the cancelled operation are deleted if cleanup is requested
+	cleanup := false
+	if value := r.URL.Query().Get(QueryCleanupKey); value != "" {
+		cleanup, err = strconv.ParseBool(value)
+		if err != nil {
+			err = ErrClusterBadCleanupParam
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
+		}
+	}
+
+	op, err := hS.Queue.Cancel(cluster, cleanup)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, op, request)
+}
+
+// ClusterServiceConfigUpdate processes a request to change config parameters of the running cluster service,
+// only the service role is run again and the service is restarted if any changed parameter requires it
+func (hS HttpServer) ClusterServiceConfigUpdate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	serviceIdOrName := params.ByName("serviceIdOrName")
+	request := "PATCH /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/services/" + serviceIdOrName + "/config"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	service := helpfunc.FindClusterService(cluster, serviceIdOrName)
+	if service == nil {
+		err = ErrClusterServiceNotFound(serviceIdOrName, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var changes map[string]string
+	err = json.NewDecoder(r.Body).Decode(&changes)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading deployed service version with its configs from database
+	version, err := hS.Db.ReadServiceTypeVersion(service.Type, service.Version)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating service config...")
+	config := helpfunc.ReconfigureServiceConfig(service.Config, changes)
+	err = validate.ServiceReconfigure(cluster, service, config, version)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	change := proto.ServiceConfigChange{
+		Cluster: cluster,
+		Service: service.Type,
+		Changed: helpfunc.ChangedConfigs(service.Config, config),
+	}
+	if len(change.Changed) == 0 {
+		hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+		response.Ok(w, &change, request)
+		return
+	}
+	change.Restart = helpfunc.ConfigsRequireRestart(change.Changed, version.Configs)
+
+	// service is saved with the new config by the launcher after the reconfiguration
+	cluster.EntityStatus = utils.StatusInited
+	err = hS.Db.UpdateCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	action := proto.ClusterAction{
+		Action:         utils.ActionReconfigureService,
+		Service:        service.Type,
+		ServiceVersion: service.Version,
+		Params:         config,
+		Restart:        change.Restart,
+	}
+	op, err := hS.Queue.EnqueueAction(cluster, &action, helpfunc.GetClusterOwnerId(r))
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	w.Header().Set(utils.OperationIdHeader, op.ID)
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, &change, request)
+}
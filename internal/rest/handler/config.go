@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/ispras/michman/internal/protobuf"
 	"github.com/ispras/michman/internal/rest/handler/check"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
 	"github.com/ispras/michman/internal/rest/handler/validate"
 	response "github.com/ispras/michman/internal/rest/response"
 	"github.com/ispras/michman/internal/utils"
@@ -130,6 +131,14 @@ func (hS HttpServer) ConfigsServiceTypeCreate(w http.ResponseWriter, r *http.Req
 		sType.Versions[i].ID = vUuid.String()
 	}
 
+	// generating UUIDs and AnsibleVarName params for service type actions
+	err = helpfunc.SetServiceTypeActionsGeneratedFields(sType.Type, sType.Actions)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
 	// generating UUID for new service type
 	stUuid, err := uuid.NewRandom()
 	if err != nil {
@@ -202,23 +211,24 @@ func (hS HttpServer) ConfigsServiceTypeUpdate(w http.ResponseWriter, r *http.Req
 	}
 
 	if newServiceType.Ports != nil {
-		if oldServiceType.Ports != nil {
-			newPLen := len(newServiceType.Ports)
-			for _, oldP := range oldServiceType.Ports {
-				f := false
-				for _, newP := range newServiceType.Ports[:newPLen] {
-					if oldP.Port == newP.Port {
-						f = true
-						break
-					}
-				}
-				//add old port if it hasn't been updated
-				if !f {
-					newServiceType.Ports = append(newServiceType.Ports, oldP)
-				}
-			}
+		//old ports are kept if they haven't been updated
+		oldServiceType.Ports = helpfunc.MergeSlices(newServiceType.Ports, oldServiceType.Ports, "Port").([]*protobuf.ServicePort)
+	}
+
+	// actions are replaced as a whole
+	if newServiceType.Actions != nil {
+		err = helpfunc.SetServiceTypeActionsGeneratedFields(oldServiceType.Type, newServiceType.Actions)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			response.Error(w, err)
+			return
 		}
-		oldServiceType.Ports = newServiceType.Ports
+		oldServiceType.Actions = newServiceType.Actions
+	}
+
+	// supported cluster actions are replaced as a whole
+	if newServiceType.SupportedActions != nil {
+		oldServiceType.SupportedActions = newServiceType.SupportedActions
 	}
 
 	err = hS.Db.UpdateServiceType(oldServiceType)
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/ispras/michman/internal/rest/reconciler"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// ClusterDriftGet processes a request to get drift of the cluster from its instances and services saved by the last inspection
+func (hS HttpServer) ClusterDriftGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/drift"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, reconciler.Report(cluster, nil), request)
+}
+
+// ClusterDriftCheck processes a request to inspect the active or missing cluster immediately
+func (hS HttpServer) ClusterDriftCheck(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/drift"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	report, err := hS.Reconciler.ReconcileCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, report, request)
+}
@@ -18,9 +18,15 @@ const (
 	//project:
 
 	//cluster:
+	errBadCleanupParam = "bad cleanup param. Supported query variables for cleanup parameter are 'true' and 'false', 'false' is default"
+	errBadDryRunParam  = "bad dry_run param. Supported query variables for dry_run parameter are 'true' and 'false', 'false' is default"
 
 	//log:
-	errBadActionParam = "bad action param. Supported query variables for action parameter are 'create', 'update' and 'delete'. Action 'create' is default"
+	errBadActionParam = "bad action param. Supported query variables for action parameter are 'create', 'update', 'delete', 'scale', 'stop', 'start', 'restart-service', 'reboot-node', 'service-action', 'remove-service', 'upgrade-service', 'reconfigure-service' and 'repair'. Action 'create' is default"
+	errBadOffsetParam = "bad offset param. Offset must be a non-negative integer returned as id of the logs stream event"
+
+	//usage:
+	errCsvEncode = "usage report can't be encoded in CSV format"
 
 	//service type:
 	errGetQueryParams = "bad view param. Supported query variables for view parameter are 'full' and 'summary', 'summary' is default"
@@ -40,11 +46,44 @@ var (
 
 	// project:
 
+	// cluster:
+	ErrClusterBadCleanupParam = rest.MakeError(errBadCleanupParam, utils.InputIncorrect)
+	ErrClusterBadDryRunParam  = rest.MakeError(errBadDryRunParam, utils.InputIncorrect)
+
+	// usage:
+	ErrCsvEncode = rest.MakeError(errCsvEncode, utils.UnexpectedError)
+
 	// log:
 	ErrLogsBadActionParam = rest.MakeError(errBadActionParam, utils.LogsError)
+	ErrLogsBadOffsetParam = rest.MakeError(errBadOffsetParam, utils.LogsError)
 )
 
 func ErrObjectExists(object string, idOrName string) error {
 	errMessage := fmt.Sprintf("%s with this name or id (%s) already exists", object, idOrName)
 	return rest.MakeError(errMessage, utils.ObjectExists)
 }
+
+func ErrClusterOperationNotFound(operationId string, clusterIdOrName string) error {
+	errMessage := fmt.Sprintf("operation %s does not exist for cluster %s", operationId, clusterIdOrName)
+	return rest.MakeError(errMessage, utils.ObjectNotFound)
+}
+
+func ErrProjectOperationNotFound(operationId string, projectIdOrName string) error {
+	errMessage := fmt.Sprintf("operation %s does not exist in project %s", operationId, projectIdOrName)
+	return rest.MakeError(errMessage, utils.ObjectNotFound)
+}
+
+func ErrClusterServiceNotFound(serviceIdOrName string, clusterIdOrName string) error {
+	errMessage := fmt.Sprintf("service %s does not exist in cluster %s", serviceIdOrName, clusterIdOrName)
+	return rest.MakeError(errMessage, utils.ObjectNotFound)
+}
+
+func ErrWebhookNotFound(webhookId string, projectIdOrName string) error {
+	errMessage := fmt.Sprintf("webhook %s does not exist in project %s", webhookId, projectIdOrName)
+	return rest.MakeError(errMessage, utils.ObjectNotFound)
+}
+
+func ErrClusterSpecName(name string, projectName string) error {
+	errMessage := fmt.Sprintf("cluster name %s must be its DisplayName followed by '-%s'", name, projectName)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
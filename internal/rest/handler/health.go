@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"github.com/ispras/michman/internal/rest/health"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// ClusterHealthGet processes a request to get health of the cluster and its services saved by the last health check
+func (hS HttpServer) ClusterHealthGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/health"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, health.Report(cluster), request)
+}
+
+// ClusterHealthCheck processes a request to run health checks of the active cluster services immediately
+func (hS HttpServer) ClusterHealthCheck(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/health"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	report, err := hS.Health.CheckCluster(cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, report, request)
+}
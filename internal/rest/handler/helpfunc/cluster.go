@@ -6,6 +6,7 @@ import (
 	"github.com/ispras/michman/internal/protobuf"
 	"github.com/ispras/michman/internal/utils"
 	"net/http"
+	"time"
 )
 
 type ServiceExists struct {
@@ -41,7 +42,7 @@ func GetServiceVersionIdx(service *protobuf.Service, ServiceTypes []protobuf.Ser
 	return 0, ErrClusterServiceVersionNotSupported(service.Version, service.Type)
 }
 
-// SetClusterServicesUuids set uuids for all cluster services
+// SetClusterServicesUuids set uuids for all cluster services, new services are not deployed yet
 func SetClusterServicesUuids(cluster *protobuf.Cluster) error {
 	for _, service := range cluster.Services {
 		sUuid, err := uuid.NewRandom()
@@ -49,11 +50,12 @@ func SetClusterServicesUuids(cluster *protobuf.Cluster) error {
 			return ErrUuidLibError
 		}
 		service.ID = sUuid.String()
+		service.EntityStatus = utils.StatusInited
 	}
 	return nil
 }
 
-// SetDefaults sets flavors and image cluster fields by default from project if not specified by user
+// SetDefaults sets flavors, image and cloud cluster fields by default from project if not specified by user
 func SetClusterDefaults(cluster *protobuf.Cluster, project *protobuf.Project) {
 	// set default project flavors if not specified
 	if cluster.MasterFlavor == "" {
@@ -73,6 +75,30 @@ func SetClusterDefaults(cluster *protobuf.Cluster, project *protobuf.Project) {
 	if cluster.Image == "" {
 		cluster.Image = project.DefaultImage
 	}
+
+	// set default project cloud if not specified
+	if cluster.Cloud == "" {
+		cluster.Cloud = project.DefaultCloud
+	}
+
+	// set default project cluster lease if not specified,
+	// clusters of the project with the limited lease get the maximal one
+	if cluster.TTL == 0 {
+		cluster.TTL = project.DefaultClusterTTL
+	}
+	if cluster.TTL == 0 {
+		cluster.TTL = project.MaxClusterTTL
+	}
+}
+
+// SetClusterLease sets the cluster lease in hours starting from now, cluster without lease never expires
+func SetClusterLease(cluster *protobuf.Cluster, ttl int32) {
+	cluster.TTL = ttl
+	cluster.ExpiresAt = ""
+	cluster.ExpiryWarnedAt = ""
+	if ttl > 0 {
+		cluster.ExpiresAt = time.Now().UTC().Add(time.Duration(ttl) * time.Hour).Format(time.RFC3339)
+	}
 }
 
 // SetClusterGeneratedFields sets ID, Name, ProjectID in cluster object
@@ -171,6 +197,7 @@ func AppendNewServices(db database.Database, serviceTypesOld map[string]ServiceE
 				return false, ErrUuidLibError
 			}
 			service.ID = sUuid.String()
+			service.EntityStatus = utils.StatusInited
 			resCluster.Services = append(resCluster.Services, service)
 		}
 
@@ -197,6 +224,7 @@ func AppendDependentServices(servicesToAdd []*protobuf.Service, resCluster *prot
 				return false, ErrUuidLibError
 			}
 			curService.ID = sUuid.String()
+			curService.EntityStatus = utils.StatusInited
 
 			resCluster.Services = append(resCluster.Services, curService)
 		}
@@ -6,10 +6,14 @@ import (
 	"github.com/ispras/michman/internal/utils"
 )
 
-const errUuidLibError = "uuid generation error"
+const (
+	errUuidLibError     = "uuid generation error"
+	errClusterSpecScale = "slaves can't be removed together with other changes of the cluster spec, apply them separately"
+)
 
 var (
-	ErrUuidLibError = rest.MakeError(errUuidLibError, utils.LibError)
+	ErrUuidLibError     = rest.MakeError(errUuidLibError, utils.LibError)
+	ErrClusterSpecScale = rest.MakeError(errClusterSpecScale, utils.ValidationError)
 )
 
 func ErrClusterDependenceServicesIncompatibleVersion(service string, currentService string) error {
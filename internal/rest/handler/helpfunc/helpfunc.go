@@ -1,5 +1,7 @@
 package helpfunc
 
+import "reflect"
+
 // MakeLogFilePath makes unified paths for custom and regular paths for the location of directories of files with logs
 func MakeLogFilePath(filename string, LogsFilePath string) string {
 	if LogsFilePath[0] == '/' {
@@ -7,3 +9,25 @@ func MakeLogFilePath(filename string, LogsFilePath string) string {
 	}
 	return "./" + LogsFilePath + "/" + filename
 }
+
+// MergeSlices returns elements of the first slice followed by elements of the second slice with the key field values
+// not found in the first slice. Slices must be of the same type and contain pointers to structures with the key field
+func MergeSlices(first interface{}, second interface{}, key string) interface{} {
+	firstValue := reflect.ValueOf(first)
+	secondValue := reflect.ValueOf(second)
+
+	keys := make(map[interface{}]bool)
+	res := reflect.MakeSlice(firstValue.Type(), 0, firstValue.Len()+secondValue.Len())
+	for i := 0; i < firstValue.Len(); i++ {
+		elem := firstValue.Index(i)
+		keys[elem.Elem().FieldByName(key).Interface()] = true
+		res = reflect.Append(res, elem)
+	}
+	for i := 0; i < secondValue.Len(); i++ {
+		elem := secondValue.Index(i)
+		if !keys[elem.Elem().FieldByName(key).Interface()] {
+			res = reflect.Append(res, elem)
+		}
+	}
+	return res.Interface()
+}
@@ -0,0 +1,50 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ClusterSlaveNodes returns slave nodes of the cluster
+func ClusterSlaveNodes(cluster *protobuf.Cluster) []*protobuf.Node {
+	var slaves []*protobuf.Node
+	for _, node := range cluster.Nodes {
+		if node.Role == utils.NodeRoleSlaves {
+			slaves = append(slaves, node)
+		}
+	}
+	return slaves
+}
+
+// MarkRemovedNodes marks slave nodes for removal by the scale request: nodes listed by name
+// or the last slaves which exceed the target number of slaves
+func MarkRemovedNodes(cluster *protobuf.Cluster, scale *protobuf.ScaleRequest) {
+	slaves := ClusterSlaveNodes(cluster)
+	if len(scale.RemoveNodes) != 0 {
+		for _, node := range slaves {
+			if utils.ItemExists(scale.RemoveNodes, node.Name) {
+				node.Status = utils.NodeStatusRemoving
+			}
+		}
+		return
+	}
+
+	sort.SliceStable(slaves, func(i, j int) bool {
+		return slaveNumber(slaves[i].Name) > slaveNumber(slaves[j].Name)
+	})
+	for i := 0; i < len(slaves)-int(scale.NSlaves); i++ {
+		slaves[i].Status = utils.NodeStatusRemoving
+	}
+}
+
+// slaveNumber returns number of the slave from its name suffix
+func slaveNumber(name string) int {
+	n, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,44 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// GetOperationsClusterId returns ID of the cluster which operations are requested.
+// Operations of the deleted clusters stay available by the cluster ID in the project of the cluster.
+func GetOperationsClusterId(db database.Database, projectId string, clusterIdOrName string) (string, error) {
+	cluster, err := db.ReadCluster(projectId, clusterIdOrName)
+	if err == nil {
+		return cluster.ID, nil
+	}
+	if !utils.IsUuid(clusterIdOrName) {
+		return "", err
+	}
+
+	ops, opsErr := db.ReadClusterOperations(clusterIdOrName)
+	if opsErr != nil {
+		return "", opsErr
+	}
+	for i := range ops {
+		if ops[i].ProjectID == projectId {
+			return clusterIdOrName, nil
+		}
+	}
+	return "", err
+}
+
+// ClusterActionActive checks if the cluster has queued or running operation with the action
+func ClusterActionActive(db database.Database, clusterId string, action string) (bool, error) {
+	ops, err := db.ReadClusterOperations(clusterId)
+	if err != nil {
+		return false, err
+	}
+	for i := range ops {
+		op := &ops[i]
+		if op.Action == action && (op.Status == utils.OperationQueued || op.Status == utils.OperationRunning) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,155 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// ClusterNodesNumber returns the number of the cluster instances of every node role, created by the launcher
+// for the cluster with services of the given classes
+func ClusterNodesNumber(cluster *protobuf.Cluster, classes []string) map[string]int32 {
+	nodes := make(map[string]int32)
+
+	// cluster without services gets master node
+	master := len(classes) == 0
+	for _, class := range classes {
+		if class == utils.ClassStorage {
+			nodes[utils.NodeRoleStorage] = 1
+		} else {
+			master = true
+		}
+	}
+	if master {
+		nodes[utils.NodeRoleMaster] = 1
+		if cluster.NSlaves > 0 {
+			nodes[utils.NodeRoleSlaves] = cluster.NSlaves
+		}
+	}
+	if cluster.Monitoring {
+		nodes[utils.NodeRoleMonitoring] = 1
+	}
+	return nodes
+}
+
+// ClusterNodes returns the number of the cluster instances of every node role
+func ClusterNodes(db database.Database, cluster *protobuf.Cluster) (map[string]int32, error) {
+	var classes []string
+	for _, service := range cluster.Services {
+		st, err := db.ReadServiceType(service.Type)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, st.Class)
+	}
+	return ClusterNodesNumber(cluster, classes), nil
+}
+
+// ClusterFlavorNodes returns the number of the cluster instances of every flavor
+func ClusterFlavorNodes(db database.Database, cluster *protobuf.Cluster) (map[string]int32, error) {
+	roles, err := ClusterNodes(db, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	flavors := map[string]string{
+		utils.NodeRoleMaster:     cluster.MasterFlavor,
+		utils.NodeRoleSlaves:     cluster.SlavesFlavor,
+		utils.NodeRoleStorage:    cluster.StorageFlavor,
+		utils.NodeRoleMonitoring: cluster.MonitoringFlavor,
+	}
+
+	nodes := make(map[string]int32)
+	for role, number := range roles {
+		nodes[flavors[role]] += number
+	}
+	return nodes, nil
+}
+
+// ClusterResources returns resources consumed by the cluster instances according to their flavors
+func ClusterResources(db database.Database, cluster *protobuf.Cluster) (*protobuf.ResourceUsage, error) {
+	nodes, err := ClusterFlavorNodes(db, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &protobuf.ResourceUsage{Clusters: 1}
+	for name, number := range nodes {
+		flavor, err := db.ReadFlavor(name)
+		if err != nil {
+			return nil, err
+		}
+		res.Nodes += number
+		res.VCPUs += number * flavor.VCPUs
+		res.RAM += number * flavor.RAM
+		res.Disk += number * flavor.Disk
+	}
+	return res, nil
+}
+
+// AddResources adds resources consumed by the cluster to the used ones
+func AddResources(used *protobuf.ResourceUsage, res *protobuf.ResourceUsage) {
+	used.Clusters += res.Clusters
+	used.VCPUs += res.VCPUs
+	used.RAM += res.RAM
+	used.Disk += res.Disk
+	used.Nodes += res.Nodes
+}
+
+// ProjectResources returns resources consumed by all project clusters except the cluster with excludeID
+func ProjectResources(db database.Database, project *protobuf.Project, excludeID string) (*protobuf.ResourceUsage, error) {
+	clusters, err := db.ReadProjectClusters(project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	used := &protobuf.ResourceUsage{}
+	for i := range clusters {
+		if clusters[i].ID == excludeID {
+			continue
+		}
+		res, err := ClusterResources(db, &clusters[i])
+		if err != nil {
+			return nil, err
+		}
+		AddResources(used, res)
+	}
+	return used, nil
+}
+
+// ProjectQuotas returns quotas of the project resources, quota equal to zero is not limited
+func ProjectQuotas(project *protobuf.Project) *protobuf.ResourceUsage {
+	return &protobuf.ResourceUsage{
+		Clusters: project.MaxClusters,
+		VCPUs:    project.MaxVCPUs,
+		RAM:      project.MaxRAM,
+		Disk:     project.MaxDisk,
+		Nodes:    project.MaxNodes,
+	}
+}
+
+// UpdateProjectLimits sets the project quotas and leases changed by the update request: zero value keeps
+// the project value and ProjectLimitReset value resets it to 0 - not limited
+func UpdateProjectLimits(project *protobuf.Project, update *protobuf.Project) {
+	limits := []struct {
+		value  *int32
+		update int32
+	}{
+		{&project.DefaultClusterTTL, update.DefaultClusterTTL},
+		{&project.MaxClusterTTL, update.MaxClusterTTL},
+		{&project.MaxClusters, update.MaxClusters},
+		{&project.MaxVCPUs, update.MaxVCPUs},
+		{&project.MaxRAM, update.MaxRAM},
+		{&project.MaxDisk, update.MaxDisk},
+		{&project.MaxNodes, update.MaxNodes},
+	}
+	for _, limit := range limits {
+		switch limit.update {
+		case 0:
+		case utils.ProjectLimitReset:
+			*limit.value = 0
+		default:
+			*limit.value = limit.update
+		}
+	}
+}
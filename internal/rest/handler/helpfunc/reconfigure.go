@@ -0,0 +1,52 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+	"sort"
+)
+
+// ReconfigureServiceConfig returns the service config with the changed parameters applied,
+// parameters with empty values are removed from the config
+func ReconfigureServiceConfig(config map[string]string, changes map[string]string) map[string]string {
+	res := make(map[string]string)
+	for name, value := range config {
+		res[name] = value
+	}
+	for name, value := range changes {
+		if value == "" {
+			delete(res, name)
+		} else {
+			res[name] = value
+		}
+	}
+	return res
+}
+
+// ChangedConfigs returns sorted names of the config parameters whose values differ in the old and new configs
+func ChangedConfigs(oldConfig map[string]string, newConfig map[string]string) []string {
+	var changed []string
+	for name, value := range newConfig {
+		if oldValue, ok := oldConfig[name]; !ok || oldValue != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range oldConfig {
+		if _, ok := newConfig[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// ConfigsRequireRestart returns true if any of the changed config parameters requires restart of the service
+func ConfigsRequireRestart(changed []string, configs []*protobuf.ServiceConfig) bool {
+	for _, name := range changed {
+		for _, sc := range configs {
+			if sc.ParameterName == name && sc.RequiresRestart {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+)
+
+// FindClusterService returns the cluster service with the given ID, name or type
+func FindClusterService(cluster *protobuf.Cluster, serviceIdOrName string) *protobuf.Service {
+	for _, service := range cluster.Services {
+		if service.ID == serviceIdOrName || service.Name == serviceIdOrName {
+			return service
+		}
+	}
+	// service type is unique in the cluster
+	for _, service := range cluster.Services {
+		if service.Type == serviceIdOrName {
+			return service
+		}
+	}
+	return nil
+}
+
+// ResolveServiceConfig returns the service config with default values of the version parameters which are not set
+func ResolveServiceConfig(config map[string]string, configs []*protobuf.ServiceConfig) map[string]string {
+	res := make(map[string]string)
+	for _, sc := range configs {
+		if sc.DefaultValue != "" {
+			res[sc.ParameterName] = sc.DefaultValue
+		}
+	}
+	for name, value := range config {
+		res[name] = value
+	}
+	return res
+}
@@ -0,0 +1,32 @@
+package helpfunc
+
+import (
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/protobuf"
+)
+
+// SetServiceTypeActionsGeneratedFields sets uuids of the service type actions and ansible variable names
+// of their parameters named after the service type, as for service configs
+func SetServiceTypeActionsGeneratedFields(serviceType string, actions []*protobuf.ServiceAction) error {
+	for _, action := range actions {
+		aUuid, err := uuid.NewRandom()
+		if err != nil {
+			return ErrUuidLibError
+		}
+		action.ID = aUuid.String()
+		for _, param := range action.Parameters {
+			param.AnsibleVarName = serviceType + "_" + param.ParameterName
+		}
+	}
+	return nil
+}
+
+// ServiceTypeSupports checks if the launcher could run the cluster action for services of the type
+func ServiceTypeSupports(sType *protobuf.ServiceType, action string) bool {
+	for _, supported := range sType.SupportedActions {
+		if supported == action {
+			return true
+		}
+	}
+	return false
+}
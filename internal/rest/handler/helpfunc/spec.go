@@ -0,0 +1,109 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// ApplyClusterSpec changes the cluster to its validated desired spec and returns the action converging
+// the cluster instances to it and whether the cluster was changed. Empty action means that only
+// the cluster fields saved in database are changed. The spec is validated to list all deployed services and keys
+func ApplyClusterSpec(db database.Database, cluster *protobuf.Cluster, spec *protobuf.Cluster) (string, bool, error) {
+	changed := false
+	if spec.DisplayName != "" && spec.DisplayName != cluster.DisplayName {
+		cluster.DisplayName = spec.DisplayName
+		changed = true
+	}
+	if spec.Description != cluster.Description {
+		cluster.Description = spec.Description
+		changed = true
+	}
+	for _, key := range spec.Keys {
+		if !utils.ItemExists(cluster.Keys, key) {
+			cluster.Keys = append(cluster.Keys, key)
+			changed = true
+		}
+	}
+
+	// failed or cancelled cluster is deployed again
+	action := ""
+	if cluster.EntityStatus != utils.StatusActive {
+		action = utils.ActionCreate
+	}
+
+	if len(spec.Services) != 0 {
+		servicesAction, err := applySpecServices(db, cluster, spec)
+		if err != nil {
+			return "", false, err
+		}
+		if action == "" || servicesAction == utils.ActionCreate {
+			action = servicesAction
+		}
+	}
+
+	if spec.NSlaves > cluster.NSlaves {
+		cluster.NSlaves = spec.NSlaves
+		action = utils.ActionCreate
+	} else if spec.NSlaves < cluster.NSlaves {
+		if action != "" {
+			return "", false, ErrClusterSpecScale
+		}
+		MarkRemovedNodes(cluster, &protobuf.ScaleRequest{NSlaves: spec.NSlaves})
+		action = utils.ActionScale
+	}
+
+	return action, changed || action != "", nil
+}
+
+// applySpecServices adds new services of the spec to the cluster and changes configs of the deployed ones,
+// returns the action deploying the changes
+func applySpecServices(db database.Database, cluster *protobuf.Cluster, spec *protobuf.Cluster) (string, error) {
+	serviceTypesOld, oldServiceNumber, err := SetServiceExistInfo(db, cluster)
+	if err != nil {
+		return "", err
+	}
+
+	action := ""
+	var newServices []*protobuf.Service
+	for _, service := range spec.Services {
+		old := serviceTypesOld[service.Type]
+		if !old.Exists {
+			newServices = append(newServices, service)
+			continue
+		}
+		if !sameConfig(old.Service.Config, service.Config) {
+			old.Service.Config = service.Config
+			action = utils.ActionUpdate
+		}
+	}
+	if len(newServices) == 0 {
+		return action, nil
+	}
+
+	newHost, err := AppendNewServices(db, serviceTypesOld, &protobuf.Cluster{Services: newServices}, cluster)
+	if err != nil {
+		return "", err
+	}
+	err = UpdateRangeValuesAppendedServices(db, oldServiceNumber, cluster, utils.ActionUpdate)
+	if err != nil {
+		return "", err
+	}
+	if newHost {
+		return utils.ActionCreate, nil
+	}
+	return utils.ActionUpdate, nil
+}
+
+// sameConfig checks if the service configs have the same parameters and values
+func sameConfig(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package helpfunc
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares dotted service versions part by part, numeric parts are compared as numbers.
+// Returns negative number if a is older than b, zero if they are equal and positive number otherwise
+func CompareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		if aErr == nil && bErr == nil {
+			if aNum != bNum {
+				return aNum - bNum
+			}
+			continue
+		}
+		if res := strings.Compare(aParts[i], bParts[i]); res != 0 {
+			return res
+		}
+	}
+	return len(aParts) - len(bParts)
+}
+
+// UpgradeServiceConfig returns service config parameters which exist in the new service version
+// and sorted names of the removed ones
+func UpgradeServiceConfig(config map[string]string, version *protobuf.ServiceVersion) (map[string]string, []string) {
+	res := make(map[string]string)
+	var removed []string
+	for name, value := range config {
+		found := false
+		for _, sc := range version.Configs {
+			if sc.ParameterName == name {
+				found = true
+				break
+			}
+		}
+		if found {
+			res[name] = value
+		} else {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(removed)
+	return res, removed
+}
@@ -8,16 +8,31 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-type GrpcClient interface {
-	StartClusterCreation(c *proto.Cluster)
-	StartClusterDestroying(c *proto.Cluster)
-	StartClusterModification(c *proto.Cluster)
+type OperationQueue interface {
+	Enqueue(c *proto.Cluster, action string, ownerId string) (*proto.Operation, error)
+	EnqueueAction(c *proto.Cluster, action *proto.ClusterAction, ownerId string) (*proto.Operation, error)
+	Cancel(c *proto.Cluster, cleanup bool) (*proto.Operation, error)
+}
+
+type ClusterPlanner interface {
+	PlanCluster(c *proto.Cluster, action string) (*proto.ClusterPlan, error)
+}
+
+type HealthMonitor interface {
+	CheckCluster(c *proto.Cluster) (*proto.ClusterHealth, error)
+}
+
+type ClusterReconciler interface {
+	ReconcileCluster(c *proto.Cluster) (*proto.ClusterDrift, error)
 }
 
 type HttpServer struct {
-	Gc     GrpcClient
-	Logger *logrus.Logger
-	Db     database.Database
-	Router *httprouter.Router
-	Config utils.Config
+	Queue      OperationQueue
+	Planner    ClusterPlanner
+	Health     HealthMonitor
+	Reconciler ClusterReconciler
+	Logger     *logrus.Logger
+	Db         database.Database
+	Router     *httprouter.Router
+	Config     utils.Config
 }
@@ -8,10 +8,20 @@ import (
 	"github.com/ispras/michman/internal/utils"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 const (
 	respActionKey = "action"
+	respOffsetKey = "offset"
+
+	// server-sent event types of the cluster logs stream
+	logsEventEnd   = "end"
+	logsEventError = "error"
+
+	// logsStreamInterval is a period of checking cluster logs for new lines
+	logsStreamInterval = time.Second
 )
 
 type clusterLog struct {
@@ -79,19 +89,11 @@ func (hS HttpServer) ServeClusterLog(w http.ResponseWriter, r *http.Request, par
 		return
 	}
 
-	queryValues := r.URL.Query()
-	action := utils.ActionCreate
-
-	// checking the action field from the request for compliance with: create, delete, update
-	if tmpAction := queryValues.Get(respActionKey); tmpAction != "" {
-		if tmpAction == utils.ActionCreate || tmpAction == utils.ActionDelete || tmpAction == utils.ActionUpdate {
-			action = tmpAction
-		} else {
-			err = ErrLogsBadActionParam
-			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
-			response.Error(w, err)
-			return
-		}
+	action, err := getClusterLogAction(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
 	}
 
 	// initialize cluster logger
@@ -115,3 +117,137 @@ func (hS HttpServer) ServeClusterLog(w http.ResponseWriter, r *http.Request, par
 	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
 	response.Ok(w, resp, request)
 }
+
+// ServeClusterLogStream processes the request to follow cluster logs of the action with server-sent events.
+// New log lines are sent while the cluster has queued or running operation with the action,
+// the id of each event is the offset to resume the stream from with Last-Event-ID header or offset parameter.
+func (hS HttpServer) ServeClusterLogStream(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	projectIdOrName := params.ByName("projectIdOrName")
+	request := "GET logs/project/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/stream"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// reading cluster info from database
+	cluster, err := hS.Db.ReadCluster(project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	action, err := getClusterLogAction(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	offset, err := getClusterLogOffset(r)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// initialize cluster logger
+	cLogger, err := clusterlogger.MakeNewClusterLogger(hS.Config, cluster.ID, action)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	defer func() {
+		if err := cLogger.FinClusterLogsWriter(); err != nil {
+			hS.Logger.Warn(err)
+		}
+	}()
+
+	stream, err := response.NewEventStream(w)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	defer stream.Close()
+
+	for {
+		// the last lines are read after the operation has finished
+		active, err := helpfunc.ClusterActionActive(hS.Db, cluster.ID, action)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			stream.Send("", logsEventError, err.Error())
+			return
+		}
+
+		logs, next, err := cLogger.ReadClusterLogsFrom(offset)
+		if err != nil {
+			hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+			stream.Send("", logsEventError, err.Error())
+			return
+		}
+
+		if logs != "" {
+			offset = next
+			err = stream.Send(strconv.FormatInt(offset, 10), "", logs)
+		} else if !active {
+			stream.Send(strconv.FormatInt(offset, 10), logsEventEnd, action)
+			break
+		} else {
+			err = stream.Ping()
+		}
+		if err != nil {
+			hS.Logger.Info("Request ", request, " was closed by the client")
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			hS.Logger.Info("Request ", request, " was closed by the client")
+			return
+		case <-time.After(logsStreamInterval):
+		}
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+}
+
+// getClusterLogAction checks the action field from the request for compliance with: create, delete, update, scale
+// or one of the lifecycle actions. Action 'create' is default
+func getClusterLogAction(r *http.Request) (string, error) {
+	action := r.URL.Query().Get(respActionKey)
+	if action == "" {
+		return utils.ActionCreate, nil
+	}
+	if action != utils.ActionCreate && action != utils.ActionDelete && action != utils.ActionUpdate &&
+		action != utils.ActionScale && action != utils.ActionStop && action != utils.ActionStart &&
+		action != utils.ActionRestartService && action != utils.ActionRebootNode && action != utils.ActionServiceAction &&
+		action != utils.ActionRemoveService && action != utils.ActionUpgradeService &&
+		action != utils.ActionReconfigureService && action != utils.ActionRepair {
+		return "", ErrLogsBadActionParam
+	}
+	return action, nil
+}
+
+// getClusterLogOffset returns offset of the logs stream from Last-Event-ID header or offset field of the request
+func getClusterLogOffset(r *http.Request) (int64, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get(respOffsetKey)
+	}
+	if value == "" {
+		return 0, nil
+	}
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || offset < 0 {
+		return 0, ErrLogsBadOffsetParam
+	}
+	return offset, nil
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+// ClusterOperationsGetList processes a request to get a list of all operations of the cluster
+func (hS HttpServer) ClusterOperationsGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/operations"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	clusterId, err := helpfunc.GetOperationsClusterId(hS.Db, project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	operations, err := hS.Db.ReadClusterOperations(clusterId)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, operations, request)
+}
+
+// ClusterOperationGet processes a request to get an operation of the cluster by id
+func (hS HttpServer) ClusterOperationGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	clusterIdOrName := params.ByName("clusterIdOrName")
+	operationId := params.ByName("operationId")
+	request := "GET /projects/" + projectIdOrName + "/clusters/" + clusterIdOrName + "/operations/" + operationId
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	clusterId, err := helpfunc.GetOperationsClusterId(hS.Db, project.ID, clusterIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	operation, err := hS.Db.ReadOperation(operationId)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// operation must belong to the requested cluster
+	if operation.ClusterID != clusterId || operation.ProjectID != project.ID {
+		err = ErrClusterOperationNotFound(operationId, clusterIdOrName)
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, operation, request)
+}
+
+// OperationGet processes a request to get any operation by id
+func (hS HttpServer) OperationGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	operationId := params.ByName("operationId")
+	request := "GET /operations/" + operationId
+	hS.Logger.Info(request)
+
+	operation, err := hS.Db.ReadOperation(operationId)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, operation, request)
+}
+
+// ProjectOperationGet processes a request to get an operation of any cluster of the project by id,
+// so project members could poll the operation returned in the X-Operation-ID header
+func (hS HttpServer) ProjectOperationGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	operationId := params.ByName("operationId")
+	request := "GET /projects/" + projectIdOrName + "/operations/" + operationId
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	operation, err := hS.Db.ReadOperation(operationId)
+	if err == nil && operation.ProjectID != project.ID {
+		err = ErrProjectOperationNotFound(operationId, projectIdOrName)
+	}
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, operation, request)
+}
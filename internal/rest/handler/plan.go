@@ -0,0 +1,61 @@
+package handler
+
+import (
+	proto "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	response "github.com/ispras/michman/internal/rest/response"
+	"net/http"
+	"strconv"
+)
+
+// getDryRun returns true if the request asks only for the plan of the cluster action
+func getDryRun(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get(QueryDryRunKey)
+	if value == "" {
+		return false, nil
+	}
+	dryRun, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, ErrClusterBadDryRunParam
+	}
+	return dryRun, nil
+}
+
+// addedServices returns the cluster services which types were not requested by user
+func addedServices(services []*proto.Service, requested []*proto.Service) []*proto.Service {
+	types := make(map[string]bool)
+	for _, service := range requested {
+		types[service.Type] = true
+	}
+
+	var res []*proto.Service
+	for _, service := range services {
+		if !types[service.Type] {
+			res = append(res, service)
+		}
+	}
+	return res
+}
+
+// clusterPlan responds with the plan of the cluster action instead of running it
+func (hS HttpServer) clusterPlan(w http.ResponseWriter, request string, cluster *proto.Cluster, action string, added []*proto.Service) {
+	nodes, err := helpfunc.ClusterNodes(hS.Db, cluster)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	plan, err := hS.Planner.PlanCluster(cluster, action)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	plan.Cluster = cluster
+	plan.AddedServices = added
+	plan.Nodes = nodes
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, plan, request)
+}
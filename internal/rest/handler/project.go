@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"github.com/google/uuid"
 	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
 	"github.com/ispras/michman/internal/rest/handler/validate"
 	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/ispras/michman/internal/rest/usage"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 )
@@ -83,6 +85,49 @@ func (hS HttpServer) ProjectGet(w http.ResponseWriter, _ *http.Request, params h
 	response.Ok(w, project, request)
 }
 
+// ProjectUsageGet processes a request to get resources consumed by the project clusters against the project quotas
+// and node-hours, vCPU-hours and RAM-hours consumed by them in the requested period
+func (hS HttpServer) ProjectUsageGet(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/usage"
+	hS.Logger.Info(request)
+
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	report, format, err := readUsageReport(hS, r, project.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	if format == usage.FormatCsv {
+		writeUsageCsv(hS, w, request, report, "usage-"+project.Name+".csv")
+		return
+	}
+
+	used, err := helpfunc.ProjectResources(hS.Db, project, "")
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	projectUsage := &protobuf.ProjectUsage{
+		ProjectID: project.ID,
+		Used:      used,
+		Quotas:    helpfunc.ProjectQuotas(project),
+		Report:    report,
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, projectUsage, request)
+}
+
 // ProjectUpdate processes a request to update a project struct in database
 func (hS HttpServer) ProjectUpdate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
 	projectIdOrName := params.ByName("projectIdOrName")
@@ -139,6 +184,24 @@ func (hS HttpServer) ProjectUpdate(w http.ResponseWriter, r *http.Request, param
 	if newProj.DefaultMonitoringFlavor != "" {
 		resProj.DefaultMonitoringFlavor = newProj.DefaultMonitoringFlavor
 	}
+	if newProj.DefaultCloud != "" {
+		resProj.DefaultCloud = newProj.DefaultCloud
+	}
+	helpfunc.UpdateProjectLimits(resProj, &newProj)
+
+	// lease limits are checked together with the values which are not updated
+	err = validate.ProjectClusterTTL(resProj)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	err = validate.ProjectQuotas(resProj)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
 
 	err = hS.Db.UpdateProject(resProj)
 	if err != nil {
@@ -17,14 +17,53 @@ func (hS *HttpServer) CreateRoutes() {
 	hS.Router.GET("/projects/:projectIdOrName", hS.ProjectGet)
 	hS.Router.PUT("/projects/:projectIdOrName", hS.ProjectUpdate)
 	hS.Router.DELETE("/projects/:projectIdOrName", hS.ProjectDelete)
+	hS.Router.GET("/projects/:projectIdOrName/usage", hS.ProjectUsageGet)
 
 	// clusters:
 	hS.Router.GET("/projects/:projectIdOrName/clusters", hS.ClustersGetList)
 	hS.Router.POST("/projects/:projectIdOrName/clusters", hS.ClusterCreate)
 	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName", hS.ClusterGet)
 	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/status", hS.ClusterStatusGet)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/nodes", hS.ClusterNodesGetList)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/services", hS.ClusterServicesGetList)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/services/:serviceIdOrName", hS.ClusterServiceGet)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/health", hS.ClusterHealthGet)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/health", hS.ClusterHealthCheck)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/drift", hS.ClusterDriftGet)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/drift", hS.ClusterDriftCheck)
 	hS.Router.PUT("/projects/:projectIdOrName/clusters/:clusterIdOrName", hS.ClustersUpdate)
+	hS.Router.PUT("/projects/:projectIdOrName/clusters/:clusterIdOrName/spec", hS.ClusterSpecApply)
 	hS.Router.DELETE("/projects/:projectIdOrName/clusters/:clusterIdOrName", hS.ClustersDelete)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/cancel", hS.ClusterCancel)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/scale", hS.ClusterScale)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/lease", hS.ClusterLeaseExtend)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/actions", hS.ClusterAction)
+	hS.Router.DELETE("/projects/:projectIdOrName/clusters/:clusterIdOrName/services/:serviceIdOrName", hS.ClusterServiceDelete)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/services/:serviceIdOrName/upgrade", hS.ClusterServiceUpgrade)
+	hS.Router.PATCH("/projects/:projectIdOrName/clusters/:clusterIdOrName/services/:serviceIdOrName/config", hS.ClusterServiceConfigUpdate)
+	hS.Router.POST("/projects/:projectIdOrName/clusters/:clusterIdOrName/services/:serviceIdOrName/actions/:actionName", hS.ClusterServiceAction)
+
+	// autoscaling:
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/autoscaling", hS.AutoscalingPolicyGet)
+	hS.Router.PUT("/projects/:projectIdOrName/clusters/:clusterIdOrName/autoscaling", hS.AutoscalingPolicySet)
+	hS.Router.DELETE("/projects/:projectIdOrName/clusters/:clusterIdOrName/autoscaling", hS.AutoscalingPolicyDelete)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/autoscaling/decisions", hS.ScalingDecisionsGetList)
+
+	// webhooks:
+	hS.Router.GET("/projects/:projectIdOrName/webhooks", hS.WebhooksGetList)
+	hS.Router.POST("/projects/:projectIdOrName/webhooks", hS.WebhookCreate)
+	hS.Router.GET("/projects/:projectIdOrName/webhooks/:webhookId", hS.WebhookGet)
+	hS.Router.DELETE("/projects/:projectIdOrName/webhooks/:webhookId", hS.WebhookDelete)
+	hS.Router.GET("/projects/:projectIdOrName/webhooks/:webhookId/deliveries", hS.WebhookDeliveriesGetList)
+
+	// operations:
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/operations", hS.ClusterOperationsGetList)
+	hS.Router.GET("/projects/:projectIdOrName/clusters/:clusterIdOrName/operations/:operationId", hS.ClusterOperationGet)
+	hS.Router.GET("/projects/:projectIdOrName/operations/:operationId", hS.ProjectOperationGet)
+	hS.Router.GET("/operations/:operationId", hS.OperationGet)
+
+	// usage:
+	hS.Router.GET("/usage", hS.UsageReportGet)
 
 	// service type:
 	hS.Router.POST("/configs", hS.ConfigsServiceTypeCreate)
@@ -61,6 +100,13 @@ func (hS *HttpServer) CreateRoutes() {
 	hS.Router.PUT("/images/:imageIdOrName", hS.ImageUpdate)
 	hS.Router.DELETE("/images/:imageIdOrName", hS.ImageDelete)
 
+	// clouds:
+	hS.Router.GET("/clouds", hS.CloudsGetList)
+	hS.Router.GET("/clouds/:cloudIdOrName", hS.CloudGet)
+	hS.Router.POST("/clouds", hS.CloudCreate)
+	hS.Router.PUT("/clouds/:cloudIdOrName", hS.CloudUpdate)
+	hS.Router.DELETE("/clouds/:cloudIdOrName", hS.CloudDelete)
+
 	// flavors:
 	hS.Router.POST("/flavors", hS.FlavorCreate)
 	hS.Router.GET("/flavors", hS.FlavorsGetList)
@@ -89,6 +135,7 @@ func (hS *HttpServer) CreateRoutes() {
 	hS.Router.GET("/logs/launcher", hS.ServeAnsibleServiceLog)
 	hS.Router.GET("/logs/http_server", hS.ServeHttpServerLog)
 	hS.Router.GET("/logs/projects/:projectIdOrName/clusters/:clusterIdOrName", hS.ServeClusterLog)
+	hS.Router.GET("/logs/projects/:projectIdOrName/clusters/:clusterIdOrName/stream", hS.ServeClusterLogStream)
 
 	// service version:
 	hS.Router.GET("/version", hS.GetVersion)
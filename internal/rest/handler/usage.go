@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bytes"
+	"github.com/ispras/michman/internal/protobuf"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/ispras/michman/internal/rest/usage"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+// UsageReportGet processes a request to get node-hours, vCPU-hours and RAM-hours consumed by clusters of all projects
+func (hS HttpServer) UsageReportGet(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	request := "GET /usage"
+	hS.Logger.Info(request)
+
+	report, format, err := readUsageReport(hS, r, "")
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	if format == usage.FormatCsv {
+		writeUsageCsv(hS, w, request, report, "usage.csv")
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, report, request)
+}
+
+// readUsageReport builds usage report of the project for the period and in the format set by the request query
+func readUsageReport(hS HttpServer, r *http.Request, projectId string) (*protobuf.UsageReport, string, error) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	err := usage.CheckFormat(format)
+	if err != nil {
+		return nil, format, err
+	}
+
+	from, to, err := usage.ParsePeriod(query.Get("from"), query.Get("to"), time.Now())
+	if err != nil {
+		return nil, format, err
+	}
+
+	report, err := usage.Build(hS.Db, projectId, from, to)
+	return report, format, err
+}
+
+// writeUsageCsv sends items of the usage report as CSV file
+func writeUsageCsv(hS HttpServer, w http.ResponseWriter, request string, report *protobuf.UsageReport, fileName string) {
+	var buf bytes.Buffer
+	err := usage.WriteCsv(&buf, report)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, ErrCsvEncode)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Csv(w, buf.Bytes(), fileName)
+}
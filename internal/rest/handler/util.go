@@ -4,4 +4,6 @@ const (
 	QueryViewTypeFull    = "full"
 	QueryViewTypeSummary = "summary"
 	QueryViewKey         = "view"
+	QueryCleanupKey      = "cleanup"
+	QueryDryRunKey       = "dry_run"
 )
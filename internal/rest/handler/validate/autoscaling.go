@@ -0,0 +1,53 @@
+package validate
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/check"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// AutoscalingPolicy validates fields of the autoscaling policy set for the cluster
+func AutoscalingPolicy(db database.Database, cluster *protobuf.Cluster, policy *protobuf.AutoscalingPolicy) error {
+	if policy.ClusterID != "" {
+		return ErrGeneratedField("autoscaling policy", "ClusterID")
+	}
+	if policy.ProjectID != "" {
+		return ErrGeneratedField("autoscaling policy", "ProjectID")
+	}
+	if policy.LastScaledAt != "" {
+		return ErrGeneratedField("autoscaling policy", "LastScaledAt")
+	}
+
+	ms, err := check.MSServices(db, cluster)
+	if err != nil {
+		return err
+	}
+	if !ms {
+		return ErrAutoscalingMasterSlave
+	}
+
+	if policy.MinSlaves < 1 || policy.MaxSlaves < policy.MinSlaves {
+		return ErrAutoscalingSlaves
+	}
+	if policy.ScaleUpThreshold <= policy.ScaleDownThreshold {
+		return ErrAutoscalingThresholds
+	}
+	if policy.ScaleStep < 0 || policy.Cooldown < 0 {
+		return ErrAutoscalingStep
+	}
+
+	if policy.MetricURL == "" {
+		return ErrEmptyField("autoscaling policy", "MetricURL")
+	}
+	switch policy.MetricSource {
+	case utils.MetricSourcePrometheus:
+		if policy.MetricQuery == "" {
+			return ErrAutoscalingMetricQuery
+		}
+	case utils.MetricSourceHttp:
+	default:
+		return ErrAutoscalingMetricSource(policy.MetricSource)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package validate
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/check"
+	"github.com/ispras/michman/internal/rest/response"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// CloudCreate validates fields of the cloud structure for correct filling when creating
+func CloudCreate(db database.Database, cloud *protobuf.Cloud) error {
+	if cloud.ID != "" {
+		return ErrGeneratedField("cloud", "ID")
+	}
+	if cloud.Name == "" {
+		return ErrEmptyField("cloud", "Name")
+	}
+	if cloud.Type == "" {
+		return ErrEmptyField("cloud", "Type")
+	}
+	return cloudType(cloud)
+}
+
+// CloudUpdate validates fields of the cloud structure for correct filling when updating
+func CloudUpdate(db database.Database, oldCloud *protobuf.Cloud, newCloud *protobuf.Cloud) error {
+	if newCloud.ID != "" {
+		return ErrCloudUnmodFields
+	}
+
+	renamed := newCloud.Name != "" && oldCloud.Name != newCloud.Name
+	retyped := newCloud.Type != "" && oldCloud.Type != newCloud.Type
+	if renamed || retyped {
+		used, err := check.CloudUsed(db, oldCloud.Name)
+		if err != nil {
+			return err
+		}
+		if used {
+			return ErrCloudUsed
+		}
+	}
+
+	if renamed {
+		_, err := db.ReadCloud(newCloud.Name)
+		if err == nil {
+			return ErrObjectExists("cloud", newCloud.Name)
+		}
+		if err != nil && response.ErrorClass(err) != utils.ObjectNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CloudResult validates the cloud structure resulting from the update
+func CloudResult(cloud *protobuf.Cloud) error {
+	return cloudType(cloud)
+}
+
+// CloudDelete checks the structure of the cloud for use when deleting
+func CloudDelete(db database.Database, cloud *protobuf.Cloud) error {
+	used, err := check.CloudUsed(db, cloud.Name)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrCloudUsed
+	}
+
+	return nil
+}
+
+// cloudType checks that the cloud type is supported and its required fields are set
+func cloudType(cloud *protobuf.Cloud) error {
+	switch cloud.Type {
+	case utils.CloudProviderOpenstack:
+		if cloud.VaultKey == "" {
+			return ErrEmptyField("cloud", "VaultKey")
+		}
+	case utils.CloudProviderFake:
+	default:
+		return ErrCloudType(cloud.Type)
+	}
+	return nil
+}
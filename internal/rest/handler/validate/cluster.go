@@ -41,6 +41,21 @@ func ClusterCreateGeneral(db database.Database, cluster *protobuf.Cluster) error
 	if cluster.ProjectID != "" {
 		return ErrGeneratedField("cluster", "ProjectID")
 	}
+	if len(cluster.Nodes) != 0 {
+		return ErrGeneratedField("cluster", "Nodes")
+	}
+	if cluster.ExpiresAt != "" {
+		return ErrGeneratedField("cluster", "ExpiresAt")
+	}
+	if cluster.ExpiryWarnedAt != "" {
+		return ErrGeneratedField("cluster", "ExpiryWarnedAt")
+	}
+	if cluster.Health != "" || cluster.HealthCheckedAt != "" {
+		return ErrGeneratedField("cluster", "Health")
+	}
+	if len(cluster.Drift) != 0 || cluster.DriftCheckedAt != "" {
+		return ErrGeneratedField("cluster", "Drift")
+	}
 
 	if cluster.NSlaves < 0 {
 		return ErrClusterNSlavesZero
@@ -66,6 +81,12 @@ func ClusterCreateGeneral(db database.Database, cluster *protobuf.Cluster) error
 	if err != nil {
 		return err
 	}
+	if cluster.Cloud != "" {
+		_, err = db.ReadCloud(cluster.Cloud)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -94,7 +115,8 @@ func ClusterCreateServices(db database.Database, cluster *protobuf.Cluster) erro
 
 // ClusterUpdate validates fields of the cluster structure for correct filling when updating
 func ClusterUpdate(db database.Database, oldCluster *protobuf.Cluster, newCluster *protobuf.Cluster) error {
-	if oldCluster.EntityStatus != utils.StatusActive && oldCluster.EntityStatus != utils.StatusFailed {
+	if oldCluster.EntityStatus != utils.StatusActive && oldCluster.EntityStatus != utils.StatusFailed &&
+		oldCluster.EntityStatus != utils.StatusCancelled {
 		return ErrClusterStatus
 	}
 	if newCluster.ID != "" {
@@ -136,6 +158,21 @@ func ClusterUpdate(db database.Database, oldCluster *protobuf.Cluster, newCluste
 	if newCluster.MonitoringFlavor != "" {
 		return ErrClusterUnmodFields("MonitoringFlavor")
 	}
+	if newCluster.Cloud != "" {
+		return ErrClusterUnmodFields("Cloud")
+	}
+	if len(newCluster.Nodes) != 0 {
+		return ErrClusterUnmodFields("Nodes")
+	}
+	if newCluster.TTL != 0 || newCluster.ExpiresAt != "" || newCluster.ExpiryWarnedAt != "" {
+		return ErrClusterUnmodFields("TTL")
+	}
+	if newCluster.Health != "" || newCluster.HealthCheckedAt != "" {
+		return ErrClusterUnmodFields("Health")
+	}
+	if len(newCluster.Drift) != 0 || newCluster.DriftCheckedAt != "" {
+		return ErrClusterUnmodFields("Drift")
+	}
 
 	// check correctness of new services
 	for _, services := range newCluster.Services {
@@ -148,15 +185,124 @@ func ClusterUpdate(db database.Database, oldCluster *protobuf.Cluster, newCluste
 	return nil
 }
 
+// ClusterSpec validates the desired spec of the existing cluster: fields set on the cluster creation
+// and versions of the deployed services can't be changed, deployed services and keys can't be removed,
+// slaves can be removed only from the active cluster.
+// Fields describing the cluster state (status, nodes, addresses and lease) are ignored
+func ClusterSpec(db database.Database, cluster *protobuf.Cluster, spec *protobuf.Cluster) error {
+	if cluster.EntityStatus != utils.StatusActive && cluster.EntityStatus != utils.StatusFailed &&
+		cluster.EntityStatus != utils.StatusCancelled {
+		return ErrClusterStatus
+	}
+
+	// empty fields keep the cluster values
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"ID", cluster.ID, spec.ID},
+		{"Name", cluster.Name, spec.Name},
+		{"ProjectID", cluster.ProjectID, spec.ProjectID},
+		{"Image", cluster.Image, spec.Image},
+		{"MasterFlavor", cluster.MasterFlavor, spec.MasterFlavor},
+		{"SlavesFlavor", cluster.SlavesFlavor, spec.SlavesFlavor},
+		{"StorageFlavor", cluster.StorageFlavor, spec.StorageFlavor},
+		{"MonitoringFlavor", cluster.MonitoringFlavor, spec.MonitoringFlavor},
+		{"Cloud", cluster.Cloud, spec.Cloud},
+	}
+	for _, field := range fields {
+		if field.new != "" && field.new != field.old {
+			return ErrClusterUnmodFields(field.name)
+		}
+	}
+	if spec.Monitoring != cluster.Monitoring {
+		return ErrClusterUnmodFields("Monitoring")
+	}
+	if spec.NSlaves < 0 {
+		return ErrClusterNSlavesZero
+	}
+
+	// services and keys can't be removed by the spec, empty lists keep the cluster ones
+	if len(spec.Services) != 0 {
+		for _, old := range cluster.Services {
+			if !specHasService(spec, old.Type) {
+				return ErrClusterSpecServiceMissing(old.Type)
+			}
+		}
+	}
+	if len(spec.Keys) != 0 {
+		for _, key := range cluster.Keys {
+			if !utils.ItemExists(spec.Keys, key) {
+				return ErrClusterSpecKeysMissing
+			}
+		}
+	}
+
+	// deployed services keep their versions
+	for _, service := range spec.Services {
+		for _, old := range cluster.Services {
+			if old.Type != service.Type {
+				continue
+			}
+			if service.Version == "" {
+				service.Version = old.Version
+			} else if service.Version != old.Version {
+				return ErrClusterServiceVersionUnmod(service.Type)
+			}
+		}
+		err := ClusterService(db, service)
+		if err != nil {
+			return err
+		}
+	}
+
+	if spec.NSlaves < cluster.NSlaves {
+		return ClusterScale(db, cluster, &protobuf.ScaleRequest{NSlaves: spec.NSlaves})
+	}
+	return nil
+}
+
+// specHasService checks if the spec lists the service of the type
+func specHasService(spec *protobuf.Cluster, serviceType string) bool {
+	for _, service := range spec.Services {
+		if service.Type == serviceType {
+			return true
+		}
+	}
+	return false
+}
+
 // ClusterDelete validates the cluster structure for the correct status when deleting
 func ClusterDelete(cluster *protobuf.Cluster) error {
-	if cluster.EntityStatus != utils.StatusActive && cluster.EntityStatus != utils.StatusFailed {
-		return ErrClusterStatus
+	if cluster.EntityStatus != utils.StatusActive && cluster.EntityStatus != utils.StatusFailed &&
+		cluster.EntityStatus != utils.StatusCancelled && cluster.EntityStatus != utils.StatusStopped &&
+		cluster.EntityStatus != utils.StatusMissing {
+		return ErrClusterDeleteStatus
 	}
 
 	return nil
 }
 
+// ClusterLease validates the cluster lease in hours: it can't be negative and must be set
+// and not greater than the maximal lease if the project limits lease of its clusters
+func ClusterLease(project *protobuf.Project, ttl int32) error {
+	if ttl < 0 {
+		return ErrClusterTTLNegative
+	}
+	if project.MaxClusterTTL > 0 && (ttl == 0 || ttl > project.MaxClusterTTL) {
+		return ErrClusterTTLMax(project.MaxClusterTTL)
+	}
+	return nil
+}
+
+// ClusterLeaseExtend validates the request to extend the cluster lease
+func ClusterLeaseExtend(project *protobuf.Project, cluster *protobuf.Cluster, lease *protobuf.LeaseRequest) error {
+	if cluster.EntityStatus == utils.StatusStopping {
+		return ErrClusterLeaseStatus
+	}
+	return ClusterLease(project, lease.TTL)
+}
+
 // ClusterServices validates service fields of the cluster structure after addition services from dependencies
 func ClusterServices(db database.Database, cluster *protobuf.Cluster) error {
 	// check correctness of services
@@ -202,3 +348,318 @@ func ClusterService(db database.Database, service *protobuf.Service) error {
 
 	return nil
 }
+
+// ClusterScale validates the scale request: cluster must be active, only its slave nodes can be removed
+// and master-slave services must keep at least one slave
+func ClusterScale(db database.Database, cluster *protobuf.Cluster, scale *protobuf.ScaleRequest) error {
+	if cluster.EntityStatus != utils.StatusActive {
+		return ErrClusterScaleStatus
+	}
+	if len(scale.RemoveNodes) != 0 && scale.NSlaves != 0 {
+		return ErrClusterScaleParams
+	}
+
+	slaves := helpfunc.ClusterSlaveNodes(cluster)
+	remaining := int(scale.NSlaves)
+	if len(scale.RemoveNodes) == 0 {
+		if scale.NSlaves < 0 || remaining >= len(slaves) {
+			return ErrClusterScaleNSlaves(len(slaves))
+		}
+	} else {
+		removed := make(map[string]bool)
+		for _, name := range scale.RemoveNodes {
+			found := false
+			for _, node := range slaves {
+				if node.Name == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return ErrClusterScaleNode(name)
+			}
+			removed[name] = true
+		}
+		remaining = len(slaves) - len(removed)
+	}
+
+	if remaining == 0 {
+		res, err := check.MSServices(db, cluster)
+		if err != nil {
+			return err
+		}
+		if res {
+			return ErrClustersNSlavesMasterSlave
+		}
+	}
+	return nil
+}
+
+// ClusterAction validates the lifecycle action request: cluster must be in the status suitable for the action,
+// restarted service must be deployed in the cluster and support restart, rebooted node must be one of the cluster nodes.
+// Repair re-runs the cluster playbooks and is allowed for active, missing and failed clusters
+func ClusterAction(db database.Database, cluster *protobuf.Cluster, action *protobuf.ClusterAction) error {
+	if action.Cluster != nil {
+		return ErrGeneratedField("action", "Cluster")
+	}
+	if (action.Service != "" && action.Action != utils.ActionRestartService) ||
+		(action.Node != "" && action.Action != utils.ActionRebootNode) ||
+		action.ServiceAction != "" || action.Params != nil {
+		return ErrClusterActionParams
+	}
+
+	switch action.Action {
+	case utils.ActionStop, utils.ActionRestartService, utils.ActionRebootNode:
+		if cluster.EntityStatus != utils.StatusActive && cluster.EntityStatus != utils.StatusFailed {
+			return ErrClusterActionStatus(action.Action, utils.StatusActive, utils.StatusFailed)
+		}
+	case utils.ActionStart:
+		if cluster.EntityStatus != utils.StatusStopped && cluster.EntityStatus != utils.StatusFailed {
+			return ErrClusterActionStatus(action.Action, utils.StatusStopped, utils.StatusFailed)
+		}
+	case utils.ActionRepair:
+		if cluster.EntityStatus != utils.StatusActive && cluster.EntityStatus != utils.StatusMissing &&
+			cluster.EntityStatus != utils.StatusFailed {
+			return ErrClusterActionStatus(action.Action, utils.StatusActive, utils.StatusMissing, utils.StatusFailed)
+		}
+	default:
+		return ErrClusterActionUnknown(action.Action)
+	}
+
+	switch action.Action {
+	case utils.ActionRestartService:
+		if action.Service == "" {
+			return ErrEmptyField("action", "Service")
+		}
+		deployed := false
+		for _, service := range cluster.Services {
+			if service.Type == action.Service {
+				deployed = true
+				break
+			}
+		}
+		if !deployed {
+			return ErrClusterActionService(action.Service)
+		}
+		// services without restart playbook can't be restarted by the launcher
+		sType, err := db.ReadServiceType(action.Service)
+		if err != nil {
+			return err
+		}
+		if !helpfunc.ServiceTypeSupports(sType, action.Action) {
+			return ErrClusterActionNotSupported(action.Action, action.Service)
+		}
+	case utils.ActionRebootNode:
+		if action.Node == "" {
+			return ErrEmptyField("action", "Node")
+		}
+		for _, node := range cluster.Nodes {
+			if node.Name == action.Node {
+				return nil
+			}
+		}
+		return ErrClusterActionNode(action.Node)
+	}
+	return nil
+}
+
+// ClusterServiceRemove validates the request to remove the service from the cluster: service must be deployed
+// in the active cluster and support removal, no remaining service may depend on it and removal can't change
+// the cluster instances
+func ClusterServiceRemove(db database.Database, cluster *protobuf.Cluster, serviceType string) error {
+	if cluster.EntityStatus != utils.StatusActive {
+		return ErrClusterActionStatus(utils.ActionRemoveService, utils.StatusActive)
+	}
+
+	// services without remove tasks can't be uninstalled by the launcher
+	sType, err := db.ReadServiceType(serviceType)
+	if err != nil {
+		return err
+	}
+	if !helpfunc.ServiceTypeSupports(sType, utils.ActionRemoveService) {
+		return ErrClusterActionNotSupported(utils.ActionRemoveService, serviceType)
+	}
+
+	remaining := &protobuf.Cluster{NSlaves: cluster.NSlaves, Monitoring: cluster.Monitoring}
+	deployed := false
+	for _, service := range cluster.Services {
+		if service.Type == serviceType {
+			deployed = true
+			continue
+		}
+		remaining.Services = append(remaining.Services, service)
+	}
+	if !deployed {
+		return ErrClusterActionService(serviceType)
+	}
+
+	for _, service := range remaining.Services {
+		sType, err := db.ReadServiceType(service.Type)
+		if err != nil {
+			return err
+		}
+		for _, version := range sType.Versions {
+			if version.Version != service.Version {
+				continue
+			}
+			for _, dependency := range version.Dependencies {
+				if dependency.ServiceType == serviceType {
+					return ErrClusterServiceDependent(serviceType, service.Type)
+				}
+			}
+		}
+	}
+
+	// instances of the removed service are not deleted
+	nodes, err := helpfunc.ClusterNodes(db, cluster)
+	if err != nil {
+		return err
+	}
+	remainingNodes, err := helpfunc.ClusterNodes(db, remaining)
+	if err != nil {
+		return err
+	}
+	for role, number := range nodes {
+		if remainingNodes[role] != number {
+			return ErrClusterServiceRemoveNodes(serviceType, role)
+		}
+	}
+	return nil
+}
+
+// ServiceUpgrade validates the request to upgrade the service of the active cluster to the newer version
+// of its service type, dependencies of all cluster services must be satisfied after the upgrade
+func ServiceUpgrade(db database.Database, cluster *protobuf.Cluster, sType *protobuf.ServiceType, version string) error {
+	if cluster.EntityStatus != utils.StatusActive {
+		return ErrClusterActionStatus(utils.ActionUpgradeService, utils.StatusActive)
+	}
+	if version == "" {
+		return ErrEmptyField("upgrade", "Version")
+	}
+
+	var service *protobuf.Service
+	for _, s := range cluster.Services {
+		if s.Type == sType.Type {
+			service = s
+			break
+		}
+	}
+	if service == nil {
+		return ErrClusterActionService(sType.Type)
+	}
+
+	found := false
+	for _, sv := range sType.Versions {
+		if sv.Version == version {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return helpfunc.ErrClusterServiceVersionNotSupported(version, sType.Type)
+	}
+	if helpfunc.CompareVersions(version, service.Version) <= 0 {
+		return ErrServiceUpgradeVersion(sType.Type, service.Version, version)
+	}
+
+	sTypes, err := db.ReadServicesTypesList()
+	if err != nil {
+		return err
+	}
+	return ServiceUpgradeDependencies(cluster.Services, sTypes, sType.Type, version)
+}
+
+// ServiceUpgradeDependencies checks that dependencies of the new service version are deployed in the cluster
+// in the supported versions and the cluster services depending on the upgraded one support its new version
+func ServiceUpgradeDependencies(services []*protobuf.Service, sTypes []protobuf.ServiceType, serviceType string, version string) error {
+	versions := make(map[string]string)
+	for _, service := range services {
+		versions[service.Type] = service.Version
+	}
+	versions[serviceType] = version
+
+	for _, service := range services {
+		var sv *protobuf.ServiceVersion
+		for i := range sTypes {
+			if sTypes[i].Type != service.Type {
+				continue
+			}
+			for _, v := range sTypes[i].Versions {
+				if v.Version == versions[service.Type] {
+					sv = v
+					break
+				}
+			}
+		}
+		if sv == nil {
+			return helpfunc.ErrClusterServiceVersionNotSupported(versions[service.Type], service.Type)
+		}
+
+		for _, dependency := range sv.Dependencies {
+			// only the upgraded service and its dependents are affected
+			if service.Type != serviceType && dependency.ServiceType != serviceType {
+				continue
+			}
+			depVersion, ok := versions[dependency.ServiceType]
+			if !ok {
+				return ErrServiceUpgradeDependency(service.Type, dependency.ServiceType, "")
+			}
+			if !utils.ItemExists(dependency.ServiceVersions, depVersion) {
+				return ErrServiceUpgradeDependency(service.Type, dependency.ServiceType, depVersion)
+			}
+		}
+	}
+	return nil
+}
+
+// ServiceReconfigure validates the request to change config parameters of the service of the active cluster,
+// new config must be correct for the deployed service version
+func ServiceReconfigure(cluster *protobuf.Cluster, service *protobuf.Service, config map[string]string, version *protobuf.ServiceVersion) error {
+	if cluster.EntityStatus != utils.StatusActive {
+		return ErrClusterActionStatus(utils.ActionReconfigureService, utils.StatusActive)
+	}
+	return check.ServiceConfigCorrectValue(&protobuf.Service{Type: service.Type, Config: config}, version.Configs)
+}
+
+// ServiceAction validates the request to run the service type action: service of the type must be deployed
+// in the cluster, cluster must be in the status allowed by the action and action parameters must be correct
+func ServiceAction(cluster *protobuf.Cluster, sType *protobuf.ServiceType, action *protobuf.ClusterAction) error {
+	var serviceAction *protobuf.ServiceAction
+	for _, a := range sType.Actions {
+		if a.Name == action.ServiceAction {
+			serviceAction = a
+			break
+		}
+	}
+	if serviceAction == nil {
+		return ErrServiceActionNotFound(action.ServiceAction, sType.Type)
+	}
+
+	deployed := false
+	for _, service := range cluster.Services {
+		if service.Type == sType.Type {
+			deployed = true
+			break
+		}
+	}
+	if !deployed {
+		return ErrClusterActionService(sType.Type)
+	}
+
+	allowedStatuses := serviceAction.AllowedStatuses
+	if len(allowedStatuses) == 0 {
+		allowedStatuses = []string{utils.StatusActive}
+	}
+	allowed := false
+	for _, status := range allowedStatuses {
+		if cluster.EntityStatus == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return ErrClusterActionStatus(serviceAction.Name, allowedStatuses...)
+	}
+
+	return check.ServiceActionParams(action.Params, serviceAction)
+}
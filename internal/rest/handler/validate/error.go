@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/ispras/michman/internal/rest"
 	"github.com/ispras/michman/internal/utils"
+	"strings"
 )
 
 const (
@@ -16,16 +17,36 @@ const (
 	// cluster:
 	errClusterNSlavesZero         = "NSlaves parameter must be number >= 0"
 	errClustersNSlavesMasterSlave = "NSlaves parameter must be number >= 1 because master-slave services will be installed"
-	errClusterStatus              = "cluster status must be 'ACTIVE' or 'FAILED' for UPDATE or DELETE"
+	errClusterStatus              = "cluster status must be 'ACTIVE', 'FAILED' or 'CANCELLED' for UPDATE"
+	errClusterDeleteStatus        = "cluster status must be 'ACTIVE', 'FAILED', 'CANCELLED', 'STOPPED' or 'MISSING' for DELETE"
+	errClusterScaleStatus         = "cluster status must be 'ACTIVE' for SCALE"
+	errClusterScaleParams         = "only one of NSlaves and RemoveNodes scale parameters can be set"
+	errClusterActionParams        = "Service can be set only for restart-service action and Node only for reboot-node action, service type actions are run by the cluster service actions request"
+	errClusterTTLNegative         = "TTL parameter must be number >= 0"
+	errClusterLeaseStatus         = "lease of the cluster being deleted can't be extended"
+	errClusterSpecKeysMissing     = "cluster spec must list all keys of the cluster: keys can't be removed from the deployed hosts"
 
 	// image:
 	errImageGeneratedField = "image ID is generated field. It can't be filled in by user"
 	errImageUsed           = "image already in use. it can't be modified or deleted"
 	errImageUnmodFields    = "some image fields can't be modified (ID)"
 
+	// cloud:
+	errCloudUnmodFields = "some cloud fields can't be modified (ID)"
+	errCloudUsed        = "cloud already in use. its name and type can't be modified and it can't be deleted"
+
+	// autoscaling:
+	errAutoscalingMasterSlave = "autoscaling is supported only for clusters with master-slave services"
+	errAutoscalingSlaves      = "autoscaling policy MinSlaves must be number >= 1 and MaxSlaves must be number >= MinSlaves"
+	errAutoscalingThresholds  = "autoscaling policy ScaleUpThreshold must be greater than ScaleDownThreshold"
+	errAutoscalingStep        = "autoscaling policy ScaleStep and Cooldown must be numbers >= 0"
+	errAutoscalingMetricQuery = "autoscaling policy MetricQuery must be set for prometheus metric source"
+
 	// project:
 	errProjectUnmodFields      = "some project fields can't be modified (ID, Name)"
+	errProjectClusterTTL       = "project DefaultClusterTTL and MaxClusterTTL must be numbers >= 0 and DefaultClusterTTL can't be greater than MaxClusterTTL"
 	errProjectHasClusters      = "project has clusters. Delete them first"
+	errProjectQuotas           = "project MaxClusters, MaxVCPUs, MaxRAM, MaxDisk and MaxNodes quotas must be numbers >= 0"
 	errClusterServiceTypeEmpty = "service type field can't be empty"
 
 	// service type:
@@ -37,6 +58,9 @@ const (
 	errServiceTypeVersionDependencyUnmodFields = "some service types version dependency fields can't be modified (Service Type)"
 	errServiceTypeUnmodVersionFields           = "service types version fields (config, dependencies) can't be modified in this response. Use specified one"
 	errServiceTypeVersionEmptyVersionField     = "version field must be set"
+
+	// webhook:
+	errWebhookURL = "webhook URL must be absolute http or https URL"
 )
 
 var (
@@ -44,6 +68,13 @@ var (
 	ErrClusterNSlavesZero         = rest.MakeError(errClusterNSlavesZero, utils.ValidationError)
 	ErrClustersNSlavesMasterSlave = rest.MakeError(errClustersNSlavesMasterSlave, utils.ValidationError)
 	ErrClusterStatus              = rest.MakeError(errClusterStatus, utils.ValidationError)
+	ErrClusterDeleteStatus        = rest.MakeError(errClusterDeleteStatus, utils.ValidationError)
+	ErrClusterScaleStatus         = rest.MakeError(errClusterScaleStatus, utils.ValidationError)
+	ErrClusterScaleParams         = rest.MakeError(errClusterScaleParams, utils.ValidationError)
+	ErrClusterActionParams        = rest.MakeError(errClusterActionParams, utils.ValidationError)
+	ErrClusterTTLNegative         = rest.MakeError(errClusterTTLNegative, utils.ValidationError)
+	ErrClusterLeaseStatus         = rest.MakeError(errClusterLeaseStatus, utils.ValidationError)
+	ErrClusterSpecKeysMissing     = rest.MakeError(errClusterSpecKeysMissing, utils.ValidationError)
 
 	// flavor:
 	ErrFlavorGeneratedField = rest.MakeError(errFlavorGeneratedField, utils.ValidationError)
@@ -56,9 +87,22 @@ var (
 	ErrImageUsed           = rest.MakeError(errImageUsed, utils.ValidationError)
 	ErrImageUnmodFields    = rest.MakeError(errImageUnmodFields, utils.ValidationError)
 
+	// cloud:
+	ErrCloudUnmodFields = rest.MakeError(errCloudUnmodFields, utils.ValidationError)
+	ErrCloudUsed        = rest.MakeError(errCloudUsed, utils.ObjectUsed)
+
+	// autoscaling:
+	ErrAutoscalingMasterSlave = rest.MakeError(errAutoscalingMasterSlave, utils.ValidationError)
+	ErrAutoscalingSlaves      = rest.MakeError(errAutoscalingSlaves, utils.ValidationError)
+	ErrAutoscalingThresholds  = rest.MakeError(errAutoscalingThresholds, utils.ValidationError)
+	ErrAutoscalingStep        = rest.MakeError(errAutoscalingStep, utils.ValidationError)
+	ErrAutoscalingMetricQuery = rest.MakeError(errAutoscalingMetricQuery, utils.ValidationError)
+
 	// project:
 	ErrProjectUnmodFields = rest.MakeError(errProjectUnmodFields, utils.ValidationError)
+	ErrProjectClusterTTL  = rest.MakeError(errProjectClusterTTL, utils.ValidationError)
 	ErrProjectHasClusters = rest.MakeError(errProjectHasClusters, utils.ValidationError)
+	ErrProjectQuotas      = rest.MakeError(errProjectQuotas, utils.ValidationError)
 
 	// service:
 	ErrClusterServiceTypeEmpty = rest.MakeError(errClusterServiceTypeEmpty, utils.ValidationError)
@@ -72,6 +116,9 @@ var (
 	ErrServiceTypeVersionConfigUnmodFields     = rest.MakeError(errServiceTypeVersionConfigUnmodFields, utils.ObjectUnmodified)
 	ErrServiceTypeVersionDependencyUnmodFields = rest.MakeError(errServiceTypeVersionDependencyUnmodFields, utils.ValidationError)
 	ErrServiceTypeVersionEmptyVersionField     = rest.MakeError(errServiceTypeVersionEmptyVersionField, utils.ValidationError)
+
+	// webhook:
+	ErrWebhookURL = rest.MakeError(errWebhookURL, utils.ValidationError)
 )
 
 // common:
@@ -96,20 +143,124 @@ func ErrImageValidationParam(param string) error {
 	return rest.MakeError(errMessage, utils.ValidationError)
 }
 
+// cloud:
+func ErrCloudType(cloudType string) error {
+	errMessage := fmt.Sprintf("cloud type '%s' is not supported", cloudType)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+// autoscaling:
+func ErrAutoscalingMetricSource(source string) error {
+	errMessage := fmt.Sprintf("autoscaling metric source '%s' is not supported", source)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+// webhook:
+func ErrWebhookEvent(event string) error {
+	errMessage := fmt.Sprintf("webhook event '%s' is not supported", event)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
 // cluster:
 func ErrClusterServiceVersionsEmpty(param string) error {
 	errMessage := fmt.Sprintf("'%s' service version and default version are not specified", param)
 	return rest.MakeError(errMessage, utils.ValidationError)
 }
 
+func ErrClusterScaleNSlaves(nSlaves int) error {
+	errMessage := fmt.Sprintf("NSlaves scale parameter must be number >= 0 and less than the current number of slave nodes (%d)", nSlaves)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterScaleNode(name string) error {
+	errMessage := fmt.Sprintf("node '%s' is not a slave node of the cluster", name)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterActionUnknown(action string) error {
+	errMessage := fmt.Sprintf("cluster action '%s' is not supported, use 'stop', 'start', 'restart-service' or 'reboot-node'", action)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterActionStatus(action string, statuses ...string) error {
+	errMessage := fmt.Sprintf("cluster status must be '%s' for %s action", strings.Join(statuses, "' or '"), action)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterActionService(serviceType string) error {
+	errMessage := fmt.Sprintf("service '%s' is not deployed in the cluster", serviceType)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterActionNotSupported(action string, serviceType string) error {
+	errMessage := fmt.Sprintf("%s action is not supported by service '%s'", action, serviceType)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterActionNode(name string) error {
+	errMessage := fmt.Sprintf("node '%s' is not a node of the cluster", name)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceActionNotFound(action string, serviceType string) error {
+	errMessage := fmt.Sprintf("action '%s' is not declared by service type '%s'", action, serviceType)
+	return rest.MakeError(errMessage, utils.ObjectNotFound)
+}
+
 func ErrClusterUnmodFields(field string) error {
 	errMessage := fmt.Sprintf("cluster field '%s' can't be modified", field)
 	return rest.MakeError(errMessage, utils.ObjectUnmodified)
 }
 
+func ErrClusterServiceDependent(serviceType string, dependentType string) error {
+	errMessage := fmt.Sprintf("service '%s' can't be removed: service '%s' depends on it", serviceType, dependentType)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterServiceRemoveNodes(serviceType string, role string) error {
+	errMessage := fmt.Sprintf("service '%s' can't be removed: cluster %s node is created for it", serviceType, role)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceUpgradeVersion(serviceType string, oldVersion string, newVersion string) error {
+	errMessage := fmt.Sprintf("service '%s' can't be upgraded from version %s to version %s which is not newer",
+		serviceType, oldVersion, newVersion)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrServiceUpgradeDependency(serviceType string, dependencyType string, dependencyVersion string) error {
+	errMessage := fmt.Sprintf("service '%s' requires service '%s' which is not deployed in the cluster",
+		serviceType, dependencyType)
+	if dependencyVersion != "" {
+		errMessage = fmt.Sprintf("service '%s' doesn't support version %s of service '%s'",
+			serviceType, dependencyVersion, dependencyType)
+	}
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterSpecServiceMissing(serviceType string) error {
+	errMessage := fmt.Sprintf("cluster spec must list the deployed service '%s': use the cluster services request to remove it", serviceType)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterServiceVersionUnmod(serviceType string) error {
+	errMessage := fmt.Sprintf("version of the deployed service '%s' can't be modified", serviceType)
+	return rest.MakeError(errMessage, utils.ObjectUnmodified)
+}
+
 // project:
 
 //func ErrProjectFieldIsGenerated(param string) error {
 //	errMessage := fmt.Sprintf("project %s is generated field. It can't be filled in by user", param)
 //	return rest.MakeError(errMessage, utils.ValidationError)
 //}
+
+func ErrClusterTTLMax(maxTTL int32) error {
+	errMessage := fmt.Sprintf("cluster TTL must be set and can't be greater than %d hours allowed by the project", maxTTL)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrProjectQuotaExceeded(resource string, used int32, quota int32) error {
+	errMessage := fmt.Sprintf("project quota of %s is exceeded: %d requested, %d allowed", resource, used, quota)
+	return rest.MakeError(errMessage, utils.QuotaExceeded)
+}
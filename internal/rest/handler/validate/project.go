@@ -51,7 +51,12 @@ func ProjectCreate(db database.Database, project *protobuf.Project) error {
 		return err
 	}
 
-	return nil
+	err = ProjectClusterTTL(project)
+	if err != nil {
+		return err
+	}
+
+	return ProjectQuotas(project)
 }
 
 // ProjectUpdate validates fields of the project structure for correct filling when updating
@@ -99,6 +104,23 @@ func ProjectFieldsDb(db database.Database, project *protobuf.Project) error {
 			return err
 		}
 	}
+	if project.DefaultCloud != "" {
+		_, err := db.ReadCloud(project.DefaultCloud)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProjectClusterTTL checks the default and maximal lease of the project clusters
+func ProjectClusterTTL(project *protobuf.Project) error {
+	if project.DefaultClusterTTL < 0 || project.MaxClusterTTL < 0 {
+		return ErrProjectClusterTTL
+	}
+	if project.MaxClusterTTL > 0 && project.DefaultClusterTTL > project.MaxClusterTTL {
+		return ErrProjectClusterTTL
+	}
 	return nil
 }
 
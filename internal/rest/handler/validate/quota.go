@@ -0,0 +1,56 @@
+package validate
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+)
+
+// ProjectQuotas checks quotas of the project resources
+func ProjectQuotas(project *protobuf.Project) error {
+	if project.MaxClusters < 0 || project.MaxVCPUs < 0 || project.MaxRAM < 0 ||
+		project.MaxDisk < 0 || project.MaxNodes < 0 {
+		return ErrProjectQuotas
+	}
+	return nil
+}
+
+// ClusterQuotas checks that the cluster together with the other project clusters doesn't exceed the project quotas
+func ClusterQuotas(db database.Database, project *protobuf.Project, cluster *protobuf.Cluster) error {
+	if project.MaxClusters == 0 && project.MaxVCPUs == 0 && project.MaxRAM == 0 &&
+		project.MaxDisk == 0 && project.MaxNodes == 0 {
+		return nil
+	}
+
+	used, err := helpfunc.ProjectResources(db, project, cluster.ID)
+	if err != nil {
+		return err
+	}
+	res, err := helpfunc.ClusterResources(db, cluster)
+	if err != nil {
+		return err
+	}
+	helpfunc.AddResources(used, res)
+
+	return ResourceUsage(used, helpfunc.ProjectQuotas(project))
+}
+
+// ResourceUsage checks the used resources against the quotas, quota equal to zero is not limited
+func ResourceUsage(used *protobuf.ResourceUsage, quotas *protobuf.ResourceUsage) error {
+	if quotas.Clusters > 0 && used.Clusters > quotas.Clusters {
+		return ErrProjectQuotaExceeded("clusters", used.Clusters, quotas.Clusters)
+	}
+	if quotas.VCPUs > 0 && used.VCPUs > quotas.VCPUs {
+		return ErrProjectQuotaExceeded("VCPUs", used.VCPUs, quotas.VCPUs)
+	}
+	if quotas.RAM > 0 && used.RAM > quotas.RAM {
+		return ErrProjectQuotaExceeded("RAM", used.RAM, quotas.RAM)
+	}
+	if quotas.Disk > 0 && used.Disk > quotas.Disk {
+		return ErrProjectQuotaExceeded("Disk", used.Disk, quotas.Disk)
+	}
+	if quotas.Nodes > 0 && used.Nodes > quotas.Nodes {
+		return ErrProjectQuotaExceeded("nodes", used.Nodes, quotas.Nodes)
+	}
+	return nil
+}
@@ -42,6 +42,18 @@ func ServiceTypeCreate(db database.Database, sType *protobuf.ServiceType) error
 		return err
 	}
 
+	// check service type actions
+	err = check.ServiceTypeActions(sType.Actions)
+	if err != nil {
+		return err
+	}
+
+	// check cluster actions supported by the service type
+	err = check.ServiceTypeSupportedActions(sType.SupportedActions)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -81,6 +93,20 @@ func ServiceTypeUpdate(oldServiceType *protobuf.ServiceType, newServiceType *pro
 			return err
 		}
 	}
+
+	if newServiceType.Actions != nil {
+		err := check.ServiceTypeActions(newServiceType.Actions)
+		if err != nil {
+			return err
+		}
+	}
+
+	if newServiceType.SupportedActions != nil {
+		err := check.ServiceTypeSupportedActions(newServiceType.SupportedActions)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,39 @@
+package validate
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"net/url"
+)
+
+// Webhook validates fields of the webhook structure for correct filling when creating
+func Webhook(webhook *protobuf.Webhook) error {
+	if webhook.ID != "" {
+		return ErrGeneratedField("webhook", "ID")
+	}
+	if webhook.ProjectID != "" {
+		return ErrGeneratedField("webhook", "ProjectID")
+	}
+	if webhook.CreatedAt != "" {
+		return ErrGeneratedField("webhook", "CreatedAt")
+	}
+
+	if webhook.URL == "" {
+		return ErrEmptyField("webhook", "URL")
+	}
+	webhookURL, err := url.Parse(webhook.URL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return ErrWebhookURL
+	}
+
+	for _, event := range webhook.Events {
+		switch event {
+		case utils.EventClusterCreated, utils.EventClusterActive, utils.EventClusterFailed,
+			utils.EventClusterDeleting, utils.EventClusterDeleted, utils.EventClusterScaled,
+			utils.EventClusterExpiring:
+		default:
+			return ErrWebhookEvent(event)
+		}
+	}
+	return nil
+}
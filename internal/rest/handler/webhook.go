@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+	proto "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	response "github.com/ispras/michman/internal/rest/response"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+// WebhooksGetList processes a request to get a list of webhooks of the project
+func (hS HttpServer) WebhooksGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	request := "GET /projects/" + projectIdOrName + "/webhooks"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhooks, err := hS.Db.ReadProjectWebhooks(project.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	// secrets are not shown to users
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, webhooks, request)
+}
+
+// WebhookCreate processes a request to subscribe a new webhook to events of the project clusters
+func (hS HttpServer) WebhookCreate(w http.ResponseWriter, r *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	request := "POST /projects/" + projectIdOrName + "/webhooks"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	var webhook proto.Webhook
+	err = json.NewDecoder(r.Body).Decode(&webhook)
+	if err != nil {
+		err = ErrJsonIncorrect
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Validating webhook...")
+	err = validate.Webhook(&webhook)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	// generating UUID for new webhook
+	wUuid, err := uuid.NewRandom()
+	if err != nil {
+		err = ErrUuidLibError
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+	webhook.ID = wUuid.String()
+	webhook.ProjectID = project.ID
+	webhook.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	err = hS.Db.WriteWebhook(&webhook)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook.Secret = ""
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusCreated)
+	response.Created(w, &webhook, request)
+}
+
+// WebhookGet processes a request to get the webhook of the project
+func (hS HttpServer) WebhookGet(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	webhookId := params.ByName("webhookId")
+	request := "GET /projects/" + projectIdOrName + "/webhooks/" + webhookId
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook, err := hS.Db.ReadWebhook(webhookId)
+	if err == nil && webhook.ProjectID != project.ID {
+		err = ErrWebhookNotFound(webhookId, projectIdOrName)
+	}
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook.Secret = ""
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, webhook, request)
+}
+
+// WebhookDelete processes a request to delete the webhook of the project together with its deliveries
+func (hS HttpServer) WebhookDelete(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	webhookId := params.ByName("webhookId")
+	request := "DELETE /projects/" + projectIdOrName + "/webhooks/" + webhookId
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook, err := hS.Db.ReadWebhook(webhookId)
+	if err == nil && webhook.ProjectID != project.ID {
+		err = ErrWebhookNotFound(webhookId, projectIdOrName)
+	}
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	err = hS.Db.DeleteWebhook(webhook.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook.Secret = ""
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, webhook, request)
+}
+
+// WebhookDeliveriesGetList processes a request to get a log of the webhook deliveries
+func (hS HttpServer) WebhookDeliveriesGetList(w http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	projectIdOrName := params.ByName("projectIdOrName")
+	webhookId := params.ByName("webhookId")
+	request := "GET /projects/" + projectIdOrName + "/webhooks/" + webhookId + "/deliveries"
+	hS.Logger.Info(request)
+
+	// reading project info from database
+	project, err := hS.Db.ReadProject(projectIdOrName)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	webhook, err := hS.Db.ReadWebhook(webhookId)
+	if err == nil && webhook.ProjectID != project.ID {
+		err = ErrWebhookNotFound(webhookId, projectIdOrName)
+	}
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	deliveries, err := hS.Db.ReadWebhookDeliveries(webhook.ID)
+	if err != nil {
+		hS.Logger.Warn("Request ", request, " failed with an error: ", err.Error())
+		response.Error(w, err)
+		return
+	}
+
+	hS.Logger.Info("Request ", request, " has succeeded with status ", http.StatusOK)
+	response.Ok(w, deliveries, request)
+}
@@ -0,0 +1,252 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultConsulPort = 8500
+	consulChecksPath  = "/v1/health/checks/"
+	consulServiceName = "-service"
+	checkTimeout      = 10 * time.Second
+
+	// suffixes of the HTTP check config parameters, parameters are named after the service type,
+	// e.g. jupyterHealthPath, jupyterHealthPort and jupyterHealthStatus
+	httpPathSuffix   = "HealthPath"
+	httpPortSuffix   = "HealthPort"
+	httpStatusSuffix = "HealthStatus"
+)
+
+// HttpCheck is a GET request of HTTP health check, zero ExpectedStatus means any successful status
+type HttpCheck struct {
+	Address        string
+	ExpectedStatus int
+}
+
+// NetChecker runs HTTP and TCP checks against the service URL and reads results of the other checks
+// from consul agent of the cluster monitoring node, consul agents run the checks on the cluster hosts
+type NetChecker struct {
+	Client     *http.Client
+	Timeout    time.Duration
+	ConsulPort int
+}
+
+// consulCheck is a check returned by consul health API
+type consulCheck struct {
+	Status string `json:"Status"`
+	Output string `json:"Output"`
+}
+
+// Check runs the declared HTTP or TCP check against the service URL, other checks are read from consul
+func (c NetChecker) Check(cluster *protobuf.Cluster, service *protobuf.Service, check *protobuf.ServiceHealthCheck) Result {
+	if check == nil || check.CheckType == utils.CheckTypeNotSupported {
+		return Result{Health: utils.HealthUnknown, Output: "service type declares no health check"}
+	}
+
+	switch check.CheckType {
+	case utils.CheckTypeHTTP:
+		if service.URL != "" {
+			httpCheck, err := MakeHttpCheck(service, check)
+			if err != nil {
+				return Result{Health: utils.HealthUnknown, Output: err.Error()}
+			}
+			return c.CheckHTTP(httpCheck)
+		}
+	case utils.CheckTypeTCP:
+		if HasPort(service.URL) {
+			return c.CheckTCP(service.URL)
+		}
+	}
+
+	consul := ConsulAddress(cluster, c.consulPort())
+	if consul == "" {
+		return Result{Health: utils.HealthUnknown, Output: "cluster has no monitoring node to read consul checks from"}
+	}
+	return c.CheckConsul(consul, service.Type)
+}
+
+// CheckHTTP checks that the service responds to GET request with the expected status
+// or with successful status if no status is expected
+func (c NetChecker) CheckHTTP(check HttpCheck) Result {
+	resp, err := c.client().Get(check.Address)
+	if err != nil {
+		return Result{Health: utils.HealthCritical, Output: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	output := fmt.Sprintf("HTTP GET %s: %s", check.Address, resp.Status)
+	switch {
+	case check.ExpectedStatus != 0:
+		if resp.StatusCode == check.ExpectedStatus {
+			return Result{Health: utils.HealthPassing, Output: output}
+		}
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return Result{Health: utils.HealthPassing, Output: output}
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return Result{Health: utils.HealthWarning, Output: output}
+	}
+	return Result{Health: utils.HealthCritical, Output: output}
+}
+
+// CheckTCP checks that the service accepts TCP connections
+func (c NetChecker) CheckTCP(address string) Result {
+	conn, err := net.DialTimeout("tcp", address, c.timeout())
+	if err != nil {
+		return Result{Health: utils.HealthCritical, Output: err.Error()}
+	}
+	conn.Close()
+	return Result{Health: utils.HealthPassing, Output: fmt.Sprintf("TCP connect %s: Success", address)}
+}
+
+// CheckConsul reads results of the service checks from consul agent
+func (c NetChecker) CheckConsul(address string, serviceType string) Result {
+	resp, err := c.client().Get(address + consulChecksPath + serviceType + consulServiceName)
+	if err != nil {
+		return Result{Health: utils.HealthUnknown, Output: err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{Health: utils.HealthUnknown, Output: "consul responded with status " + resp.Status}
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Result{Health: utils.HealthUnknown, Output: err.Error()}
+	}
+	return ParseConsulChecks(body)
+}
+
+func (c NetChecker) client() *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+	return &http.Client{Timeout: c.timeout()}
+}
+
+func (c NetChecker) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return checkTimeout
+}
+
+func (c NetChecker) consulPort() int {
+	if c.ConsulPort > 0 {
+		return c.ConsulPort
+	}
+	return DefaultConsulPort
+}
+
+// ParseConsulChecks returns the worst result of the service checks from consul health API response
+func ParseConsulChecks(body []byte) Result {
+	var checks []consulCheck
+	if err := json.Unmarshal(body, &checks); err != nil {
+		return Result{Health: utils.HealthUnknown, Output: err.Error()}
+	}
+	if len(checks) == 0 {
+		return Result{Health: utils.HealthUnknown, Output: "no consul checks are registered for the service"}
+	}
+
+	rank := map[string]int{utils.HealthPassing: 1, utils.HealthWarning: 2, utils.HealthCritical: 3}
+	res := Result{Health: utils.HealthUnknown}
+	for _, check := range checks {
+		health := strings.ToUpper(check.Status)
+		if rank[health] > rank[res.Health] {
+			res = Result{Health: health, Output: check.Output}
+		}
+	}
+	return res
+}
+
+// ConsulAddress returns address of consul HTTP API on the cluster monitoring node or empty string if there is no such node
+func ConsulAddress(cluster *protobuf.Cluster, port int) string {
+	for _, node := range cluster.Nodes {
+		if node.Role != utils.NodeRoleMonitoring {
+			continue
+		}
+		ip := node.FloatingIP
+		if ip == "" {
+			ip = node.PrivateIP
+		}
+		if ip == "" {
+			ip = node.IPv6
+		}
+		if ip == "" {
+			return ""
+		}
+		return "http://" + net.JoinHostPort(ip, strconv.Itoa(port))
+	}
+	return ""
+}
+
+// MakeHttpCheck builds the request of the service HTTP check from the path, port and expected status
+// declared in the check configs, values set in the service config override the declared defaults.
+// The service URL is requested as is if the check declares nothing
+func MakeHttpCheck(service *protobuf.Service, check *protobuf.ServiceHealthCheck) (HttpCheck, error) {
+	address, err := url.Parse(HttpAddress(service.URL))
+	if err != nil {
+		return HttpCheck{}, err
+	}
+	res := HttpCheck{}
+	if port := checkConfig(service, check, httpPortSuffix); port != "" {
+		if _, err := strconv.Atoi(port); err != nil {
+			return HttpCheck{}, fmt.Errorf("health check port %s is not a number", port)
+		}
+		address.Host = net.JoinHostPort(address.Hostname(), port)
+	}
+	if path := checkConfig(service, check, httpPathSuffix); path != "" {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		address.Path = path
+		address.RawPath = ""
+	}
+	if status := checkConfig(service, check, httpStatusSuffix); status != "" {
+		if res.ExpectedStatus, err = strconv.Atoi(status); err != nil {
+			return HttpCheck{}, fmt.Errorf("health check status %s is not a number", status)
+		}
+	}
+	res.Address = address.String()
+	return res, nil
+}
+
+// checkConfig returns value of the check config parameter with the name suffix from the service config
+// or its default value, empty string is returned if the check declares no such parameter
+func checkConfig(service *protobuf.Service, check *protobuf.ServiceHealthCheck, suffix string) string {
+	for _, config := range check.Configs {
+		if !strings.HasSuffix(strings.ToLower(config.ParameterName), strings.ToLower(suffix)) {
+			continue
+		}
+		if value, ok := service.Config[config.ParameterName]; ok && value != "" {
+			return value
+		}
+		return config.DefaultValue
+	}
+	return ""
+}
+
+// HttpAddress returns URL of the service with http scheme if the scheme is not set
+func HttpAddress(url string) string {
+	if strings.Contains(url, "://") {
+		return url
+	}
+	return "http://" + url
+}
+
+// HasPort checks if the service URL is an address with port
+func HasPort(url string) bool {
+	if strings.Contains(url, "://") {
+		return false
+	}
+	_, port, err := net.SplitHostPort(url)
+	return err == nil && port != ""
+}
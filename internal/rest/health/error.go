@@ -0,0 +1,12 @@
+package health
+
+import (
+	"fmt"
+	"github.com/ispras/michman/internal/rest"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func ErrClusterStatus(name string, status string) error {
+	errMessage := fmt.Sprintf("cluster %s is in %s status, only active clusters are checked", name, status)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
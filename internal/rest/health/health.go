@@ -0,0 +1,169 @@
+package health
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const DefaultInterval = time.Minute
+
+// Result is the health of the service returned by its health check
+type Result struct {
+	Health string
+	Output string
+}
+
+// Checker runs the health check declared by the service type for the cluster service
+type Checker interface {
+	Check(cluster *protobuf.Cluster, service *protobuf.Service, check *protobuf.ServiceHealthCheck) Result
+}
+
+// Monitor periodically runs health checks of the services of the active clusters
+// and saves health of the services and the clusters
+type Monitor struct {
+	Db       database.Database
+	Checker  Checker
+	Logger   *logrus.Logger
+	Interval time.Duration
+}
+
+// Run checks health of the active clusters until the rest service stops
+func (m Monitor) Run() {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.CheckAll()
+	}
+}
+
+// CheckAll runs one health check of all active clusters
+func (m Monitor) CheckAll() {
+	clusters, err := m.Db.ReadClustersList()
+	if err != nil {
+		m.Logger.Warn(err)
+		return
+	}
+	for i := range clusters {
+		if clusters[i].EntityStatus != utils.StatusActive {
+			continue
+		}
+		if _, err := m.CheckCluster(&clusters[i]); err != nil {
+			m.Logger.Warnf("Health of cluster %s can't be checked: %s", clusters[i].Name, err.Error())
+		}
+	}
+}
+
+// CheckCluster runs health checks of the active cluster services and saves their results,
+// results are not saved if the cluster is changed by an operation while the checks are running
+func (m Monitor) CheckCluster(cluster *protobuf.Cluster) (*protobuf.ClusterHealth, error) {
+	if cluster.EntityStatus != utils.StatusActive {
+		return nil, ErrClusterStatus(cluster.Name, cluster.EntityStatus)
+	}
+	sTypes, err := m.Db.ReadServicesTypesList()
+	if err != nil {
+		return nil, err
+	}
+
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+	results := make(map[string]Result)
+	for _, service := range cluster.Services {
+		results[service.ID] = m.Checker.Check(cluster, service, ServiceHealthCheck(sTypes, service.Type))
+	}
+
+	current, err := m.Db.ReadCluster(cluster.ProjectID, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.EntityStatus != utils.StatusActive {
+		return nil, ErrClusterStatus(current.Name, current.EntityStatus)
+	}
+	ApplyResults(current, results, checkedAt)
+	if current.Health == utils.HealthDegraded && cluster.Health != utils.HealthDegraded {
+		m.Logger.Warnf("Cluster %s is degraded", current.Name)
+	}
+	// only health is saved, so the cluster changed by an operation started meanwhile is not overwritten
+	err = m.Db.UpdateClusterHealth(current)
+	if err != nil {
+		return nil, err
+	}
+	return Report(current), nil
+}
+
+// ServiceHealthCheck returns the health check declared by the service type or nil if there is no such service type
+func ServiceHealthCheck(sTypes []protobuf.ServiceType, serviceType string) *protobuf.ServiceHealthCheck {
+	for i := range sTypes {
+		if sTypes[i].Type == serviceType && len(sTypes[i].HealthCheck) > 0 {
+			return sTypes[i].HealthCheck[0]
+		}
+	}
+	return nil
+}
+
+// ApplyResults sets health of the cluster services from the results of their checks and health of the cluster,
+// the cluster which drifted from its instances or services stays degraded
+func ApplyResults(cluster *protobuf.Cluster, results map[string]Result, checkedAt string) {
+	for _, service := range cluster.Services {
+		result, ok := results[service.ID]
+		if !ok {
+			continue
+		}
+		service.Health = result.Health
+		service.HealthOutput = result.Output
+		service.HealthCheckedAt = checkedAt
+	}
+	cluster.Health = ClusterHealth(cluster.Services)
+	if len(cluster.Drift) > 0 {
+		cluster.Health = utils.HealthDegraded
+	}
+	cluster.HealthCheckedAt = checkedAt
+}
+
+// ClusterHealth returns health of the cluster: degraded if any service check is not passing,
+// healthy if any service check is passing and unknown if no service health is known
+func ClusterHealth(services []*protobuf.Service) string {
+	health := utils.HealthUnknown
+	for _, service := range services {
+		switch service.Health {
+		case utils.HealthCritical, utils.HealthWarning:
+			return utils.HealthDegraded
+		case utils.HealthPassing:
+			health = utils.HealthHealthy
+		}
+	}
+	return health
+}
+
+// Report returns saved health of the cluster and its services
+func Report(cluster *protobuf.Cluster) *protobuf.ClusterHealth {
+	res := &protobuf.ClusterHealth{
+		ClusterID:    cluster.ID,
+		EntityStatus: cluster.EntityStatus,
+		Health:       cluster.Health,
+		CheckedAt:    cluster.HealthCheckedAt,
+		Services:     []*protobuf.ServiceHealth{},
+	}
+	if res.Health == "" {
+		res.Health = utils.HealthUnknown
+	}
+	for _, service := range cluster.Services {
+		health := service.Health
+		if health == "" {
+			health = utils.HealthUnknown
+		}
+		res.Services = append(res.Services, &protobuf.ServiceHealth{
+			ServiceID: service.ID,
+			Type:      service.Type,
+			Health:    health,
+			Output:    service.HealthOutput,
+			CheckedAt: service.HealthCheckedAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,23 @@
+package queue
+
+import (
+	"fmt"
+	"github.com/ispras/michman/internal/rest"
+	"github.com/ispras/michman/internal/utils"
+)
+
+const errUuidLibError = "uuid generation error"
+
+var (
+	ErrUuidLibError = rest.MakeError(errUuidLibError, utils.LibError)
+)
+
+func ErrUnknownAction(action string) error {
+	errMessage := fmt.Sprintf("unknown cluster operation action: %s", action)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrNoActiveOperation(clusterName string) error {
+	errMessage := fmt.Sprintf("cluster %s has no queued or running operation to cancel", clusterName)
+	return rest.MakeError(errMessage, utils.ObjectUnmodified)
+}
@@ -0,0 +1,436 @@
+package queue
+
+import (
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	grpc_client "github.com/ispras/michman/internal/rest/grpc"
+	"github.com/ispras/michman/internal/rest/usage"
+	"github.com/ispras/michman/internal/rest/webhook"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+	"sync"
+	"time"
+)
+
+// Runner sends cluster actions to the launcher service, passes progress of the action
+// to the handler and returns the task status received from the launcher
+type Runner interface {
+	StartClusterCreation(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error)
+	StartClusterDestroying(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error)
+	StartClusterModification(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error)
+	StartClusterScaling(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error)
+	StartClusterAction(c *protobuf.Cluster, action *protobuf.ClusterAction, progress grpc_client.ProgressHandler) (string, error)
+	CancelCluster(c *protobuf.Cluster) (string, error)
+}
+
+// EventNotifier is notified about cluster lifecycle events caused by the operations
+type EventNotifier interface {
+	Notify(event string, cluster *protobuf.Cluster, op *protobuf.Operation)
+}
+
+// cancelRegistry keeps cancel requests of the running operations, so they are not lost
+// when the operation is saved by its background goroutine. It also keeps operations which runs
+// are registered by the launcher, the cancel is sent to the launcher only after that
+type cancelRegistry struct {
+	mutex    sync.Mutex
+	requests map[string]bool
+	attached map[string]bool
+}
+
+var cancelRequests = &cancelRegistry{requests: make(map[string]bool), attached: make(map[string]bool)}
+
+// apply sets cancel request flags of the operation
+func (r *cancelRegistry) apply(op *protobuf.Operation) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cleanup, ok := r.requests[op.ID]
+	if ok {
+		op.CancelRequested = true
+		op.CleanupOnCancel = cleanup
+	}
+}
+
+// add saves cancel request of the operation, returns true if the operation run is already registered by the launcher
+func (r *cancelRegistry) add(opId string, cleanup bool) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.requests[opId] = cleanup
+	return r.attached[opId]
+}
+
+// attach marks the operation run as registered by the launcher, returns true if the operation cancel is requested
+func (r *cancelRegistry) attach(opId string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.attached[opId] = true
+	_, ok := r.requests[opId]
+	return ok
+}
+
+func (r *cancelRegistry) remove(opId string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.requests, opId)
+	delete(r.attached, opId)
+}
+
+// Queue stores cluster operations in database before running them,
+// so they could be resumed after the rest service restart
+type Queue struct {
+	Db       database.Database
+	Runner   Runner
+	Logger   *logrus.Logger
+	Notifier EventNotifier
+}
+
+// Enqueue saves new operation for the cluster requested by the user and starts it in background.
+// Cluster must be already saved in database with the new status.
+func (q Queue) Enqueue(cluster *protobuf.Cluster, action string, ownerId string) (*protobuf.Operation, error) {
+	if action != utils.ActionCreate && action != utils.ActionUpdate && action != utils.ActionDelete &&
+		action != utils.ActionScale {
+		return nil, ErrUnknownAction(action)
+	}
+
+	return q.enqueue(cluster, &protobuf.Operation{Action: action, OwnerID: ownerId})
+}
+
+// EnqueueAction saves new operation running the lifecycle action of the cluster and starts it in background.
+// Cluster must be already saved in database with the new status.
+func (q Queue) EnqueueAction(cluster *protobuf.Cluster, action *protobuf.ClusterAction, ownerId string) (*protobuf.Operation, error) {
+	if action.Action != utils.ActionStop && action.Action != utils.ActionStart &&
+		action.Action != utils.ActionRestartService && action.Action != utils.ActionRebootNode &&
+		action.Action != utils.ActionServiceAction && action.Action != utils.ActionRemoveService &&
+		action.Action != utils.ActionUpgradeService && action.Action != utils.ActionReconfigureService &&
+		action.Action != utils.ActionRepair {
+		return nil, ErrUnknownAction(action.Action)
+	}
+
+	// action parameters are saved, so the operation could be resumed
+	return q.enqueue(cluster, &protobuf.Operation{
+		Action:         action.Action,
+		OwnerID:        ownerId,
+		Service:        action.Service,
+		Node:           action.Node,
+		ServiceAction:  action.ServiceAction,
+		Params:         action.Params,
+		ServiceVersion: action.ServiceVersion,
+		Restart:        action.Restart,
+	})
+}
+
+// enqueue saves the queued operation with the requested action and starts it
+func (q Queue) enqueue(cluster *protobuf.Cluster, op *protobuf.Operation) (*protobuf.Operation, error) {
+	opUuid, err := uuid.NewRandom()
+	if err != nil {
+		return nil, ErrUuidLibError
+	}
+
+	op.ID = opUuid.String()
+	op.ClusterID = cluster.ID
+	op.ProjectID = cluster.ProjectID
+	op.Status = utils.OperationQueued
+	op.Phase = utils.OperationPhaseQueued
+	op.CreatedAt = now()
+
+	err = q.Db.WriteOperation(op)
+	if err != nil {
+		return nil, err
+	}
+	q.notify(webhook.QueuedEvent(op.Action), op, cluster)
+
+	// operation is changed by its goroutine, so its queued copy is returned
+	queued := proto.Clone(op).(*protobuf.Operation)
+	go q.run(op, cluster)
+	return queued, nil
+}
+
+// Resume restarts all operations which were not finished before the rest service stop
+// and fails clusters which are left in transitional statuses without any operation
+func (q Queue) Resume() error {
+	ops, err := q.Db.ReadOperationsList()
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[string]bool)
+	for i := range ops {
+		op := &ops[i]
+		if op.Status != utils.OperationQueued && op.Status != utils.OperationRunning {
+			continue
+		}
+		pending[op.ClusterID] = true
+
+		// operation cancelled before the rest service stop is not restarted
+		if op.CancelRequested {
+			q.Logger.Infof("Operation %s for cluster %s was cancelled, marking it as cancelled", op.ID, op.ClusterID)
+			q.cancelled(op)
+			continue
+		}
+
+		cluster, err := q.Db.ReadCluster(op.ProjectID, op.ClusterID)
+		if err != nil {
+			// cluster which was being deleted has already gone
+			if op.Action == utils.ActionDelete {
+				q.Logger.Infof("Cluster %s of operation %s no longer exists, marking operation as succeeded", op.ClusterID, op.ID)
+				q.finish(op, "", nil)
+			} else {
+				q.Logger.Warnf("Cluster %s of operation %s can't be read: %s", op.ClusterID, op.ID, err.Error())
+				q.finish(op, "", err)
+			}
+			continue
+		}
+
+		q.Logger.Infof("Resuming %s operation %s for cluster %s", op.Action, op.ID, cluster.Name)
+		go q.run(op, cluster)
+	}
+
+	clusters, err := q.Db.ReadClustersList()
+	if err != nil {
+		return err
+	}
+	for i := range clusters {
+		c := &clusters[i]
+		if pending[c.ID] || (c.EntityStatus != utils.StatusInited && c.EntityStatus != utils.StatusStopping) {
+			continue
+		}
+		q.Logger.Warnf("Cluster %s is in %s status without any operation, marking it as failed", c.Name, c.EntityStatus)
+		c.EntityStatus = utils.StatusFailed
+		err = q.Db.UpdateCluster(c)
+		if err != nil {
+			q.Logger.Warn(err)
+		}
+	}
+
+	return nil
+}
+
+// run sends operation to the launcher and saves its result
+func (q Queue) run(op *protobuf.Operation, cluster *protobuf.Cluster) {
+	// operation could be cancelled while it was queued
+	cancelRequests.apply(op)
+	if op.CancelRequested {
+		q.cancelled(op)
+		return
+	}
+
+	op.Status = utils.OperationRunning
+	op.Phase = utils.OperationPhaseLauncher
+	op.StartedAt = now()
+	q.save(op)
+
+	var result string
+	var err error
+	switch op.Action {
+	case utils.ActionCreate:
+		result, err = q.Runner.StartClusterCreation(cluster, q.progress(op, cluster))
+	case utils.ActionUpdate:
+		result, err = q.Runner.StartClusterModification(cluster, q.progress(op, cluster))
+	case utils.ActionDelete:
+		result, err = q.Runner.StartClusterDestroying(cluster, q.progress(op, cluster))
+	case utils.ActionScale:
+		result, err = q.Runner.StartClusterScaling(cluster, q.progress(op, cluster))
+	default:
+		action := &protobuf.ClusterAction{Action: op.Action, Service: op.Service, Node: op.Node,
+			ServiceAction: op.ServiceAction, Params: op.Params, ServiceVersion: op.ServiceVersion,
+			Restart: op.Restart}
+		result, err = q.Runner.StartClusterAction(cluster, action, q.progress(op, cluster))
+	}
+
+	q.finish(op, result, err)
+	q.notify(webhook.FinishedEvent(op), op, cluster)
+}
+
+// progress returns handler which saves launcher progress events into the operation
+// and sends the cancel requested before the launcher has registered the operation run
+func (q Queue) progress(op *protobuf.Operation, cluster *protobuf.Cluster) grpc_client.ProgressHandler {
+	return func(event *protobuf.ProgressEvent) {
+		switch event.Type {
+		case utils.ProgressEventStarted:
+			if cancelRequests.attach(op.ID) {
+				q.cancelRun(op, cluster)
+			}
+			return
+		case utils.ProgressEventPhase:
+			op.Phase = event.Phase
+			op.Play = ""
+			op.Task = ""
+		case utils.ProgressEventPlay:
+			op.Play = event.Play
+			op.Task = ""
+		case utils.ProgressEventTask:
+			op.Task = event.Task
+		case utils.ProgressEventHost, utils.ProgressEventRecap:
+			if op.HostResults == nil {
+				op.HostResults = make(map[string]string)
+			}
+			op.HostResults[event.Host] = event.HostStatus
+			// successful host results are saved together with the next task
+			if event.HostStatus != utils.HostFailed && event.HostStatus != utils.HostUnreachable {
+				return
+			}
+		default:
+			return
+		}
+
+		q.save(op)
+	}
+}
+
+// finish saves final status, launcher result and error of the operation
+func (q Queue) finish(op *protobuf.Operation, result string, runErr error) {
+	op.Status = utils.OperationSucceeded
+	op.Phase = utils.OperationPhaseFinished
+	op.Result = result
+	op.FinishedAt = now()
+	if runErr != nil {
+		op.Status = utils.OperationFailed
+		op.Error = runErr.Error()
+	} else if result == utils.AnsibleCancelled {
+		op.Status = utils.OperationCancelled
+	}
+	q.Logger.Infof("Operation %s for cluster %s finished with status %s", op.ID, op.ClusterID, op.Status)
+
+	q.save(op)
+	cancelRequests.remove(op.ID)
+
+	if op.Status == utils.OperationSucceeded {
+		q.account(op)
+	}
+	if op.Status == utils.OperationCancelled && op.CleanupOnCancel {
+		q.cleanup(op)
+	}
+}
+
+// account updates usage records of the cluster after its successful operation
+func (q Queue) account(op *protobuf.Operation) {
+	var err error
+	if op.Action == utils.ActionDelete {
+		err = usage.Finish(q.Db, op.ClusterID, time.Now())
+	} else {
+		var cluster *protobuf.Cluster
+		cluster, err = q.Db.ReadCluster(op.ProjectID, op.ClusterID)
+		if err == nil {
+			err = usage.Account(q.Db, cluster, time.Now())
+		}
+	}
+	if err != nil {
+		q.Logger.Warnf("Usage of cluster %s can't be accounted: %s", op.ClusterID, err.Error())
+	}
+}
+
+// Cancel requests cancellation of the active operation of the cluster.
+// If cleanup is set, instances created by the cancelled operation are deleted afterwards.
+func (q Queue) Cancel(cluster *protobuf.Cluster, cleanup bool) (*protobuf.Operation, error) {
+	ops, err := q.Db.ReadClusterOperations(cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var op *protobuf.Operation
+	for i := range ops {
+		if ops[i].Status == utils.OperationQueued || ops[i].Status == utils.OperationRunning {
+			op = &ops[i]
+			break
+		}
+	}
+	if op == nil {
+		return nil, ErrNoActiveOperation(cluster.Name)
+	}
+
+	attached := cancelRequests.add(op.ID, cleanup)
+	cancelRequests.apply(op)
+	err = q.Db.UpdateOperation(op)
+	if err != nil {
+		return nil, err
+	}
+
+	// queued operation is cancelled by its goroutine before it is sent to the launcher, operation
+	// which run is not registered by the launcher yet is cancelled as soon as the launcher starts it
+	q.Logger.Infof("Cancelling %s operation %s for cluster %s", op.Action, op.ID, cluster.Name)
+	if !attached {
+		return op, nil
+	}
+	_, err = q.cancelRun(op, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// cancelRun asks the launcher to stop the action of the operation
+func (q Queue) cancelRun(op *protobuf.Operation, cluster *protobuf.Cluster) (string, error) {
+	result, err := q.Runner.CancelCluster(cluster)
+	if err != nil {
+		q.Logger.Warn(err)
+		return "", err
+	}
+	if result == utils.AnsibleNotRunning {
+		// launcher has already finished the action, operation keeps its own result
+		q.Logger.Infof("Action of operation %s is not running in the launcher", op.ID)
+	}
+	return result, nil
+}
+
+// cancelled finishes operation which was cancelled before it reached the launcher
+func (q Queue) cancelled(op *protobuf.Operation) {
+	cluster, err := q.Db.ReadCluster(op.ProjectID, op.ClusterID)
+	if err == nil {
+		cluster.EntityStatus = utils.StatusCancelled
+		err = q.Db.UpdateCluster(cluster)
+	}
+	if err != nil {
+		q.Logger.Warn(err)
+	}
+	q.finish(op, utils.AnsibleCancelled, nil)
+}
+
+// cleanup enqueues deletion of the cluster left by the cancelled operation
+func (q Queue) cleanup(op *protobuf.Operation) {
+	cluster, err := q.Db.ReadCluster(op.ProjectID, op.ClusterID)
+	if err != nil {
+		q.Logger.Warn(err)
+		return
+	}
+
+	q.Logger.Infof("Cleaning up cluster %s after cancelled operation %s", cluster.Name, op.ID)
+	cluster.EntityStatus = utils.StatusStopping
+	err = q.Db.UpdateCluster(cluster)
+	if err != nil {
+		q.Logger.Warn(err)
+		return
+	}
+
+	_, err = q.Enqueue(cluster, utils.ActionDelete, op.OwnerID)
+	if err != nil {
+		q.Logger.Warn(err)
+	}
+}
+
+// save stores operation in database together with its cancel request
+func (q Queue) save(op *protobuf.Operation) {
+	cancelRequests.apply(op)
+	err := q.Db.UpdateOperation(op)
+	if err != nil {
+		q.Logger.Warn(err)
+	}
+}
+
+// notify sends the cluster event to the notifier if it is set. Cluster is read again to get its status
+// after the operation, deleted cluster is passed as it was sent to the launcher
+func (q Queue) notify(event string, op *protobuf.Operation, cluster *protobuf.Cluster) {
+	if q.Notifier == nil || event == "" {
+		return
+	}
+	current, err := q.Db.ReadCluster(cluster.ProjectID, cluster.ID)
+	if err != nil || current == nil || current.ID == "" {
+		current = cluster
+	}
+	q.Notifier.Notify(event, current, op)
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,31 @@
+package reaper
+
+import (
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Notifier warns the cluster owner that the cluster lease expires soon
+type Notifier interface {
+	WarnExpiry(cluster *protobuf.Cluster) error
+}
+
+// EventNotifier is notified about cluster lifecycle events
+type EventNotifier interface {
+	Notify(event string, cluster *protobuf.Cluster, op *protobuf.Operation)
+}
+
+// WebhookNotifier sends expiry warnings as expiring event to the webhooks of the cluster project
+// and writes them to the rest service log, the owner also sees the warning time in ExpiryWarnedAt field of the cluster
+type WebhookNotifier struct {
+	Events EventNotifier
+	Logger *logrus.Logger
+}
+
+func (n WebhookNotifier) WarnExpiry(cluster *protobuf.Cluster) error {
+	n.Logger.Warnf("Cluster %s of owner %s expires at %s, extend its lease to keep the cluster",
+		cluster.Name, cluster.OwnerID, cluster.ExpiresAt)
+	n.Events.Notify(utils.EventClusterExpiring, cluster, nil)
+	return nil
+}
@@ -0,0 +1,150 @@
+package reaper
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	DefaultInterval      = 5 * time.Minute
+	DefaultWarningPeriod = 24 * time.Hour
+)
+
+// OperationQueue starts deletion of the expired clusters
+type OperationQueue interface {
+	Enqueue(cluster *protobuf.Cluster, action string, ownerId string) (*protobuf.Operation, error)
+}
+
+// Reaper periodically checks leases of the clusters, warns owners of the clusters which expire soon
+// and deletes expired clusters through the operation queue
+type Reaper struct {
+	Db            database.Database
+	Queue         OperationQueue
+	Notifier      Notifier
+	Logger        *logrus.Logger
+	Interval      time.Duration
+	WarningPeriod time.Duration
+}
+
+// Run checks leases of the clusters until the rest service stops
+func (r Reaper) Run() {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.Reap()
+	}
+}
+
+// Reap runs one check of all clusters with leases
+func (r Reaper) Reap() {
+	clusters, err := r.Db.ReadClustersList()
+	if err != nil {
+		r.Logger.Warn(err)
+		return
+	}
+	warningPeriod := r.WarningPeriod
+	if warningPeriod <= 0 {
+		warningPeriod = DefaultWarningPeriod
+	}
+
+	now := time.Now()
+	for i := range clusters {
+		cluster := &clusters[i]
+		switch {
+		case Expired(cluster, now):
+			r.deleteCluster(cluster)
+		case NeedsWarning(cluster, now, warningPeriod):
+			r.warnOwner(cluster)
+		}
+	}
+}
+
+// warnOwner notifies the cluster owner about the cluster expiry once per lease
+func (r Reaper) warnOwner(cluster *protobuf.Cluster) {
+	err := r.Notifier.WarnExpiry(cluster)
+	if err != nil {
+		r.Logger.Warnf("Owner of cluster %s can't be warned about its expiry: %s", cluster.Name, err.Error())
+		return
+	}
+	// only the warning time is saved, so the cluster changed meanwhile is not overwritten
+	_, err = r.Db.UpdateClusterExpiryWarning(cluster.ID, cluster.ExpiresAt, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		r.Logger.Warn(err)
+	}
+}
+
+// deleteCluster deletes the expired cluster as it is deleted by the user request;
+// cluster with the running operation is deleted after the operation is finished.
+// Cluster is claimed for the deletion by its status, so it is not deleted if it is changed meanwhile
+func (r Reaper) deleteCluster(cluster *protobuf.Cluster) {
+	if validate.ClusterDelete(cluster) != nil {
+		return
+	}
+
+	claimed, err := r.Db.UpdateClusterStatus(cluster.ID, cluster.EntityStatus, utils.StatusStopping)
+	if err != nil {
+		r.Logger.Warn(err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	// lease could be extended after the clusters were read
+	current, err := r.Db.ReadCluster(cluster.ProjectID, cluster.ID)
+	if err != nil || !Expired(current, time.Now()) {
+		if err != nil {
+			r.Logger.Warn(err)
+		}
+		_, err = r.Db.UpdateClusterStatus(cluster.ID, utils.StatusStopping, cluster.EntityStatus)
+		if err != nil {
+			r.Logger.Warn(err)
+		}
+		return
+	}
+	r.Logger.Infof("Lease of cluster %s expired at %s, deleting the cluster", current.Name, current.ExpiresAt)
+
+	// autoscaling policy of the deleted cluster is not evaluated anymore
+	if _, err = r.Db.ReadAutoscalingPolicy(current.ID); err == nil {
+		err = r.Db.DeleteAutoscalingPolicy(current.ID)
+		if err != nil {
+			r.Logger.Warn(err)
+		}
+	}
+
+	_, err = r.Queue.Enqueue(current, utils.ActionDelete, utils.ReaperOwnerID)
+	if err != nil {
+		r.Logger.Warn(err)
+	}
+}
+
+// Expired checks whether the cluster lease is over
+func Expired(cluster *protobuf.Cluster, t time.Time) bool {
+	expiresAt, ok := expiryTime(cluster)
+	return ok && !t.Before(expiresAt)
+}
+
+// NeedsWarning checks whether the cluster expires within the warning period and its owner was not warned yet
+func NeedsWarning(cluster *protobuf.Cluster, t time.Time, warningPeriod time.Duration) bool {
+	expiresAt, ok := expiryTime(cluster)
+	return ok && cluster.ExpiryWarnedAt == "" && !t.Before(expiresAt.Add(-warningPeriod))
+}
+
+func expiryTime(cluster *protobuf.Cluster) (time.Time, bool) {
+	if cluster.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, cluster.ExpiresAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
@@ -0,0 +1,17 @@
+package reconciler
+
+import (
+	"fmt"
+	"github.com/ispras/michman/internal/rest"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func ErrClusterStatus(name string, status string) error {
+	errMessage := fmt.Sprintf("cluster %s is in %s status, only active and missing clusters are inspected", name, status)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
+
+func ErrClusterChanged(name string) error {
+	errMessage := fmt.Sprintf("cluster %s status was changed during the inspection, the drift is not saved", name)
+	return rest.MakeError(errMessage, utils.ValidationError)
+}
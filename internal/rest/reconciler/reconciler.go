@@ -0,0 +1,140 @@
+package reconciler
+
+import (
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/health"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"strings"
+	"time"
+)
+
+const DefaultInterval = 10 * time.Minute
+
+// Inspector compares the cluster with its instances in the cloud and its services through ansible-service
+type Inspector interface {
+	InspectCluster(c *protobuf.Cluster) (*protobuf.ClusterDrift, error)
+}
+
+// Reconciler periodically inspects the active clusters, marks the clusters which instances are gone as missing
+// and the drifted clusters as degraded. Missing clusters are inspected too and become active if their instances return
+type Reconciler struct {
+	Db        database.Database
+	Inspector Inspector
+	Logger    *logrus.Logger
+	Interval  time.Duration
+}
+
+// Run inspects the clusters until the rest service stops
+func (r Reconciler) Run() {
+	interval := r.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.ReconcileAll()
+	}
+}
+
+// ReconcileAll runs one inspection of all active and missing clusters
+func (r Reconciler) ReconcileAll() {
+	clusters, err := r.Db.ReadClustersList()
+	if err != nil {
+		r.Logger.Warn(err)
+		return
+	}
+	for i := range clusters {
+		if !Inspected(clusters[i].EntityStatus) {
+			continue
+		}
+		if _, err := r.ReconcileCluster(&clusters[i]); err != nil {
+			r.Logger.Warnf("Cluster %s can't be inspected: %s", clusters[i].Name, err.Error())
+		}
+	}
+}
+
+// ReconcileCluster inspects the cluster and saves its drift, drift is not saved
+// if the cluster is changed by an operation while it is inspected
+func (r Reconciler) ReconcileCluster(cluster *protobuf.Cluster) (*protobuf.ClusterDrift, error) {
+	if !Inspected(cluster.EntityStatus) {
+		return nil, ErrClusterStatus(cluster.Name, cluster.EntityStatus)
+	}
+
+	checkedAt := time.Now().UTC().Format(time.RFC3339)
+	drift, err := r.Inspector.InspectCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := r.Db.ReadCluster(cluster.ProjectID, cluster.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current.EntityStatus != cluster.EntityStatus {
+		return nil, ErrClusterStatus(current.Name, current.EntityStatus)
+	}
+	ApplyDrift(current, drift, checkedAt)
+	switch {
+	case current.EntityStatus == utils.StatusMissing && cluster.EntityStatus != utils.StatusMissing:
+		r.Logger.Warnf("Cluster %s is missing", current.Name)
+	case current.EntityStatus == utils.StatusActive && cluster.EntityStatus == utils.StatusMissing:
+		r.Logger.Infof("Instances of missing cluster %s are found, cluster is active", current.Name)
+	}
+	if len(current.Drift) > 0 {
+		r.Logger.Warnf("Cluster %s has drifted: %s", current.Name, strings.Join(current.Drift, "; "))
+	}
+	// only drift is saved and only for the cluster in the inspected status,
+	// so the cluster changed by an operation started meanwhile is not overwritten
+	updated, err := r.Db.UpdateClusterDrift(current, cluster.EntityStatus)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrClusterChanged(current.Name)
+	}
+	return Report(current, drift.Nodes), nil
+}
+
+// Inspected checks if the clusters in the status are inspected by the reconciler
+func Inspected(entityStatus string) bool {
+	return entityStatus == utils.StatusActive || entityStatus == utils.StatusMissing
+}
+
+// ApplyDrift saves the inspection result in the cluster: the cluster without instances becomes missing,
+// the missing cluster with instances becomes active and the drifted cluster becomes degraded
+func ApplyDrift(cluster *protobuf.Cluster, drift *protobuf.ClusterDrift, checkedAt string) {
+	cluster.Drift = drift.Drift
+	cluster.DriftCheckedAt = checkedAt
+	if drift.Missing {
+		cluster.EntityStatus = utils.StatusMissing
+	} else if cluster.EntityStatus == utils.StatusMissing {
+		cluster.EntityStatus = utils.StatusActive
+	}
+	cluster.Health = health.ClusterHealth(cluster.Services)
+	if len(cluster.Drift) > 0 {
+		cluster.Health = utils.HealthDegraded
+	}
+}
+
+// Report returns saved drift of the cluster with its instances found by the inspection
+func Report(cluster *protobuf.Cluster, nodes []*protobuf.Node) *protobuf.ClusterDrift {
+	res := &protobuf.ClusterDrift{
+		ClusterID:    cluster.ID,
+		EntityStatus: cluster.EntityStatus,
+		Health:       cluster.Health,
+		Missing:      cluster.EntityStatus == utils.StatusMissing,
+		Drift:        cluster.Drift,
+		Nodes:        nodes,
+		CheckedAt:    cluster.DriftCheckedAt,
+	}
+	if res.Health == "" {
+		res.Health = utils.HealthUnknown
+	}
+	if res.Drift == nil {
+		res.Drift = []string{}
+	}
+	return res
+}
@@ -6,9 +6,11 @@ import (
 )
 
 const (
-	errJsonEncode = "json encode error"
+	errJsonEncode             = "json encode error"
+	errEventStreamUnsupported = "server-sent events are not supported by the connection"
 )
 
 var (
-	ErrJsonEncode = rest.MakeError(errJsonEncode, utils.JsonError)
+	ErrJsonEncode             = rest.MakeError(errJsonEncode, utils.JsonError)
+	ErrEventStreamUnsupported = rest.MakeError(errEventStreamUnsupported, utils.LibError)
 )
@@ -48,6 +48,7 @@ func init() {
 	ErrorMap[utils.ObjectNotFound] = NotFound
 
 	ErrorMap[utils.AuthorizationError] = Forbidden
+	ErrorMap[utils.QuotaExceeded] = Forbidden
 
 	ErrorMap[utils.JsonError] = BadRequest
 	ErrorMap[utils.ValidationError] = BadRequest
@@ -77,6 +77,14 @@ func Created(w http.ResponseWriter, msgStruct interface{}, requestName string) {
 	w.Header().Set("Content-Type", "application/json")
 }
 
+// Csv (The 200 (OK) status code) sends the table in CSV format as the attached file
+func Csv(w http.ResponseWriter, data []byte, fileName string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+fileName+"\"")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
 // NoContent (The 204 (No Content) status code indicates that the server has successfully fulfilled the request
 // and that there is no additional content to send in the response content.)
 func NoContent(w http.ResponseWriter) {
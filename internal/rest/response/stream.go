@@ -0,0 +1,86 @@
+package response
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// EventStream sends server-sent events to the client
+type EventStream struct {
+	w     io.Writer
+	flush func() error
+	conn  net.Conn
+}
+
+// NewEventStream starts text/event-stream response. If the response writer is buffered
+// by the middleware, the connection is hijacked and the response is written directly to it.
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	if flusher, ok := w.(http.Flusher); ok {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		return &EventStream{w: w, flush: func() error { flusher.Flush(); return nil }}, nil
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, ErrEventStreamUnsupported
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, ErrEventStreamUnsupported
+	}
+	stream := &EventStream{w: rw, flush: rw.Flush, conn: conn}
+	_, err = rw.WriteString("HTTP/1.1 200 OK\r\n" +
+		"Content-Type: text/event-stream\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"Connection: close\r\n\r\n")
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Send sends event with the id and the type, every line of data is sent as a separate data field
+func (s *EventStream) Send(id string, event string, data string) error {
+	w := bufio.NewWriter(s.w)
+	if id != "" {
+		w.WriteString("id: " + id + "\n")
+	}
+	if event != "" {
+		w.WriteString("event: " + event + "\n")
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(data, "\n"), "\n") {
+		w.WriteString("data: " + line + "\n")
+	}
+	w.WriteString("\n")
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Ping sends comment to keep the connection alive and to find out if the client has gone
+func (s *EventStream) Ping() error {
+	if _, err := io.WriteString(s.w, ":\n\n"); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+// Close closes hijacked connection of the stream
+func (s *EventStream) Close() error {
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package usage
+
+import (
+	"github.com/ispras/michman/internal/rest"
+	"github.com/ispras/michman/internal/utils"
+)
+
+const (
+	errUuidLibError = "uuid generation error"
+	errPeriodTime   = "usage period 'from' and 'to' parameters must be dates (YYYY-MM-DD) or times in RFC3339 format"
+	errPeriodOrder  = "usage period 'from' must be earlier than 'to'"
+	errFormat       = "usage report format must be 'json' or 'csv'"
+)
+
+var (
+	ErrUuidLibError = rest.MakeError(errUuidLibError, utils.LibError)
+	ErrPeriodTime   = rest.MakeError(errPeriodTime, utils.ValidationError)
+	ErrPeriodOrder  = rest.MakeError(errPeriodOrder, utils.ValidationError)
+	ErrFormat       = rest.MakeError(errFormat, utils.ValidationError)
+)
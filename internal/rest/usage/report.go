@@ -0,0 +1,188 @@
+package usage
+
+import (
+	"encoding/csv"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	FormatJson = "json"
+	FormatCsv  = "csv"
+
+	dateLayout = "2006-01-02"
+)
+
+// ParsePeriod parses the report period from dates or times in RFC3339 format,
+// by default the period starts at the beginning of the current month and ends now
+func ParsePeriod(from string, to string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := now
+	var err error
+	if from != "" {
+		start, err = parseTime(from)
+		if err != nil {
+			return start, end, err
+		}
+	}
+	if to != "" {
+		end, err = parseTime(to)
+		if err != nil {
+			return start, end, err
+		}
+	}
+	if !start.Before(end) {
+		return start, end, ErrPeriodOrder
+	}
+	return start, end, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t.UTC(), nil
+	}
+	t, err = time.Parse(dateLayout, value)
+	if err != nil {
+		return t, ErrPeriodTime
+	}
+	return t, nil
+}
+
+// Hours returns the number of hours the record nodes were running in the period, open record is running until now
+func Hours(record *protobuf.UsageRecord, from time.Time, to time.Time, now time.Time) float64 {
+	start, err := time.Parse(time.RFC3339, record.StartedAt)
+	if err != nil {
+		return 0
+	}
+	end := now
+	if record.EndedAt != "" {
+		end, err = time.Parse(time.RFC3339, record.EndedAt)
+		if err != nil {
+			return 0
+		}
+	}
+
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
+
+// Report aggregates node-hours, vCPU-hours and RAM-hours of the usage records by projects and flavors
+func Report(records []protobuf.UsageRecord, projectNames map[string]string, from time.Time, to time.Time, now time.Time) *protobuf.UsageReport {
+	report := &protobuf.UsageReport{
+		From: from.Format(time.RFC3339),
+		To:   to.Format(time.RFC3339),
+	}
+
+	items := make(map[string]*protobuf.UsageReportItem)
+	itemClusters := make(map[string]map[string]bool)
+	clusters := make(map[string]bool)
+	for i := range records {
+		record := &records[i]
+		hours := Hours(record, from, to, now)
+		if hours == 0 {
+			continue
+		}
+
+		key := record.ProjectID + "/" + record.Flavor
+		item, ok := items[key]
+		if !ok {
+			item = &protobuf.UsageReportItem{
+				ProjectID:   record.ProjectID,
+				ProjectName: projectNames[record.ProjectID],
+				Flavor:      record.Flavor,
+			}
+			items[key] = item
+			itemClusters[key] = make(map[string]bool)
+		}
+		itemClusters[key][record.ClusterID] = true
+		clusters[record.ClusterID] = true
+
+		nodeHours := hours * float64(record.Nodes)
+		item.NodeHours += nodeHours
+		item.VCPUHours += nodeHours * float64(record.VCPUs)
+		item.RAMHours += nodeHours * float64(record.RAM)
+	}
+
+	for key, item := range items {
+		item.Clusters = int32(len(itemClusters[key]))
+		report.Items = append(report.Items, item)
+		report.NodeHours += item.NodeHours
+		report.VCPUHours += item.VCPUHours
+		report.RAMHours += item.RAMHours
+	}
+	report.Clusters = int32(len(clusters))
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		if report.Items[i].ProjectName != report.Items[j].ProjectName {
+			return report.Items[i].ProjectName < report.Items[j].ProjectName
+		}
+		return report.Items[i].Flavor < report.Items[j].Flavor
+	})
+	return report
+}
+
+// Build reads usage records of the project clusters which ran in the period and aggregates them,
+// usage of all projects is reported if the project is not set
+func Build(db database.Database, projectId string, from time.Time, to time.Time) (*protobuf.UsageReport, error) {
+	records, err := db.ReadUsageRecords(projectId, from.Format(time.RFC3339), to.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	projects, err := db.ReadProjectsList()
+	if err != nil {
+		return nil, err
+	}
+
+	projectNames := make(map[string]string)
+	for i := range projects {
+		projectNames[projects[i].ID] = projects[i].Name
+	}
+	return Report(records, projectNames, from, to, time.Now()), nil
+}
+
+// WriteCsv writes items of the report as CSV table with the header
+func WriteCsv(w io.Writer, report *protobuf.UsageReport) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"ProjectID", "ProjectName", "Flavor", "Clusters", "NodeHours", "VCPUHours", "RAMHours"})
+	if err != nil {
+		return err
+	}
+	for _, item := range report.Items {
+		err = cw.Write([]string{
+			item.ProjectID,
+			item.ProjectName,
+			item.Flavor,
+			strconv.Itoa(int(item.Clusters)),
+			strconv.FormatFloat(item.NodeHours, 'f', 2, 64),
+			strconv.FormatFloat(item.VCPUHours, 'f', 2, 64),
+			strconv.FormatFloat(item.RAMHours, 'f', 2, 64),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CheckFormat checks the requested report format
+func CheckFormat(format string) error {
+	if format != "" && format != FormatJson && format != FormatCsv {
+		return ErrFormat
+	}
+	return nil
+}
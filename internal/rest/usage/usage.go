@@ -0,0 +1,106 @@
+package usage
+
+import (
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/utils"
+	"time"
+)
+
+// Account synchronizes open usage records of the cluster with its nodes: records are started
+// when the cluster becomes active, restarted when its nodes change and finished when it is stopped.
+// Records of the cluster in other statuses are kept, as its instances are not known for sure.
+func Account(db database.Database, cluster *protobuf.Cluster, t time.Time) error {
+	var nodes map[string]int32
+	var err error
+	switch cluster.EntityStatus {
+	case utils.StatusActive:
+		nodes, err = helpfunc.ClusterFlavorNodes(db, cluster)
+		if err != nil {
+			return err
+		}
+	case utils.StatusStopped:
+		// instances of the stopped cluster are not accounted
+	default:
+		return nil
+	}
+
+	records, err := db.ReadClusterOpenUsageRecords(cluster.ID)
+	if err != nil {
+		return err
+	}
+	if SameNodes(records, nodes) {
+		return nil
+	}
+	err = finish(db, records, t)
+	if err != nil {
+		return err
+	}
+
+	for name, number := range nodes {
+		flavor, err := db.ReadFlavor(name)
+		if err != nil {
+			return err
+		}
+		rUuid, err := uuid.NewRandom()
+		if err != nil {
+			return ErrUuidLibError
+		}
+		err = db.WriteUsageRecord(&protobuf.UsageRecord{
+			ID:          rUuid.String(),
+			ProjectID:   cluster.ProjectID,
+			ClusterID:   cluster.ID,
+			ClusterName: cluster.Name,
+			Flavor:      flavor.Name,
+			Nodes:       number,
+			VCPUs:       flavor.VCPUs,
+			RAM:         flavor.RAM,
+			StartedAt:   t.UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finish finishes open usage records of the deleted cluster
+func Finish(db database.Database, clusterId string, t time.Time) error {
+	records, err := db.ReadClusterOpenUsageRecords(clusterId)
+	if err != nil {
+		return err
+	}
+	return finish(db, records, t)
+}
+
+func finish(db database.Database, records []protobuf.UsageRecord, t time.Time) error {
+	for i := range records {
+		records[i].EndedAt = t.UTC().Format(time.RFC3339)
+		err := db.WriteUsageRecord(&records[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SameNodes returns true if the open usage records account exactly the given number of nodes of every flavor
+func SameNodes(records []protobuf.UsageRecord, nodes map[string]int32) bool {
+	accounted := make(map[string]int32)
+	for i := range records {
+		accounted[records[i].Flavor] += records[i].Nodes
+	}
+
+	for name, number := range nodes {
+		if number == 0 {
+			continue
+		}
+		if accounted[name] != number {
+			return false
+		}
+		delete(accounted, name)
+	}
+	return len(accounted) == 0
+}
@@ -0,0 +1,263 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/ispras/michman/internal/database"
+	"github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultRetries    = 3
+	DefaultRetryDelay = 10 * time.Second
+
+	// requestTimeout limits waiting for the webhook response
+	requestTimeout = 10 * time.Second
+	// signaturePrefix names the hash function of the payload signature
+	signaturePrefix = "sha256="
+)
+
+// Dispatcher delivers cluster lifecycle events to the webhooks of the cluster project
+// and saves every delivery with its attempts in the deliveries log. Retries is the number
+// of retries of the failed delivery, 0 disables them
+type Dispatcher struct {
+	Db         database.Database
+	Client     *http.Client
+	Logger     *logrus.Logger
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// Notify delivers the cluster event to the webhooks of the cluster project subscribed to it in background
+func (d Dispatcher) Notify(event string, cluster *protobuf.Cluster, op *protobuf.Operation) {
+	webhooks, err := d.Db.ReadProjectWebhooks(cluster.ProjectID)
+	if err != nil {
+		d.Logger.Warnf("Webhooks of project %s can't be read: %s", cluster.ProjectID, err.Error())
+		return
+	}
+
+	payload, err := json.Marshal(NewEvent(event, cluster, op))
+	if err != nil {
+		d.Logger.Warn(err)
+		return
+	}
+	for i := range webhooks {
+		if !Subscribed(&webhooks[i], event) {
+			continue
+		}
+		deliveryUuid, err := uuid.NewRandom()
+		if err != nil {
+			d.Logger.Warn(err)
+			return
+		}
+		delivery := &protobuf.WebhookDelivery{
+			ID:        deliveryUuid.String(),
+			WebhookID: webhooks[i].ID,
+			Event:     event,
+			ClusterID: cluster.ID,
+			Payload:   string(payload),
+			Status:    utils.DeliveryPending,
+			CreatedAt: now(),
+		}
+		// pending delivery is resumed after the rest service restart
+		d.save(delivery)
+		go d.Deliver(&webhooks[i], delivery)
+	}
+}
+
+// Resume continues the pending deliveries interrupted by the previous shutdown in background,
+// delivery which webhook is deleted or which has no retries left is marked as failed
+func (d Dispatcher) Resume() error {
+	deliveries, err := d.Db.ReadWebhookDeliveriesByStatus(utils.DeliveryPending)
+	if err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook, err := d.Db.ReadWebhook(delivery.WebhookID)
+		if err != nil {
+			d.Logger.Warnf("Webhook %s of delivery %s can't be read: %s", delivery.WebhookID, delivery.ID, err.Error())
+			delivery.Error = err.Error()
+			delivery.Status = utils.DeliveryFailed
+			d.save(delivery)
+			continue
+		}
+		if int(delivery.Attempts) > d.Retries {
+			d.Logger.Warnf("Event %s of cluster %s is not delivered to webhook %s: no retries left",
+				delivery.Event, delivery.ClusterID, webhook.ID)
+			delivery.Status = utils.DeliveryFailed
+			d.save(delivery)
+			continue
+		}
+
+		d.Logger.Infof("Resuming delivery %s of event %s to webhook %s", delivery.ID, delivery.Event, webhook.ID)
+		go d.Deliver(webhook, delivery)
+	}
+	return nil
+}
+
+// Deliver sends the payload of the delivery to the webhook, failed request is retried
+// with the delay doubled after every attempt. Delivery is saved after each attempt,
+// resumed delivery continues from its saved attempts
+func (d Dispatcher) Deliver(webhook *protobuf.Webhook, delivery *protobuf.WebhookDelivery) {
+	delay := d.RetryDelay
+	if delay <= 0 {
+		delay = DefaultRetryDelay
+	}
+	for i := int32(1); i < delivery.Attempts; i++ {
+		delay *= 2
+	}
+
+	for attempt := int(delivery.Attempts) + 1; ; attempt++ {
+		delivery.Attempts = int32(attempt)
+		status, err := d.send(webhook, delivery)
+		delivery.ResponseStatus = int32(status)
+		if err == nil {
+			delivery.Status = utils.DeliveryDelivered
+			delivery.Error = ""
+			delivery.DeliveredAt = now()
+			d.save(delivery)
+			return
+		}
+
+		delivery.Error = err.Error()
+		if attempt > d.Retries {
+			break
+		}
+		d.save(delivery)
+		time.Sleep(delay)
+		delay *= 2
+	}
+
+	d.Logger.Warnf("Event %s of cluster %s is not delivered to webhook %s: %s",
+		delivery.Event, delivery.ClusterID, webhook.ID, delivery.Error)
+	delivery.Status = utils.DeliveryFailed
+	d.save(delivery)
+}
+
+// send posts the signed payload to the webhook URL and returns the response status
+func (d Dispatcher) send(webhook *protobuf.Webhook, delivery *protobuf.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(utils.WebhookEventHeader, delivery.Event)
+	req.Header.Set(utils.WebhookDeliveryHeader, delivery.ID)
+	if webhook.Secret != "" {
+		req.Header.Set(utils.WebhookSignatureHeader, Sign(webhook.Secret, []byte(delivery.Payload)))
+	}
+
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d Dispatcher) save(delivery *protobuf.WebhookDelivery) {
+	if err := d.Db.WriteWebhookDelivery(delivery); err != nil {
+		d.Logger.Warnf("Delivery %s of webhook %s can't be saved: %s", delivery.ID, delivery.WebhookID, err.Error())
+	}
+}
+
+// Sign returns HMAC-SHA256 signature of the payload with the webhook secret in the form of sha256=<hex digest>
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscribed checks if the webhook receives the event, webhook without events filter receives all events
+func Subscribed(webhook *protobuf.Webhook, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NewEvent makes payload of the cluster event caused by the operation
+func NewEvent(event string, cluster *protobuf.Cluster, op *protobuf.Operation) *protobuf.WebhookEvent {
+	res := &protobuf.WebhookEvent{
+		Event:        event,
+		CreatedAt:    now(),
+		ProjectID:    cluster.ProjectID,
+		ClusterID:    cluster.ID,
+		ClusterName:  cluster.Name,
+		EntityStatus: cluster.EntityStatus,
+		NSlaves:      cluster.NSlaves,
+		ExpiresAt:    cluster.ExpiresAt,
+	}
+	if eventUuid, err := uuid.NewRandom(); err == nil {
+		res.ID = eventUuid.String()
+	}
+	// deleted cluster has no status
+	if event == utils.EventClusterDeleted {
+		res.EntityStatus = ""
+	}
+	if op != nil {
+		res.OperationID = op.ID
+		res.Action = op.Action
+		res.Error = op.Error
+	}
+	return res
+}
+
+// QueuedEvent returns the event of the cluster which operation with the action is queued
+// or empty string if the queued action has no event
+func QueuedEvent(action string) string {
+	switch action {
+	case utils.ActionCreate:
+		return utils.EventClusterCreated
+	case utils.ActionDelete:
+		return utils.EventClusterDeleting
+	}
+	return ""
+}
+
+// FinishedEvent returns the event of the cluster which operation is finished
+// or empty string if the finished operation has no event, as stopped or cancelled ones
+func FinishedEvent(op *protobuf.Operation) string {
+	switch op.Status {
+	case utils.OperationFailed:
+		return utils.EventClusterFailed
+	case utils.OperationSucceeded:
+		switch op.Action {
+		case utils.ActionDelete:
+			return utils.EventClusterDeleted
+		case utils.ActionScale:
+			return utils.EventClusterScaled
+		case utils.ActionStop:
+			return ""
+		}
+		return utils.EventClusterActive
+	}
+	return ""
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
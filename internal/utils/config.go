@@ -8,6 +8,9 @@ import (
 )
 
 type Config struct {
+	// Cloud provider
+	CloudProvider string `yaml:"cloud_provider,omitempty"` //openstack or fake, openstack is default
+
 	// Openstack
 	Key            string `yaml:"os_key_name"`
 	VirtualNetwork string `yaml:"virtual_network"`
@@ -31,6 +34,24 @@ type Config struct {
 	LogstashAddr string `yaml:"logstash_addr,omitempty"`  //logstash address if logstash output is used
 	ElasticAddr  string `yaml:"elastic_addr,omitempty"`   //elastic address if logstash output is used
 
+	//Autoscaling
+	AutoscalingInterval int `yaml:"autoscaling_interval,omitempty"` //time in seconds between evaluations of autoscaling policies
+
+	//Clusters expiry
+	ReaperInterval      int `yaml:"reaper_interval,omitempty"`       //time in seconds between checks of clusters expiry
+	ExpiryWarningPeriod int `yaml:"expiry_warning_period,omitempty"` //time in hours before cluster expiry when the owner is warned
+
+	//Health checks
+	HealthCheckInterval int `yaml:"health_check_interval,omitempty"` //time in seconds between health checks of the active clusters
+	ConsulPort          int `yaml:"consul_port,omitempty"`           //port of consul agent on the cluster monitoring node
+
+	//Drift detection
+	ReconcileInterval int `yaml:"reconcile_interval,omitempty"` //time in seconds between inspections of the active clusters
+
+	//Webhooks
+	WebhookRetries    *int `yaml:"webhook_retries,omitempty"`     //number of retries of the failed webhook delivery, 0 disables them
+	WebhookRetryDelay int  `yaml:"webhook_retry_delay,omitempty"` //time in seconds before the first retry, it doubles with every retry
+
 	// Mirror
 	UsePackageMirror bool   `yaml:"use_package_mirror,omitempty"`
 	UsePipMirror     bool   `yaml:"use_pip_mirror,omitempty"`
@@ -134,5 +155,10 @@ func (Cfg *Config) MakeCfg() error {
 	if Cfg.Storage != StorageCouchbase && Cfg.Storage != StorageMySQL {
 		return ErrStorage
 	}
+
+	//check cloud provider type
+	if Cfg.CloudProvider != "" && Cfg.CloudProvider != CloudProviderOpenstack && Cfg.CloudProvider != CloudProviderFake {
+		return ErrCloudProvider
+	}
 	return nil
 }
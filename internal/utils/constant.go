@@ -8,6 +8,10 @@ const (
 	AnsibleFail string = "FAIL"
 	RunFail     string = "RUN_FAIL"
 
+	//statuses for ansible runner cancellation
+	AnsibleCancelled  string = "CANCELLED"
+	AnsibleNotRunning string = "NOT_RUNNING"
+
 	//supported actions for ansible
 	AnsibleLaunch  = "launch"
 	AnsibleDestroy = "destroy"
@@ -26,8 +30,25 @@ const (
 	AnsibleInstancesRole = "ansible/instances.yml"
 	AnsibleServicesRole  = "ansible/services.yml"
 
-	//ansible get ip of any instance with role
-	AnsibleIpRole = "ansible/get_ip.yml"
+	//ansible writes inventory of the cluster instances
+	AnsibleInventoryRole = "ansible/get_inventory.yml"
+
+	//ansible drains services and destroys removed slaves, drain tasks are named after service types
+	AnsibleScaleRole      = "ansible/scale.yml"
+	AnsibleDrainTasksPath = "ansible/roles/drain/tasks"
+
+	//ansible stops or starts cluster instances, reboots node and restarts services of lifecycle actions,
+	//restart playbooks are named after service types
+	AnsiblePowerRole         = "ansible/power.yml"
+	AnsibleRebootNodeRole    = "ansible/reboot-node.yml"
+	AnsibleRestartRolePrefix = "ansible/restart-"
+
+	//ansible uninstalls the removed service, uninstall tasks are named after service types
+	AnsibleRemoveServiceRole = "ansible/remove-service.yml"
+	AnsibleRemoveTasksPath   = "ansible/roles/remove/tasks"
+
+	//playbooks of the service type actions
+	AnsibleActionsPath = "ansible/actions"
 
 	// Docker login secrets keys
 	DockerLoginUlr      = "url"
@@ -62,15 +83,92 @@ const (
 	VaultSshKey = "key_bgt"
 
 	//Entity statuses
-	StatusInited   = "INITED"
-	StatusActive   = "ACTIVE"
-	StatusFailed   = "FAILED"
-	StatusStopping = "STOPPING"
-	StatusMissing  = "MISSING"
+	StatusInited    = "INITED"
+	StatusActive    = "ACTIVE"
+	StatusFailed    = "FAILED"
+	StatusStopping  = "STOPPING"
+	StatusMissing   = "MISSING"
+	StatusCancelled = "CANCELLED"
+	StatusStopped   = "STOPPED"
+
+	//Health of the cluster services returned by their health checks
+	HealthPassing  = "PASSING"
+	HealthWarning  = "WARNING"
+	HealthCritical = "CRITICAL"
+	HealthUnknown  = "UNKNOWN"
+
+	//Health of the cluster
+	HealthHealthy  = "HEALTHY"
+	HealthDegraded = "DEGRADED"
+
+	//Health check types declared by service types
+	CheckTypeScript       = "Script"
+	CheckTypeHTTP         = "HTTP"
+	CheckTypeTCP          = "TCP"
+	CheckTypeTTL          = "TTL"
+	CheckTypeDocker       = "Docker"
+	CheckTypeGRPC         = "gRPC"
+	CheckTypeNotSupported = "NotSupported"
+
+	//Operation statuses
+	OperationQueued    = "QUEUED"
+	OperationRunning   = "RUNNING"
+	OperationSucceeded = "SUCCEEDED"
+	OperationFailed    = "FAILED"
+	OperationCancelled = "CANCELLED"
+
+	//Operation phases
+	OperationPhaseQueued    = "queued"
+	OperationPhaseLauncher  = "launcher"
+	OperationPhaseInstances = "instances"
+	OperationPhaseInventory = "inventory"
+	OperationPhaseServices  = "services"
+	OperationPhaseScale     = "scale"
+	OperationPhaseAction    = "action"
+	OperationPhaseFinished  = "finished"
+
+	//Progress event types sent by launcher
+	ProgressEventStarted = "started" //action is registered by the launcher and could be cancelled
+	ProgressEventPhase   = "phase"
+	ProgressEventPlay    = "play"
+	ProgressEventTask    = "task"
+	ProgressEventHost    = "host"
+	ProgressEventRecap   = "recap"
+	ProgressEventFinish  = "finish"
+
+	//Ansible host results
+	HostOk          = "ok"
+	HostChanged     = "changed"
+	HostSkipping    = "skipping"
+	HostFailed      = "failed"
+	HostUnreachable = "unreachable"
+
+	//Cluster node roles, named after instances metadata groups
+	NodeRoleMaster     = "master"
+	NodeRoleSlaves     = "slaves"
+	NodeRoleStorage    = "storage"
+	NodeRoleMonitoring = "monitoring"
+
+	//Cluster node statuses
+	NodeStatusActive   = "ACTIVE"
+	NodeStatusDown     = "DOWN"
+	NodeStatusFailed   = "FAILED"
+	NodeStatusUnknown  = "UNKNOWN"
+	NodeStatusRemoving = "REMOVING"
+
+	//Response header with ID of the operation started by request
+	OperationIdHeader = "X-Operation-ID"
 
 	//default IDs
 	CommonProjectID string = "None"
 
+	//Cloud provider types
+	CloudProviderOpenstack = "openstack"
+	CloudProviderFake      = "fake"
+
+	//IP of all cluster hosts of the fake cloud provider
+	FakeProviderHostIP = "127.0.0.1"
+
 	//Openstack stein version
 	OsSteinVersion   string = "stein"
 	OsLibertyVersion string = "liberty"
@@ -99,6 +197,50 @@ const (
 	ActionCreate = "create"
 	ActionUpdate = "update"
 	ActionDelete = "delete"
+	ActionScale  = "scale"
+
+	//cluster lifecycle actions and actions declared by service types
+	ActionStop               = "stop"
+	ActionStart              = "start"
+	ActionRestartService     = "restart-service"
+	ActionRebootNode         = "reboot-node"
+	ActionServiceAction      = "service-action"
+	ActionRemoveService      = "remove-service"
+	ActionUpgradeService     = "upgrade-service"
+	ActionReconfigureService = "reconfigure-service"
+	ActionRepair             = "repair"
+
+	//Autoscaling metric sources
+	MetricSourcePrometheus = "prometheus"
+	MetricSourceHttp       = "http"
+
+	//Value of the project quota or lease in the update request resetting it to 0 - not limited
+	ProjectLimitReset = -1
+
+	//Owner of the operations started by the autoscaler
+	AutoscalerOwnerID = "autoscaler"
+
+	//Owner of the operations deleting expired clusters
+	ReaperOwnerID = "reaper"
+
+	//Cluster lifecycle events delivered to webhooks
+	EventClusterCreated  = "created"
+	EventClusterActive   = "active"
+	EventClusterFailed   = "failed"
+	EventClusterDeleting = "deleting"
+	EventClusterDeleted  = "deleted"
+	EventClusterScaled   = "scaled"
+	EventClusterExpiring = "expiring"
+
+	//Webhook delivery statuses
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryFailed    = "FAILED"
+
+	//Headers of the webhook requests
+	WebhookEventHeader     = "X-Michman-Event"
+	WebhookDeliveryHeader  = "X-Michman-Delivery"
+	WebhookSignatureHeader = "X-Michman-Signature"
 
 	//log file names
 	HttpLogFileName     = "http_server.log"
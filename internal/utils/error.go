@@ -18,6 +18,7 @@ const (
 	InputIncorrect     = 1100
 	EnforcerError      = 1200
 	ParseError         = 1300
+	QuotaExceeded      = 1400
 )
 
 const (
@@ -35,6 +36,7 @@ const (
 	errLogsFilePathEmpty       = "'logs_file_path' couldn't be empty"
 	errLogstashOutputParams    = "for logstash logs output config parameters 'logstash_addr' and 'elastic_addr' couldn't be empty"
 	errStorage                 = "for storage config parameter are supported only 'couchbase' or 'mysql' values"
+	errCloudProvider           = "for cloud_provider config parameter are supported only 'openstack' or 'fake' values"
 )
 
 var (
@@ -50,4 +52,5 @@ var (
 	ErrMkdir                   = errors.New(errMkdir)
 	ErrLogstashOutputParams    = errors.New(errLogstashOutputParams)
 	ErrStorage                 = errors.New(errStorage)
+	ErrCloudProvider           = errors.New(errCloudProvider)
 )
@@ -11,6 +11,14 @@ CREATE TABLE `project` (
 	`DefaultSlavesFlavor` varchar(255) NOT NULL,
 	`DefaultStorageFlavor` varchar(255) NOT NULL,
 	`DefaultMonitoringFlavor` varchar(255),
+	`DefaultCloud` varchar(255),
+	`DefaultClusterTTL` int UNSIGNED,
+	`MaxClusterTTL` int UNSIGNED,
+	`MaxClusters` int UNSIGNED,
+	`MaxVCPUs` int UNSIGNED,
+	`MaxRAM` int UNSIGNED,
+	`MaxDisk` int UNSIGNED,
+	`MaxNodes` int UNSIGNED,
 	PRIMARY KEY (`ID`)
 );
 
@@ -32,6 +40,14 @@ CREATE TABLE `cluster` (
 	`SlavesFlavor` varchar(255), 
 	`StorageFlavor` varchar(255), 
 	`MonitoringFlavor` varchar(255),
+	`Cloud` varchar(255),
+	`TTL` int UNSIGNED,
+	`ExpiresAt` varchar(64),
+	`ExpiryWarnedAt` varchar(64),
+	`Health` varchar(32),
+	`HealthCheckedAt` varchar(64),
+	`Drift` json,
+	`DriftCheckedAt` varchar(64),
 	PRIMARY KEY (`ID`)
 );
 
@@ -47,6 +63,133 @@ CREATE TABLE `service` (
 	`Version` varchar(255) NOT NULL,
 	`URL` varchar(255),
 	`Description` TEXT,
+	`Health` varchar(32),
+	`HealthOutput` TEXT,
+	`HealthCheckedAt` varchar(64),
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `node` (
+	`ClusterRef` varchar(255) NOT NULL,
+	`Name` varchar(255) NOT NULL,
+	`Role` varchar(32) NOT NULL,
+	`PrivateIP` varchar(64),
+	`FloatingIP` varchar(64),
+	`IPv6` varchar(64),
+	`Flavor` varchar(255),
+	`Image` varchar(255),
+	`Status` varchar(32),
+	PRIMARY KEY (`ClusterRef`, `Name`)
+);
+
+CREATE TABLE `operation` (
+	`ID` varchar(255),
+	`ClusterID` varchar(255) NOT NULL,
+	`ProjectID` varchar(255) NOT NULL,
+	`Action` varchar(32) NOT NULL,
+	`Status` varchar(32) NOT NULL,
+	`CreatedAt` varchar(64) NOT NULL,
+	`OwnerID` varchar(255),
+	`StartedAt` varchar(64),
+	`FinishedAt` varchar(64),
+	`Phase` varchar(64),
+	`Result` varchar(32),
+	`Error` TEXT,
+	`Play` TEXT,
+	`Task` TEXT,
+	`HostResults` json,
+	`CancelRequested` boolean,
+	`CleanupOnCancel` boolean,
+	`Service` varchar(255),
+	`Node` varchar(255),
+	`ServiceAction` varchar(255),
+	`Params` json,
+	`ServiceVersion` varchar(255),
+	`Restart` boolean,
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `autoscaling_policy` (
+	`ClusterID` varchar(255) NOT NULL,
+	`ProjectID` varchar(255) NOT NULL,
+	`Enabled` boolean NOT NULL,
+	`MinSlaves` int UNSIGNED NOT NULL,
+	`MaxSlaves` int UNSIGNED NOT NULL,
+	`MetricSource` varchar(32) NOT NULL,
+	`MetricURL` TEXT NOT NULL,
+	`MetricQuery` TEXT,
+	`ScaleUpThreshold` double NOT NULL,
+	`ScaleDownThreshold` double NOT NULL,
+	`ScaleStep` int UNSIGNED NOT NULL,
+	`Cooldown` int UNSIGNED NOT NULL,
+	`LastScaledAt` varchar(64),
+	PRIMARY KEY (`ClusterID`)
+);
+
+CREATE TABLE `scaling_decision` (
+	`ID` varchar(255),
+	`ClusterID` varchar(255) NOT NULL,
+	`ProjectID` varchar(255) NOT NULL,
+	`CreatedAt` varchar(64) NOT NULL,
+	`MetricValue` double NOT NULL,
+	`FromSlaves` int UNSIGNED NOT NULL,
+	`ToSlaves` int UNSIGNED NOT NULL,
+	`Reason` TEXT,
+	`OperationID` varchar(255),
+	`Error` TEXT,
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `usage_record` (
+	`ID` varchar(255),
+	`ProjectID` varchar(255) NOT NULL,
+	`ClusterID` varchar(255) NOT NULL,
+	`ClusterName` varchar(255) NOT NULL,
+	`Flavor` varchar(255) NOT NULL,
+	`Nodes` int UNSIGNED NOT NULL,
+	`VCPUs` int UNSIGNED NOT NULL,
+	`RAM` int UNSIGNED NOT NULL,
+	`StartedAt` varchar(64) NOT NULL,
+	`EndedAt` varchar(64),
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `webhook` (
+	`ID` varchar(255),
+	`ProjectID` varchar(255) NOT NULL,
+	`URL` TEXT NOT NULL,
+	`Secret` TEXT,
+	`Events` json,
+	`Description` TEXT,
+	`CreatedAt` varchar(64) NOT NULL,
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `webhook_delivery` (
+	`ID` varchar(255),
+	`WebhookID` varchar(255) NOT NULL,
+	`Event` varchar(32) NOT NULL,
+	`ClusterID` varchar(255) NOT NULL,
+	`Payload` TEXT NOT NULL,
+	`Status` varchar(32) NOT NULL,
+	`Attempts` int UNSIGNED NOT NULL,
+	`ResponseStatus` int,
+	`Error` TEXT,
+	`CreatedAt` varchar(64) NOT NULL,
+	`DeliveredAt` varchar(64),
+	PRIMARY KEY (`ID`)
+);
+
+CREATE TABLE `cloud` (
+	`ID` varchar(255),
+	`Name` varchar(255) NOT NULL UNIQUE,
+	`Type` varchar(32) NOT NULL,
+	`Description` TEXT,
+	`VaultKey` varchar(255),
+	`OsVersion` varchar(32),
+	`VirtualNetwork` varchar(255),
+	`FloatingIP` varchar(255),
+	`KeyName` varchar(255),
 	PRIMARY KEY (`ID`)
 );
 
@@ -87,6 +230,7 @@ CREATE TABLE `service_type` (
 	`DefaultVersion` varchar(255) NOT NULL,
 	`Class` varchar(32) NOT NULL,
 	`AccessPort` varchar(32),
+	`SupportedActions` json,
 	PRIMARY KEY (`ID`)
 );
 
@@ -109,6 +253,7 @@ CREATE TABLE `service_config` (
 	`Description` TEXT,
 	`AnsibleVarName` varchar(255) NOT NULL,
 	`IsList` boolean NOT NULL,
+	`RequiresRestart` boolean,
 	`VersionID` varchar(255) NOT NULL,
 	PRIMARY KEY (`ID`)
 );
@@ -138,6 +283,17 @@ CREATE TABLE `service_port` (
 	PRIMARY KEY (`ID`)
 );
 
+CREATE TABLE `service_action` (
+	`ID` varchar(255),
+	`Name` varchar(255) NOT NULL,
+	`Description` TEXT,
+	`Playbook` varchar(255) NOT NULL,
+	`Parameters` json,
+	`AllowedStatuses` json,
+	`ServiceTypeID` varchar(255) NOT NULL,
+	PRIMARY KEY (`ID`)
+);
+
 CREATE TABLE `flavor`(
 	`ID` varchar(255), 
 	`Name` varchar(255) NOT NULL UNIQUE,
@@ -181,6 +337,10 @@ ALTER TABLE `service` ADD CONSTRAINT `Service_fk1` FOREIGN KEY (`ClusterRef`) RE
 
 ALTER TABLE `template` ADD CONSTRAINT `Template_fk0` FOREIGN KEY (`ProjectID`) REFERENCES `project`(`ID`);
 
+ALTER TABLE `webhook` ADD CONSTRAINT `Webhook_fk0` FOREIGN KEY (`ProjectID`) REFERENCES `project`(`ID`) ON DELETE CASCADE;
+
+ALTER TABLE `webhook_delivery` ADD CONSTRAINT `WebhookDelivery_fk0` FOREIGN KEY (`WebhookID`) REFERENCES `webhook`(`ID`) ON DELETE CASCADE;
+
 ALTER TABLE `service_version` ADD CONSTRAINT `ServiceVersion_fk0` FOREIGN KEY (`ServiceTypeID`) REFERENCES `service_type`(`ID`) ON DELETE CASCADE;
 
 ALTER TABLE `service_config` ADD CONSTRAINT `ServiceConfig_fk0` FOREIGN KEY (`VersionID`) REFERENCES `service_version`(`ID`) ON DELETE CASCADE;
@@ -195,6 +355,8 @@ ALTER TABLE `dependency_to_version` ADD CONSTRAINT `DependencyToVersion_fk1` FOR
 
 ALTER TABLE `service_port` ADD CONSTRAINT `ServicePort_fk0` FOREIGN KEY (`ServiceTypeID`) REFERENCES `service_type`(`ID`) ON DELETE CASCADE;
 
+ALTER TABLE `service_action` ADD CONSTRAINT `ServiceAction_fk0` FOREIGN KEY (`ServiceTypeID`) REFERENCES `service_type`(`ID`) ON DELETE CASCADE;
+
 ALTER TABLE `health_check` ADD CONSTRAINT `HealthCheck_fk0` FOREIGN KEY (`ServiceTypeID`) REFERENCES `service_type`(`ID`) ON DELETE CASCADE;
 
 ALTER TABLE `health_configs` ADD CONSTRAINT `HealthConfig_fk0` FOREIGN KEY (`CheckType`) REFERENCES `health_check`(`ID`) ON DELETE CASCADE;
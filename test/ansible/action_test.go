@@ -0,0 +1,238 @@
+package ansible
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestActionPlaybook(t *testing.T) {
+	playbook, vars, err := ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionStop})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsiblePowerRole || vars["power_state"] != "stop" {
+		t.Errorf("unexpected stop playbook %s with vars %v", playbook, vars)
+	}
+
+	playbook, vars, err = ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionStart})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsiblePowerRole || vars["power_state"] != "start" {
+		t.Errorf("unexpected start playbook %s with vars %v", playbook, vars)
+	}
+
+	playbook, vars, err = ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionRebootNode, Node: "c-slave-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsibleRebootNodeRole || vars["reboot_node"] != "c-slave-1" {
+		t.Errorf("unexpected reboot playbook %s with vars %v", playbook, vars)
+	}
+
+	_, _, err = ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionRestartService, Service: "nonexistent"})
+	if err == nil {
+		t.Error("restart of the service without restart playbook is accepted")
+	}
+
+	_, _, err = ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionRemoveService, Service: "nonexistent"})
+	if err == nil {
+		t.Error("removal of the service without uninstall tasks is accepted")
+	}
+
+	_, _, err = ansible.ActionPlaybook(&protobuf.ClusterAction{Action: "suspend"})
+	if err == nil {
+		t.Error("unknown action is accepted")
+	}
+}
+
+func TestRemoveServicePlaybook(t *testing.T) {
+	// uninstall tasks are searched relative to the michman root directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	playbook, vars, err := ansible.ActionPlaybook(&protobuf.ClusterAction{Action: utils.ActionRemoveService, Service: "spark"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsibleRemoveServiceRole || vars["remove_service"] != "spark" {
+		t.Errorf("unexpected remove playbook %s with vars %v", playbook, vars)
+	}
+}
+
+func TestRemoveClusterService(t *testing.T) {
+	cluster := &protobuf.Cluster{Services: []*protobuf.Service{{Type: "spark"}, {Type: "jupyter"}, {Type: "redis"}}}
+	ansible.RemoveClusterService(cluster, "jupyter")
+	if len(cluster.Services) != 2 || cluster.Services[0].Type != "spark" || cluster.Services[1].Type != "redis" {
+		t.Errorf("unexpected services after removal: %v", cluster.Services)
+	}
+}
+
+func TestServiceActionPlaybook(t *testing.T) {
+	// action playbooks are searched relative to the michman root directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	sType := &protobuf.ServiceType{
+		Type: "ganglia",
+		Actions: []*protobuf.ServiceAction{
+			{
+				Name:     "restart-monitors",
+				Playbook: "ganglia.yml",
+				Parameters: []*protobuf.ServiceConfig{
+					{ParameterName: "workers", Type: "int", DefaultValue: "2", AnsibleVarName: "ganglia_workers"},
+					{ParameterName: "hosts", Type: "string", IsList: true},
+					{ParameterName: "force", Type: "bool"},
+				},
+			},
+			{Name: "missing", Playbook: "missing.yml"},
+		},
+	}
+	aL := ansible.LauncherServer{}
+
+	playbook, vars, err := aL.ServiceActionPlaybook(sType, &protobuf.ClusterAction{
+		ServiceAction: "restart-monitors",
+		Params:        map[string]string{"hosts": `["master", "slave-1"]`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != filepath.Join(utils.AnsibleActionsPath, "ganglia.yml") {
+		t.Errorf("unexpected action playbook %s", playbook)
+	}
+	if vars["ganglia_workers"] != int64(2) {
+		t.Errorf("default value of the parameter is not set: %v", vars)
+	}
+	if hosts, ok := vars["hosts"].([]string); !ok || len(hosts) != 2 {
+		t.Errorf("list parameter is not converted: %v", vars)
+	}
+	if _, ok := vars["force"]; ok {
+		t.Errorf("parameter without value and default value is set: %v", vars)
+	}
+
+	_, _, err = aL.ServiceActionPlaybook(sType, &protobuf.ClusterAction{
+		ServiceAction: "restart-monitors",
+		Params:        map[string]string{"workers": "many"},
+	})
+	if err == nil {
+		t.Error("parameter value of the wrong type is accepted")
+	}
+
+	_, _, err = aL.ServiceActionPlaybook(sType, &protobuf.ClusterAction{ServiceAction: "missing"})
+	if err == nil {
+		t.Error("action without playbook is accepted")
+	}
+
+	_, _, err = aL.ServiceActionPlaybook(sType, &protobuf.ClusterAction{ServiceAction: "unknown"})
+	if err == nil {
+		t.Error("undeclared action is accepted")
+	}
+}
+
+func TestUpgradedServices(t *testing.T) {
+	services := []*protobuf.Service{
+		{ID: "1", Type: "spark", Version: "2.4.7", Config: map[string]string{"use-yarn": "true"}},
+		{ID: "2", Type: "jupyter", Version: "6.4.0"},
+	}
+	action := &protobuf.ClusterAction{
+		Action:         utils.ActionUpgradeService,
+		Service:        "spark",
+		ServiceVersion: "3.1.2",
+		Params:         map[string]string{"worker-memory": "4g"},
+	}
+
+	res := ansible.UpgradedServices(services, action)
+	if res[0].ID != "1" || res[0].Version != "3.1.2" || res[0].Config["worker-memory"] != "4g" {
+		t.Errorf("unexpected upgraded service %v", res[0])
+	}
+	if res[1] != services[1] {
+		t.Errorf("service which is not upgraded is changed: %v", res[1])
+	}
+	if services[0].Version != "2.4.7" {
+		t.Error("cluster services are changed before the upgrade")
+	}
+
+	action.Cluster = &protobuf.Cluster{Services: services}
+	playbook, vars, err := ansible.ActionPlaybook(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsibleServicesRole || vars["upgrade_service"] != "spark" || vars["deploy_jupyter"] != false {
+		t.Errorf("unexpected upgrade playbook %s with vars %v", playbook, vars)
+	}
+}
+
+func TestReconfigureServicePlaybook(t *testing.T) {
+	action := &protobuf.ClusterAction{
+		Cluster: &protobuf.Cluster{Services: []*protobuf.Service{
+			{Type: "spark", Version: "3.1.2"},
+			{Type: "jupyter", Version: "6.4.0"},
+		}},
+		Action:  utils.ActionReconfigureService,
+		Service: "spark",
+		Restart: true,
+	}
+
+	playbook, vars, err := ansible.ActionPlaybook(action)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if playbook != utils.AnsibleServicesRole || vars["reconfigure_service"] != "spark" ||
+		vars["restart_service"] != true || vars["deploy_jupyter"] != false {
+		t.Errorf("unexpected reconfigure playbook %s with vars %v", playbook, vars)
+	}
+	if _, ok := vars["deploy_spark"]; ok {
+		t.Errorf("reconfigured service is not deployed: %v", vars)
+	}
+}
+
+func TestSetServicesStatus(t *testing.T) {
+	services := []*protobuf.Service{
+		{Type: "spark", EntityStatus: utils.StatusActive},
+		{Type: "jupyter", EntityStatus: utils.StatusActive},
+	}
+
+	ansible.SetServicesStatus(services, "spark", utils.StatusFailed)
+	if services[0].EntityStatus != utils.StatusFailed || services[1].EntityStatus != utils.StatusActive {
+		t.Errorf("unexpected statuses %s and %s", services[0].EntityStatus, services[1].EntityStatus)
+	}
+
+	ansible.SetServicesStatus(services, "", utils.StatusStopped)
+	if services[0].EntityStatus != utils.StatusStopped || services[1].EntityStatus != utils.StatusStopped {
+		t.Errorf("unexpected statuses %s and %s", services[0].EntityStatus, services[1].EntityStatus)
+	}
+}
+
+func TestFinishServices(t *testing.T) {
+	services := []*protobuf.Service{
+		{Type: "spark", EntityStatus: utils.StatusActive},
+		{Type: "jupyter", EntityStatus: utils.StatusInited},
+	}
+
+	ansible.FinishServices(services, false)
+	if services[0].EntityStatus != utils.StatusActive || services[1].EntityStatus != utils.StatusFailed {
+		t.Errorf("unexpected statuses after failed run %s and %s", services[0].EntityStatus, services[1].EntityStatus)
+	}
+
+	ansible.FinishServices(services, true)
+	if services[0].EntityStatus != utils.StatusActive || services[1].EntityStatus != utils.StatusActive {
+		t.Errorf("unexpected statuses after successful run %s and %s", services[0].EntityStatus, services[1].EntityStatus)
+	}
+}
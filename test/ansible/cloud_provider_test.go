@@ -0,0 +1,172 @@
+package ansible
+
+import (
+	"context"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestNewCloudProvider(t *testing.T) {
+	for providerType, expected := range map[string]string{
+		"":                           utils.CloudProviderOpenstack,
+		utils.CloudProviderOpenstack: utils.CloudProviderOpenstack,
+		utils.CloudProviderFake:      utils.CloudProviderFake,
+	} {
+		provider, err := ansible.NewCloudProvider(utils.Config{CloudProvider: providerType}, nil)
+		if err != nil {
+			t.Fatalf("unexpected error for %q provider: %v", providerType, err)
+		}
+		if provider.Type() != expected {
+			t.Errorf("expected %s provider for %q, got %s", expected, providerType, provider.Type())
+		}
+	}
+
+	if _, err := ansible.NewCloudProvider(utils.Config{CloudProvider: "aws"}, nil); err == nil {
+		t.Error("expected error for unsupported provider")
+	}
+}
+
+func TestOpenstackProvider(t *testing.T) {
+	provider := &ansible.OpenstackProvider{
+		Config: utils.Config{OsVersion: utils.OsLibertyVersion, FloatingIP: "public", VirtualNetwork: "net"},
+		Creds: utils.OsCredentials{
+			utils.OsAuthUrl:     "http://keystone:5000",
+			utils.OsProjectName: "michman",
+			utils.OsPassword:    "",
+		},
+	}
+
+	env := provider.Env()
+	sort.Strings(env)
+	expected := []string{"OS_AUTH_URL=http://keystone:5000", "OS_PROJECT_NAME=michman"}
+	if len(env) != len(expected) {
+		t.Fatalf("expected env %v, got %v", expected, env)
+	}
+	for i := range expected {
+		if env[i] != expected[i] {
+			t.Errorf("expected env %v, got %v", expected, env)
+		}
+	}
+
+	vars, err := provider.InstanceVars(&protobuf.Cluster{}, &protobuf.Image{CloudImageID: "image-id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["os_image"] != "image-id" || vars["floating_ip_pool"] != "public" || vars["virtual_network"] != "net" {
+		t.Errorf("unexpected instance vars %v", vars)
+	}
+
+	if provider.Playbook(utils.AnsibleInstancesRole) != utils.AnsibleInstancesRole {
+		t.Error("openstack provider must run instances playbook")
+	}
+}
+
+// serviceTypesDb returns the service types by their type
+type serviceTypesDb struct {
+	database.Database
+	sTypes map[string]*protobuf.ServiceType
+}
+
+func (db serviceTypesDb) ReadServiceType(serviceTypeIdOrName string) (*protobuf.ServiceType, error) {
+	return db.sTypes[serviceTypeIdOrName], nil
+}
+
+func TestFakeProvider(t *testing.T) {
+	db := serviceTypesDb{sTypes: map[string]*protobuf.ServiceType{
+		"spark":     {Type: "spark", Class: utils.ClassMasterSlave},
+		"nextcloud": {Type: "nextcloud", Class: utils.ClassStorage},
+	}}
+	provider, err := ansible.NewCloudProvider(utils.Config{CloudProvider: utils.CloudProviderFake}, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if provider.Playbook(utils.AnsibleServicesRole) != "" {
+		t.Error("fake provider must skip playbooks")
+	}
+
+	run := func(ctx context.Context, args []string, stdout io.Writer) error {
+		t.Error("fake provider must not run playbooks")
+		return nil
+	}
+	tests := []struct {
+		name    string
+		cluster *protobuf.Cluster
+		nodes   []string
+	}{
+		{name: "without services", cluster: &protobuf.Cluster{Name: "test", NSlaves: 2},
+			nodes: []string{"test-master", "test-slave-1", "test-slave-2"}},
+		{name: "master-slave", cluster: &protobuf.Cluster{Name: "test", NSlaves: 1,
+			Services: []*protobuf.Service{{Type: "spark"}}},
+			nodes: []string{"test-master", "test-slave-1"}},
+		{name: "storage only", cluster: &protobuf.Cluster{Name: "test", NSlaves: 2,
+			Services: []*protobuf.Service{{Type: "nextcloud"}}},
+			nodes: []string{"test-storage"}},
+		{name: "storage with monitoring", cluster: &protobuf.Cluster{Name: "test", Monitoring: true,
+			Services: []*protobuf.Service{{Type: "nextcloud"}}},
+			nodes: []string{"test-monitoring", "test-storage"}},
+		{name: "master-slave and storage with monitoring", cluster: &protobuf.Cluster{Name: "test", NSlaves: 1,
+			Monitoring: true, Services: []*protobuf.Service{{Type: "spark"}, {Type: "nextcloud"}}},
+			nodes: []string{"test-master", "test-monitoring", "test-slave-1", "test-storage"}},
+	}
+	for _, test := range tests {
+		nodes, err := provider.DiscoverNodes(context.Background(), test.cluster, run)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+			if node.PrivateIP != utils.FakeProviderHostIP || node.Status != utils.NodeStatusActive {
+				t.Errorf("%s: expected active node with IP %s, got %v", test.name, utils.FakeProviderHostIP, node)
+			}
+		}
+		if !sort.StringsAreSorted(names) || len(names) != len(test.nodes) {
+			t.Errorf("%s: expected nodes %v, got %v", test.name, test.nodes, names)
+			continue
+		}
+		for i := range names {
+			if names[i] != test.nodes[i] {
+				t.Errorf("%s: expected nodes %v, got %v", test.name, test.nodes, names)
+				break
+			}
+		}
+	}
+}
+
+func TestNewClusterCloudProvider(t *testing.T) {
+	config := utils.Config{FloatingIP: "public", VirtualNetwork: "net", Key: "key"}
+	cloud := &protobuf.Cloud{Name: "east", Type: utils.CloudProviderOpenstack, VirtualNetwork: "east-net"}
+
+	provider, err := ansible.NewClusterCloudProvider(config, cloud, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars, err := provider.InstanceVars(&protobuf.Cluster{}, &protobuf.Image{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["virtual_network"] != "east-net" || vars["floating_ip_pool"] != "public" || vars["os_key_name"] != "key" {
+		t.Errorf("unexpected instance vars %v", vars)
+	}
+
+	cloud.Type = utils.CloudProviderFake
+	provider, err = ansible.NewClusterCloudProvider(config, cloud, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if provider.Type() != utils.CloudProviderFake {
+		t.Errorf("expected %s provider, got %s", utils.CloudProviderFake, provider.Type())
+	}
+
+	cloud.Type = "aws"
+	if _, err := ansible.NewClusterCloudProvider(config, cloud, nil); err == nil {
+		t.Error("expected error for unsupported cloud type")
+	}
+}
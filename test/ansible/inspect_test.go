@@ -0,0 +1,81 @@
+package ansible
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestNodesDrift(t *testing.T) {
+	saved := []*protobuf.Node{
+		{Name: "c-master", PrivateIP: "192.168.0.1", FloatingIP: "10.0.0.1", Status: utils.NodeStatusActive},
+		{Name: "c-slave-1", PrivateIP: "192.168.0.2", Status: utils.NodeStatusActive},
+		{Name: "c-slave-2", PrivateIP: "192.168.0.3", Status: utils.NodeStatusActive},
+	}
+
+	tests := []struct {
+		name  string
+		found []*protobuf.Node
+		drift []string
+	}{
+		{
+			name: "no drift",
+			found: []*protobuf.Node{
+				{Name: "c-master", PrivateIP: "192.168.0.1", FloatingIP: "10.0.0.1", Status: utils.NodeStatusActive},
+				{Name: "c-slave-1", PrivateIP: "192.168.0.2", Status: utils.NodeStatusActive},
+				{Name: "c-slave-2", PrivateIP: "192.168.0.3", Status: utils.NodeStatusActive},
+			},
+			drift: nil,
+		},
+		{
+			name: "changed and missing nodes",
+			found: []*protobuf.Node{
+				{Name: "c-master", PrivateIP: "192.168.0.1", FloatingIP: "10.0.0.5", Status: utils.NodeStatusActive},
+				{Name: "c-slave-1", PrivateIP: "192.168.0.2", Status: utils.NodeStatusDown},
+				{Name: "c-slave-3", PrivateIP: "192.168.0.4", Status: utils.NodeStatusActive},
+			},
+			drift: []string{
+				"node c-master floating IP changed from '10.0.0.1' to '10.0.0.5'",
+				"node c-slave-1 is DOWN",
+				"node c-slave-2 is missing",
+				"node c-slave-3 is not saved in the cluster",
+			},
+		},
+	}
+	for _, test := range tests {
+		drift := ansible.NodesDrift(saved, test.found)
+		if !reflect.DeepEqual(drift, test.drift) {
+			t.Errorf("%s: expected drift %v, got %v", test.name, test.drift, drift)
+		}
+	}
+}
+
+func TestServicesDrift(t *testing.T) {
+	services := []*protobuf.Service{
+		{Type: "spark", URL: "10.0.0.1:8080"},
+		{Type: "jupyter", URL: "10.0.0.1:8888"},
+		{Type: "nfs", URL: "10.0.0.1"},
+		{Type: "elastic", URL: ""},
+	}
+	var dialed []string
+	dial := func(address string) error {
+		dialed = append(dialed, address)
+		if address == "10.0.0.1:8888" {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	drift := ansible.ServicesDrift(services, dial)
+	expected := []string{"service jupyter at 10.0.0.1:8888 is unreachable: connection refused"}
+	if !reflect.DeepEqual(drift, expected) {
+		t.Errorf("expected drift %v, got %v", expected, drift)
+	}
+	if !reflect.DeepEqual(dialed, []string{"10.0.0.1:8080", "10.0.0.1:8888"}) {
+		t.Errorf("only services with port must be dialed, dialed %v", dialed)
+	}
+}
@@ -0,0 +1,79 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestParseInventory(t *testing.T) {
+	inventory := []byte(`[
+		{"name": "c-slave-1", "group": "c_slaves", "private_v4": "10.0.0.3", "public_v4": "", "private_v6": "", "public_v6": "", "status": "SHUTOFF"},
+		{"name": "c-master", "group": "c_master", "private_v4": "10.0.0.2", "public_v4": "172.16.0.2", "private_v6": "", "public_v6": "2001:db8::2", "status": "ACTIVE"},
+		{"name": "c-storage", "group": "c_storage", "private_v4": "", "public_v4": "", "private_v6": "fd00::4", "public_v6": "", "status": "ERROR"}
+	]`)
+
+	cluster := &protobuf.Cluster{Name: "c", Image: "ubuntu", MasterFlavor: "large", SlavesFlavor: "medium", StorageFlavor: "small"}
+	nodes, err := ansible.ParseInventory(cluster, inventory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 3 {
+		t.Fatalf("expected 3 nodes, got %d", len(nodes))
+	}
+	if nodes[0].Name != "c-master" || nodes[1].Name != "c-slave-1" || nodes[2].Name != "c-storage" {
+		t.Errorf("nodes are not sorted by name: %v", nodes)
+	}
+
+	master := ansible.FindNode(nodes, utils.NodeRoleMaster)
+	if master == nil || master.PrivateIP != "10.0.0.2" || master.IPv6 != "2001:db8::2" {
+		t.Fatalf("unexpected master node %v", master)
+	}
+	if master.Flavor != "large" || master.Image != "ubuntu" || master.Status != utils.NodeStatusActive {
+		t.Errorf("unexpected master node spec %v", master)
+	}
+	if nodes[1].Flavor != "medium" || nodes[1].Status != utils.NodeStatusDown {
+		t.Errorf("unexpected slave node spec %v", nodes[1])
+	}
+	if nodes[2].Flavor != "small" || nodes[2].Status != utils.NodeStatusFailed {
+		t.Errorf("unexpected storage node spec %v", nodes[2])
+	}
+	if ip := ansible.NodeAccessIP(master); ip != "172.16.0.2" {
+		t.Errorf("expected master to be accessed by floating IP, got %s", ip)
+	}
+	if ip := ansible.NodeAccessIP(ansible.FindNode(nodes, utils.NodeRoleSlaves)); ip != "10.0.0.3" {
+		t.Errorf("expected slave to be accessed by private IP, got %s", ip)
+	}
+	if ip := ansible.NodeAccessIP(ansible.FindNode(nodes, utils.NodeRoleStorage)); ip != "fd00::4" {
+		t.Errorf("expected storage to be accessed by IPv6, got %s", ip)
+	}
+	if ansible.FindNode(nodes, utils.NodeRoleMonitoring) != nil {
+		t.Error("unexpected monitoring node")
+	}
+
+	if _, err := ansible.ParseInventory(&protobuf.Cluster{Name: "c"}, []byte("ok: [localhost]")); err == nil {
+		t.Error("expected error for malformed inventory")
+	}
+}
+
+func TestRemoveNodes(t *testing.T) {
+	cluster := &protobuf.Cluster{
+		NSlaves: 3,
+		Nodes: []*protobuf.Node{
+			{Name: "c-master", Role: utils.NodeRoleMaster, Status: utils.NodeStatusActive},
+			{Name: "c-slave-1", Role: utils.NodeRoleSlaves, Status: utils.NodeStatusActive},
+			{Name: "c-slave-2", Role: utils.NodeRoleSlaves, Status: utils.NodeStatusRemoving},
+			{Name: "c-slave-3", Role: utils.NodeRoleSlaves, Status: utils.NodeStatusRemoving},
+		},
+	}
+
+	ansible.RemoveNodes(cluster)
+	if cluster.NSlaves != 1 {
+		t.Errorf("expected 1 slave, got %d", cluster.NSlaves)
+	}
+	if len(cluster.Nodes) != 2 || cluster.Nodes[1].Name != "c-slave-1" {
+		t.Errorf("unexpected nodes %v", cluster.Nodes)
+	}
+}
@@ -0,0 +1,42 @@
+package ansible
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+)
+
+func TestRedactExtraVars(t *testing.T) {
+	extraVars := ansible.InterfaceMap{
+		"cluster_name":      "test",
+		"os_swift_password": "swift",
+		"vault_token":       "",
+		"docker_logins": [1]map[string]string{
+			{"url": "registry", "user": "docker", "password": "rekcod"},
+		},
+	}
+
+	res, err := ansible.RedactExtraVars(extraVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vars map[string]interface{}
+	if err := json.Unmarshal([]byte(res), &vars); err != nil {
+		t.Fatal(err)
+	}
+
+	if vars["cluster_name"] != "test" {
+		t.Errorf("unexpected cluster_name %v", vars["cluster_name"])
+	}
+	if vars["os_swift_password"] != ansible.RedactedValue {
+		t.Errorf("os_swift_password is not redacted: %v", vars["os_swift_password"])
+	}
+	if vars["vault_token"] != "" {
+		t.Errorf("empty secret must stay empty: %v", vars["vault_token"])
+	}
+	login := vars["docker_logins"].([]interface{})[0].(map[string]interface{})
+	if login["password"] != ansible.RedactedValue || login["user"] != "docker" {
+		t.Errorf("unexpected docker login %v", login)
+	}
+}
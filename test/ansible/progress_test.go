@@ -0,0 +1,74 @@
+package ansible
+
+import (
+	"testing"
+
+	"github.com/ispras/michman/internal/ansible"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/utils"
+)
+
+const ansibleOutput = `PLAY [localhost] ***************************************************************
+
+TASK [create : initialize security group] **************************************
+ok: [localhost]
+
+TASK [create : Create slave instances] *****************************************
+changed: [localhost] => (item=test-slave-1)
+fatal: [test-slave-2]: UNREACHABLE! => {"changed": false, "unreachable": true}
+failed: [localhost] (item=test-slave-3) => {"changed": false}
+
+PLAY RECAP *********************************************************************
+localhost                  : ok=2    changed=1    unreachable=0    failed=1    skipped=0    rescued=0    ignored=0
+test-slave-2               : ok=0    changed=0    unreachable=1    failed=0    skipped=0    rescued=0    ignored=0
+`
+
+func TestProgressWriter(t *testing.T) {
+	var events []*protobuf.ProgressEvent
+	pw := ansible.NewProgressWriter(utils.OperationPhaseInstances, func(event *protobuf.ProgressEvent) {
+		events = append(events, event)
+	})
+
+	// output is written in chunks which do not match lines
+	out := []byte(ansibleOutput)
+	for len(out) > 0 {
+		n := 7
+		if n > len(out) {
+			n = len(out)
+		}
+		if _, err := pw.Write(out[:n]); err != nil {
+			t.Fatal(err)
+		}
+		out = out[n:]
+	}
+
+	type event struct {
+		Type, Play, Task, Host, HostStatus string
+	}
+	expected := []event{
+		{Type: utils.ProgressEventPlay, Play: "localhost"},
+		{Type: utils.ProgressEventTask, Play: "localhost", Task: "create : initialize security group"},
+		{Type: utils.ProgressEventHost, Play: "localhost", Task: "create : initialize security group", Host: "localhost", HostStatus: utils.HostOk},
+		{Type: utils.ProgressEventTask, Play: "localhost", Task: "create : Create slave instances"},
+		{Type: utils.ProgressEventHost, Play: "localhost", Task: "create : Create slave instances", Host: "localhost", HostStatus: utils.HostChanged},
+		{Type: utils.ProgressEventHost, Play: "localhost", Task: "create : Create slave instances", Host: "test-slave-2", HostStatus: utils.HostUnreachable},
+		{Type: utils.ProgressEventHost, Play: "localhost", Task: "create : Create slave instances", Host: "localhost", HostStatus: utils.HostFailed},
+		{Type: utils.ProgressEventRecap, Play: "localhost", Host: "localhost", HostStatus: utils.HostFailed},
+		{Type: utils.ProgressEventRecap, Play: "localhost", Host: "test-slave-2", HostStatus: utils.HostUnreachable},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d", len(expected), len(events))
+	}
+	for i, e := range expected {
+		got := events[i]
+		if got.Phase != utils.OperationPhaseInstances {
+			t.Errorf("event %d: expected phase %s, got %s", i, utils.OperationPhaseInstances, got.Phase)
+		}
+		if got.Type != e.Type || got.Play != e.Play || got.Task != e.Task || got.Host != e.Host || got.HostStatus != e.HostStatus {
+			t.Errorf("event %d: expected %s %q %q %q %q, got %s %q %q %q %q", i,
+				e.Type, e.Play, e.Task, e.Host, e.HostStatus,
+				got.Type, got.Play, got.Task, got.Host, got.HostStatus)
+		}
+	}
+}
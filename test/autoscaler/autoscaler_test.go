@@ -0,0 +1,106 @@
+package autoscaler
+
+import (
+	"testing"
+	"time"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/autoscaler"
+)
+
+func TestDecide(t *testing.T) {
+	policy := &protobuf.AutoscalingPolicy{
+		MinSlaves:          2,
+		MaxSlaves:          5,
+		ScaleUpThreshold:   80,
+		ScaleDownThreshold: 20,
+		ScaleStep:          2,
+	}
+
+	tests := []struct {
+		nSlaves int32
+		value   float64
+		target  int32
+	}{
+		{nSlaves: 3, value: 50, target: 3},
+		{nSlaves: 2, value: 90, target: 4},
+		{nSlaves: 4, value: 80, target: 5},
+		{nSlaves: 5, value: 95, target: 5},
+		{nSlaves: 5, value: 10, target: 3},
+		{nSlaves: 3, value: 20, target: 2},
+		{nSlaves: 2, value: 0, target: 2},
+		{nSlaves: 1, value: 50, target: 2},
+		{nSlaves: 7, value: 90, target: 5},
+	}
+	for _, test := range tests {
+		target, reason := autoscaler.Decide(policy, test.nSlaves, test.value)
+		if target != test.target {
+			t.Errorf("%d slaves with metric %g: expected %d slaves, got %d", test.nSlaves, test.value, test.target, target)
+		}
+		if (target != test.nSlaves) != (reason != "") {
+			t.Errorf("%d slaves with metric %g: unexpected reason %q", test.nSlaves, test.value, reason)
+		}
+	}
+}
+
+func TestInCooldown(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy := &protobuf.AutoscalingPolicy{Cooldown: 300}
+	if autoscaler.InCooldown(policy, now) {
+		t.Error("policy which never scaled is in cooldown")
+	}
+
+	policy.LastScaledAt = now.Add(-time.Minute).Format(time.RFC3339)
+	if !autoscaler.InCooldown(policy, now) {
+		t.Error("policy scaled a minute ago is not in cooldown")
+	}
+
+	policy.LastScaledAt = now.Add(-10 * time.Minute).Format(time.RFC3339)
+	if autoscaler.InCooldown(policy, now) {
+		t.Error("policy scaled ten minutes ago is in cooldown")
+	}
+}
+
+func TestParsePrometheusValue(t *testing.T) {
+	vector := []byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1672574400.1,"73.5"]}]}}`)
+	value, err := autoscaler.ParsePrometheusValue(vector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 73.5 {
+		t.Errorf("expected 73.5, got %g", value)
+	}
+
+	scalar := []byte(`{"status":"success","data":{"resultType":"scalar","result":[1672574400.1,"4"]}}`)
+	value, err = autoscaler.ParsePrometheusValue(scalar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 4 {
+		t.Errorf("expected 4, got %g", value)
+	}
+
+	multiple := []byte(`{"status":"success","data":{"resultType":"vector","result":[{"value":[1,"1"]},{"value":[1,"2"]}]}}`)
+	if _, err = autoscaler.ParsePrometheusValue(multiple); err == nil {
+		t.Error("vector with several samples is parsed")
+	}
+
+	failed := []byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`)
+	if _, err = autoscaler.ParsePrometheusValue(failed); err == nil {
+		t.Error("failed query is parsed")
+	}
+}
+
+func TestParseHttpValue(t *testing.T) {
+	value, err := autoscaler.ParseHttpValue([]byte("0.75\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 0.75 {
+		t.Errorf("expected 0.75, got %g", value)
+	}
+
+	if _, err = autoscaler.ParseHttpValue([]byte("busy")); err == nil {
+		t.Error("non-numeric value is parsed")
+	}
+}
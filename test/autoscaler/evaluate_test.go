@@ -0,0 +1,165 @@
+package autoscaler
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/autoscaler"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// scalingDb keeps one cluster with its autoscaling policy, changed is applied to the saved cluster
+// after it is read by the autoscaler for the first time, as by a concurrent request
+type scalingDb struct {
+	database.Database
+	cluster   *protobuf.Cluster
+	policy    *protobuf.AutoscalingPolicy
+	changed   func(cluster *protobuf.Cluster)
+	reads     int
+	decisions []*protobuf.ScalingDecision
+}
+
+func (db *scalingDb) ReadAutoscalingPoliciesList() ([]protobuf.AutoscalingPolicy, error) {
+	return []protobuf.AutoscalingPolicy{{ClusterID: db.policy.ClusterID, ProjectID: db.policy.ProjectID,
+		Enabled: db.policy.Enabled, MinSlaves: db.policy.MinSlaves, MaxSlaves: db.policy.MaxSlaves,
+		ScaleUpThreshold: db.policy.ScaleUpThreshold, ScaleDownThreshold: db.policy.ScaleDownThreshold}}, nil
+}
+
+func (db *scalingDb) ReadAutoscalingPolicy(clusterId string) (*protobuf.AutoscalingPolicy, error) {
+	return proto.Clone(db.policy).(*protobuf.AutoscalingPolicy), nil
+}
+
+func (db *scalingDb) WriteAutoscalingPolicy(policy *protobuf.AutoscalingPolicy) error {
+	db.policy = policy
+	return nil
+}
+
+func (db *scalingDb) WriteScalingDecision(decision *protobuf.ScalingDecision) error {
+	db.decisions = append(db.decisions, decision)
+	return nil
+}
+
+func (db *scalingDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	res := proto.Clone(db.cluster).(*protobuf.Cluster)
+	db.reads++
+	if db.reads == 1 && db.changed != nil {
+		db.changed(db.cluster)
+	}
+	return res, nil
+}
+
+func (db *scalingDb) UpdateCluster(cluster *protobuf.Cluster) error {
+	db.cluster = proto.Clone(cluster).(*protobuf.Cluster)
+	return nil
+}
+
+func (db *scalingDb) UpdateClusterStatus(clusterId string, fromStatus string, toStatus string) (bool, error) {
+	if db.cluster.EntityStatus != fromStatus {
+		return false, nil
+	}
+	db.cluster.EntityStatus = toStatus
+	return true, nil
+}
+
+type fixedMetric float64
+
+func (m fixedMetric) Read(_ *protobuf.AutoscalingPolicy) (float64, error) {
+	return float64(m), nil
+}
+
+type recordingQueue struct {
+	actions []string
+}
+
+func (q *recordingQueue) Enqueue(cluster *protobuf.Cluster, action string, ownerId string) (*protobuf.Operation, error) {
+	q.actions = append(q.actions, action)
+	return &protobuf.Operation{ID: "op-id", ClusterID: cluster.ID, Action: action}, nil
+}
+
+func scalingCluster() *protobuf.Cluster {
+	return &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusActive,
+		NSlaves: 2, Nodes: []*protobuf.Node{
+			{Name: "c-project-slave-1", Role: utils.NodeRoleSlaves, Status: utils.NodeStatusActive},
+			{Name: "c-project-slave-2", Role: utils.NodeRoleSlaves, Status: utils.NodeStatusActive},
+		}}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed func(cluster *protobuf.Cluster)
+		actions int
+		status  string
+		nSlaves int32
+	}{
+		{
+			name:    "idle cluster is scaled down",
+			actions: 1,
+			status:  utils.StatusInited,
+			nSlaves: 2,
+		},
+		{
+			name: "cluster with the started operation",
+			changed: func(cluster *protobuf.Cluster) {
+				cluster.EntityStatus = utils.StatusInited
+				cluster.NSlaves = 4
+			},
+			actions: 0,
+			status:  utils.StatusInited,
+			nSlaves: 4,
+		},
+		{
+			name: "cluster scaled by the finished operation",
+			changed: func(cluster *protobuf.Cluster) {
+				cluster.NSlaves = 3
+			},
+			actions: 0,
+			status:  utils.StatusActive,
+			nSlaves: 3,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	for _, test := range tests {
+		db := &scalingDb{
+			cluster: scalingCluster(),
+			policy: &protobuf.AutoscalingPolicy{ClusterID: "c-id", ProjectID: "p-id", Enabled: true,
+				MinSlaves: 1, MaxSlaves: 5, ScaleUpThreshold: 80, ScaleDownThreshold: 20},
+			changed: test.changed,
+		}
+		queue := &recordingQueue{}
+		a := autoscaler.Autoscaler{Db: db, Queue: queue, Metrics: fixedMetric(5), Logger: logger}
+		a.Evaluate()
+
+		if len(queue.actions) != test.actions {
+			t.Errorf("%s: expected %d enqueued operations, got %v", test.name, test.actions, queue.actions)
+		}
+		if db.cluster.EntityStatus != test.status || db.cluster.NSlaves != test.nSlaves {
+			t.Errorf("%s: expected saved cluster in status %s with %d slaves, got %s with %d slaves",
+				test.name, test.status, test.nSlaves, db.cluster.EntityStatus, db.cluster.NSlaves)
+		}
+		if len(db.decisions) != 1 {
+			t.Fatalf("%s: expected one scaling decision, got %d", test.name, len(db.decisions))
+		}
+		if (test.actions == 0) != (db.decisions[0].Error != "") {
+			t.Errorf("%s: unexpected decision error '%s'", test.name, db.decisions[0].Error)
+		}
+	}
+
+	// scaled down cluster keeps the number of slaves until the removed node is deleted
+	db := &scalingDb{cluster: scalingCluster(), policy: &protobuf.AutoscalingPolicy{ClusterID: "c-id", ProjectID: "p-id",
+		Enabled: true, MinSlaves: 1, MaxSlaves: 5, ScaleUpThreshold: 80, ScaleDownThreshold: 20}}
+	queue := &recordingQueue{}
+	autoscaler.Autoscaler{Db: db, Queue: queue, Metrics: fixedMetric(5), Logger: logger}.Evaluate()
+	if len(queue.actions) != 1 || queue.actions[0] != utils.ActionScale {
+		t.Fatalf("expected scale operation, got %v", queue.actions)
+	}
+	if db.cluster.Nodes[1].Status != utils.NodeStatusRemoving {
+		t.Errorf("expected the last slave marked for removal, got %s", db.cluster.Nodes[1].Status)
+	}
+}
@@ -0,0 +1,59 @@
+package helpfunc
+
+import (
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// serviceTypesDb returns the service types by their type
+type serviceTypesDb struct {
+	database.Database
+	sTypes map[string]*protobuf.ServiceType
+}
+
+func (db serviceTypesDb) ReadServiceType(serviceTypeIdOrName string) (*protobuf.ServiceType, error) {
+	return db.sTypes[serviceTypeIdOrName], nil
+}
+
+func TestClusterActionSupported(t *testing.T) {
+	db := serviceTypesDb{sTypes: map[string]*protobuf.ServiceType{
+		"spark":   {Type: "spark", SupportedActions: []string{utils.ActionRestartService}},
+		"jupyter": {Type: "jupyter"},
+	}}
+	cluster := &protobuf.Cluster{EntityStatus: utils.StatusActive,
+		Services: []*protobuf.Service{{Type: "spark"}, {Type: "jupyter"}}}
+
+	tests := []struct {
+		service string
+		valid   bool
+	}{
+		{service: "spark", valid: true},
+		{service: "jupyter", valid: false},
+		{service: "redis", valid: false},
+	}
+	for _, test := range tests {
+		action := &protobuf.ClusterAction{Action: utils.ActionRestartService, Service: test.service}
+		err := validate.ClusterAction(db, cluster, action)
+		if (err == nil) != test.valid {
+			t.Errorf("restart of %s: expected valid %v, got error %v", test.service, test.valid, err)
+		}
+	}
+}
+
+func TestClusterServiceRemoveSupported(t *testing.T) {
+	db := serviceTypesDb{sTypes: map[string]*protobuf.ServiceType{
+		"jupyter": {Type: "jupyter"},
+	}}
+	cluster := &protobuf.Cluster{EntityStatus: utils.StatusActive, Services: []*protobuf.Service{{Type: "jupyter"}}}
+
+	if err := validate.ClusterServiceRemove(db, cluster, "jupyter"); err == nil {
+		t.Error("removal of the service which type doesn't support it must be rejected")
+	}
+	if cluster.EntityStatus != utils.StatusActive {
+		t.Errorf("cluster status must not be changed, got %s", cluster.EntityStatus)
+	}
+}
@@ -27,3 +27,13 @@ func BenchmarkMergeSlices(b *testing.B) {
 	b.ResetTimer()
 	helpfunc.MergeSlices(Ports1, Ports2, "Port")
 }
+
+func TestMergeSlices(t *testing.T) {
+	newPorts := []*protobuf.ServicePort{{Port: 80, Description: "new"}, {Port: 443}}
+	oldPorts := []*protobuf.ServicePort{{Port: 80, Description: "old"}, {Port: 8080}}
+
+	res := helpfunc.MergeSlices(newPorts, oldPorts, "Port").([]*protobuf.ServicePort)
+	if len(res) != 3 || res[0].Description != "new" || res[1].Port != 443 || res[2].Port != 8080 {
+		t.Errorf("unexpected merged ports %v", res)
+	}
+}
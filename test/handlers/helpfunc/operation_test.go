@@ -0,0 +1,63 @@
+package helpfunc
+
+import (
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+)
+
+const (
+	projectId      = "5b3d0fb5-3b0e-4a4b-9c41-6fd2ab0f0001"
+	otherProjectId = "5b3d0fb5-3b0e-4a4b-9c41-6fd2ab0f0002"
+	clusterId      = "5b3d0fb5-3b0e-4a4b-9c41-6fd2ab0f0003"
+	deletedId      = "5b3d0fb5-3b0e-4a4b-9c41-6fd2ab0f0004"
+	foreignId      = "5b3d0fb5-3b0e-4a4b-9c41-6fd2ab0f0005"
+)
+
+// operationsDb keeps one existing cluster and operations of the deleted clusters
+type operationsDb struct {
+	database.Database
+}
+
+func (db operationsDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	if projectIdOrName == projectId && (clusterIdOrName == clusterId || clusterIdOrName == "cluster-project") {
+		return &protobuf.Cluster{ID: clusterId, ProjectID: projectId}, nil
+	}
+	return nil, database.ErrObjectNotFound("cluster", clusterIdOrName)
+}
+
+func (db operationsDb) ReadClusterOperations(clusterId string) ([]protobuf.Operation, error) {
+	switch clusterId {
+	case deletedId:
+		return []protobuf.Operation{{ClusterID: deletedId, ProjectID: projectId}}, nil
+	case foreignId:
+		return []protobuf.Operation{{ClusterID: foreignId, ProjectID: otherProjectId}}, nil
+	}
+	return nil, nil
+}
+
+func TestGetOperationsClusterId(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterIdOrName string
+		clusterId       string
+		found           bool
+	}{
+		{name: "cluster by name", clusterIdOrName: "cluster-project", clusterId: clusterId, found: true},
+		{name: "cluster by id", clusterIdOrName: clusterId, clusterId: clusterId, found: true},
+		{name: "deleted cluster of the project", clusterIdOrName: deletedId, clusterId: deletedId, found: true},
+		{name: "cluster of another project", clusterIdOrName: foreignId, found: false},
+		{name: "unknown cluster name", clusterIdOrName: "unknown", found: false},
+	}
+	for _, test := range tests {
+		id, err := helpfunc.GetOperationsClusterId(operationsDb{}, projectId, test.clusterIdOrName)
+		if test.found && (err != nil || id != test.clusterId) {
+			t.Errorf("%s: expected cluster id %s, got '%s' with error %v", test.name, test.clusterId, id, err)
+		}
+		if !test.found && err == nil {
+			t.Errorf("%s: expected error, got cluster id '%s'", test.name, id)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package helpfunc
+
+import (
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestClusterNodesNumber(t *testing.T) {
+	tests := []struct {
+		name       string
+		nSlaves    int32
+		monitoring bool
+		classes    []string
+		nodes      map[string]int32
+	}{
+		{
+			name:  "no services",
+			nodes: map[string]int32{utils.NodeRoleMaster: 1},
+		},
+		{
+			name:    "master-slave",
+			nSlaves: 3,
+			classes: []string{utils.ClassMasterSlave},
+			nodes:   map[string]int32{utils.NodeRoleMaster: 1, utils.NodeRoleSlaves: 3},
+		},
+		{
+			name:    "storage only",
+			classes: []string{utils.ClassStorage},
+			nodes:   map[string]int32{utils.NodeRoleStorage: 1},
+		},
+		{
+			name:       "all roles",
+			nSlaves:    2,
+			monitoring: true,
+			classes:    []string{utils.ClassStorage, utils.ClassStandAlone, utils.ClassMasterSlave},
+			nodes: map[string]int32{utils.NodeRoleMaster: 1, utils.NodeRoleSlaves: 2,
+				utils.NodeRoleStorage: 1, utils.NodeRoleMonitoring: 1},
+		},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{NSlaves: test.nSlaves, Monitoring: test.monitoring}
+		nodes := helpfunc.ClusterNodesNumber(cluster, test.classes)
+		if len(nodes) != len(test.nodes) {
+			t.Errorf("%s: expected nodes %v, got %v", test.name, test.nodes, nodes)
+			continue
+		}
+		for role, number := range test.nodes {
+			if nodes[role] != number {
+				t.Errorf("%s: expected nodes %v, got %v", test.name, test.nodes, nodes)
+			}
+		}
+	}
+}
+
+func TestResourceUsage(t *testing.T) {
+	quotas := &protobuf.ResourceUsage{Clusters: 2, VCPUs: 16, Nodes: 4}
+
+	tests := []struct {
+		used     *protobuf.ResourceUsage
+		exceeded bool
+	}{
+		{used: &protobuf.ResourceUsage{Clusters: 2, VCPUs: 16, RAM: 1024, Disk: 500, Nodes: 4}, exceeded: false},
+		{used: &protobuf.ResourceUsage{Clusters: 3, VCPUs: 8, Nodes: 2}, exceeded: true},
+		{used: &protobuf.ResourceUsage{Clusters: 1, VCPUs: 17, Nodes: 2}, exceeded: true},
+		{used: &protobuf.ResourceUsage{Clusters: 1, VCPUs: 8, Nodes: 5}, exceeded: true},
+	}
+	for _, test := range tests {
+		err := validate.ResourceUsage(test.used, quotas)
+		if (err != nil) != test.exceeded {
+			t.Errorf("usage %v: expected exceeded %v, got error %v", test.used, test.exceeded, err)
+		}
+	}
+}
+
+func TestUpdateProjectLimits(t *testing.T) {
+	project := &protobuf.Project{DefaultClusterTTL: 24, MaxClusterTTL: 72, MaxClusters: 5, MaxVCPUs: 16,
+		MaxRAM: 32768, MaxDisk: 500, MaxNodes: 10}
+	update := &protobuf.Project{DefaultClusterTTL: utils.ProjectLimitReset, MaxClusterTTL: 48,
+		MaxClusters: utils.ProjectLimitReset, MaxRAM: utils.ProjectLimitReset}
+
+	helpfunc.UpdateProjectLimits(project, update)
+	expected := &protobuf.Project{DefaultClusterTTL: 0, MaxClusterTTL: 48, MaxClusters: 0, MaxVCPUs: 16,
+		MaxRAM: 0, MaxDisk: 500, MaxNodes: 10}
+	if project.DefaultClusterTTL != expected.DefaultClusterTTL || project.MaxClusterTTL != expected.MaxClusterTTL ||
+		project.MaxClusters != expected.MaxClusters || project.MaxVCPUs != expected.MaxVCPUs ||
+		project.MaxRAM != expected.MaxRAM || project.MaxDisk != expected.MaxDisk || project.MaxNodes != expected.MaxNodes {
+		t.Errorf("expected limits %v, got %v", expected, project)
+	}
+
+	// other negative values are rejected by the validation
+	helpfunc.UpdateProjectLimits(project, &protobuf.Project{MaxNodes: -2})
+	if err := validate.ProjectQuotas(project); err == nil {
+		t.Error("expected negative quota error")
+	}
+}
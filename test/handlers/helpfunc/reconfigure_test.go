@@ -0,0 +1,97 @@
+package helpfunc
+
+import (
+	"reflect"
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestReconfigureServiceConfig(t *testing.T) {
+	config := map[string]string{"worker-memory": "4g", "use-yarn": "true"}
+	changes := map[string]string{"worker-memory": "8g", "use-yarn": "", "worker-cores": "2"}
+
+	res := helpfunc.ReconfigureServiceConfig(config, changes)
+	expected := map[string]string{"worker-memory": "8g", "worker-cores": "2"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected config %v, got %v", expected, res)
+	}
+	if config["worker-memory"] != "4g" || config["use-yarn"] != "true" {
+		t.Errorf("current config is modified: %v", config)
+	}
+}
+
+func TestChangedConfigs(t *testing.T) {
+	tests := []struct {
+		oldConfig, newConfig map[string]string
+		changed              []string
+	}{
+		{
+			oldConfig: map[string]string{"worker-memory": "4g"},
+			newConfig: map[string]string{"worker-memory": "4g"},
+			changed:   nil,
+		},
+		{
+			oldConfig: map[string]string{"worker-memory": "4g", "use-yarn": "true"},
+			newConfig: map[string]string{"worker-memory": "8g", "worker-cores": "2"},
+			changed:   []string{"use-yarn", "worker-cores", "worker-memory"},
+		},
+		{
+			oldConfig: nil,
+			newConfig: map[string]string{"worker-cores": "2"},
+			changed:   []string{"worker-cores"},
+		},
+	}
+	for _, test := range tests {
+		res := helpfunc.ChangedConfigs(test.oldConfig, test.newConfig)
+		if !reflect.DeepEqual(res, test.changed) {
+			t.Errorf("comparing %v with %v: expected changed %v, got %v", test.oldConfig, test.newConfig, test.changed, res)
+		}
+	}
+}
+
+func TestConfigsRequireRestart(t *testing.T) {
+	configs := []*protobuf.ServiceConfig{
+		{ParameterName: "worker-memory", RequiresRestart: true},
+		{ParameterName: "log-level"},
+	}
+	if helpfunc.ConfigsRequireRestart([]string{"log-level"}, configs) {
+		t.Errorf("log-level change must not require restart")
+	}
+	if !helpfunc.ConfigsRequireRestart([]string{"log-level", "worker-memory"}, configs) {
+		t.Errorf("worker-memory change must require restart")
+	}
+}
+
+func TestServiceReconfigure(t *testing.T) {
+	version := &protobuf.ServiceVersion{
+		Version: "3.1.2",
+		Configs: []*protobuf.ServiceConfig{
+			{ParameterName: "worker-cores", Type: "int"},
+			{ParameterName: "log-level", Type: "string", PossibleValues: []string{"INFO", "DEBUG"}},
+		},
+	}
+	service := &protobuf.Service{Type: "spark", Version: "3.1.2"}
+	active := &protobuf.Cluster{EntityStatus: utils.StatusActive}
+
+	tests := []struct {
+		cluster *protobuf.Cluster
+		config  map[string]string
+		valid   bool
+	}{
+		{cluster: active, config: map[string]string{"worker-cores": "2", "log-level": "DEBUG"}, valid: true},
+		{cluster: &protobuf.Cluster{EntityStatus: utils.StatusStopped}, config: map[string]string{"worker-cores": "2"}, valid: false},
+		{cluster: active, config: map[string]string{"worker-cores": "two"}, valid: false},
+		{cluster: active, config: map[string]string{"log-level": "TRACE"}, valid: false},
+		{cluster: active, config: map[string]string{"worker-memory": "4g"}, valid: false},
+	}
+	for _, test := range tests {
+		err := validate.ServiceReconfigure(test.cluster, service, test.config, version)
+		if (err == nil) != test.valid {
+			t.Errorf("config %v of %s cluster: expected valid %v, got error %v", test.config, test.cluster.EntityStatus, test.valid, err)
+		}
+	}
+}
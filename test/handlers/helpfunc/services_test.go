@@ -0,0 +1,57 @@
+package helpfunc
+
+import (
+	"reflect"
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+)
+
+func TestFindClusterService(t *testing.T) {
+	cluster := &protobuf.Cluster{Services: []*protobuf.Service{
+		{ID: "1", Name: "spark", Type: "spark"},
+		{ID: "2", Name: "notebook", Type: "jupyter"},
+		{ID: "3", Name: "jupyter", Type: "jupyterhub"},
+	}}
+
+	tests := []struct {
+		idOrName string
+		id       string
+	}{
+		{idOrName: "2", id: "2"},
+		{idOrName: "notebook", id: "2"},
+		{idOrName: "spark", id: "1"},
+		{idOrName: "jupyter", id: "3"},
+		{idOrName: "jupyterhub", id: "3"},
+		{idOrName: "redis", id: ""},
+	}
+	for _, test := range tests {
+		service := helpfunc.FindClusterService(cluster, test.idOrName)
+		id := ""
+		if service != nil {
+			id = service.ID
+		}
+		if id != test.id {
+			t.Errorf("service %s: expected ID %q, got %q", test.idOrName, test.id, id)
+		}
+	}
+}
+
+func TestResolveServiceConfig(t *testing.T) {
+	configs := []*protobuf.ServiceConfig{
+		{ParameterName: "worker-memory", DefaultValue: "2g"},
+		{ParameterName: "worker-cores", DefaultValue: "1"},
+		{ParameterName: "log-level"},
+	}
+	config := map[string]string{"worker-memory": "4g"}
+
+	res := helpfunc.ResolveServiceConfig(config, configs)
+	expected := map[string]string{"worker-memory": "4g", "worker-cores": "1"}
+	if !reflect.DeepEqual(res, expected) {
+		t.Errorf("expected resolved config %v, got %v", expected, res)
+	}
+	if len(config) != 1 {
+		t.Errorf("service config is modified: %v", config)
+	}
+}
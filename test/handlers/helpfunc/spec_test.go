@@ -0,0 +1,101 @@
+package helpfunc
+
+import (
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func testCluster(status string) *protobuf.Cluster {
+	return &protobuf.Cluster{
+		Name:         "test-project",
+		DisplayName:  "test",
+		Description:  "test cluster",
+		EntityStatus: status,
+		NSlaves:      2,
+		Nodes: []*protobuf.Node{
+			{Name: "test-project-master", Role: utils.NodeRoleMaster},
+			{Name: "test-project-slave-1", Role: utils.NodeRoleSlaves},
+			{Name: "test-project-slave-2", Role: utils.NodeRoleSlaves},
+		},
+	}
+}
+
+func TestApplyClusterSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		spec    *protobuf.Cluster
+		action  string
+		changed bool
+	}{
+		{name: "unchanged", status: utils.StatusActive,
+			spec:   &protobuf.Cluster{Description: "test cluster", NSlaves: 2},
+			action: "", changed: false},
+		{name: "description", status: utils.StatusActive,
+			spec:   &protobuf.Cluster{Description: "new description", NSlaves: 2},
+			action: "", changed: true},
+		{name: "scale up", status: utils.StatusActive,
+			spec:   &protobuf.Cluster{Description: "test cluster", NSlaves: 3},
+			action: utils.ActionCreate, changed: true},
+		{name: "scale down", status: utils.StatusActive,
+			spec:   &protobuf.Cluster{Description: "test cluster", NSlaves: 1},
+			action: utils.ActionScale, changed: true},
+		{name: "failed", status: utils.StatusFailed,
+			spec:   &protobuf.Cluster{Description: "test cluster", NSlaves: 2},
+			action: utils.ActionCreate, changed: true},
+	}
+	for _, test := range tests {
+		cluster := testCluster(test.status)
+		action, changed, err := helpfunc.ApplyClusterSpec(nil, cluster, test.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if action != test.action || changed != test.changed {
+			t.Errorf("%s: expected action '%s' and changed %v, got '%s' and %v",
+				test.name, test.action, test.changed, action, changed)
+		}
+	}
+
+	cluster := testCluster(utils.StatusActive)
+	_, _, err := helpfunc.ApplyClusterSpec(nil, cluster, &protobuf.Cluster{Description: "test cluster", NSlaves: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cluster.Nodes[2].Status != utils.NodeStatusRemoving || cluster.Nodes[1].Status == utils.NodeStatusRemoving {
+		t.Errorf("last slave must be marked for removal: %v", cluster.Nodes)
+	}
+
+	// failed cluster is redeployed, so its slaves can't be removed
+	_, _, err = helpfunc.ApplyClusterSpec(nil, testCluster(utils.StatusFailed), &protobuf.Cluster{NSlaves: 1})
+	if err != helpfunc.ErrClusterSpecScale {
+		t.Errorf("expected error %v, got %v", helpfunc.ErrClusterSpecScale, err)
+	}
+}
+
+func TestClusterSpecRemovals(t *testing.T) {
+	tests := []struct {
+		name string
+		spec *protobuf.Cluster
+		err  error
+	}{
+		{name: "empty lists keep services and keys", spec: &protobuf.Cluster{NSlaves: 2}},
+		{name: "all keys listed", spec: &protobuf.Cluster{NSlaves: 2, Keys: []string{"key-1", "key-2", "key-3"}}},
+		{name: "key omitted", spec: &protobuf.Cluster{NSlaves: 2, Keys: []string{"key-2"}},
+			err: validate.ErrClusterSpecKeysMissing},
+		{name: "service omitted", spec: &protobuf.Cluster{NSlaves: 2, Services: []*protobuf.Service{{Type: "spark"}}},
+			err: validate.ErrClusterSpecServiceMissing("jupyter")},
+	}
+	for _, test := range tests {
+		cluster := testCluster(utils.StatusActive)
+		cluster.Keys = []string{"key-1", "key-2"}
+		cluster.Services = []*protobuf.Service{{Type: "spark"}, {Type: "jupyter"}}
+		err := validate.ClusterSpec(nil, cluster, test.spec)
+		if (err == nil) != (test.err == nil) || (err != nil && err.Error() != test.err.Error()) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package helpfunc
+
+import (
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler/helpfunc"
+	"github.com/ispras/michman/internal/rest/handler/validate"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		sign int
+	}{
+		{a: "3.1.2", b: "3.1.2", sign: 0},
+		{a: "3.1.2", b: "2.4.7", sign: 1},
+		{a: "2.10.0", b: "2.9.1", sign: 1},
+		{a: "2.4", b: "2.4.1", sign: -1},
+		{a: "7.x", b: "6.x", sign: 1},
+	}
+	for _, test := range tests {
+		res := helpfunc.CompareVersions(test.a, test.b)
+		if (res > 0) != (test.sign > 0) || (res < 0) != (test.sign < 0) {
+			t.Errorf("comparing %s with %s: expected sign %d, got %d", test.a, test.b, test.sign, res)
+		}
+	}
+}
+
+func TestUpgradeServiceConfig(t *testing.T) {
+	version := &protobuf.ServiceVersion{
+		Version: "3.1.2",
+		Configs: []*protobuf.ServiceConfig{{ParameterName: "worker-memory"}, {ParameterName: "use-jupyter"}},
+	}
+	config := map[string]string{"worker-memory": "4g", "use-yarn": "true", "hadoop-version": "2.7"}
+
+	res, removed := helpfunc.UpgradeServiceConfig(config, version)
+	if len(res) != 1 || res["worker-memory"] != "4g" {
+		t.Errorf("unexpected carried over config %v", res)
+	}
+	if len(removed) != 2 || removed[0] != "hadoop-version" || removed[1] != "use-yarn" {
+		t.Errorf("unexpected removed config parameters %v", removed)
+	}
+}
+
+func TestServiceUpgradeDependencies(t *testing.T) {
+	sTypes := []protobuf.ServiceType{
+		{
+			Type: "spark",
+			Versions: []*protobuf.ServiceVersion{
+				{Version: "2.4.7"},
+				{Version: "3.1.2"},
+			},
+		},
+		{
+			Type: "jupyter",
+			Versions: []*protobuf.ServiceVersion{
+				{Version: "6.0.1", Dependencies: []*protobuf.ServiceDependency{
+					{ServiceType: "spark", ServiceVersions: []string{"2.4.7"}},
+				}},
+				{Version: "6.4.0", Dependencies: []*protobuf.ServiceDependency{
+					{ServiceType: "spark", ServiceVersions: []string{"2.4.7", "3.1.2"}},
+				}},
+			},
+		},
+		{
+			Type: "jupyterhub",
+			Versions: []*protobuf.ServiceVersion{
+				{Version: "1.4", Dependencies: []*protobuf.ServiceDependency{
+					{ServiceType: "jupyter", ServiceVersions: []string{"6.4.0"}},
+				}},
+			},
+		},
+	}
+
+	services := []*protobuf.Service{{Type: "spark", Version: "2.4.7"}, {Type: "jupyter", Version: "6.0.1"}}
+	// jupyter 6.0.1 doesn't support spark 3.1.2
+	if err := validate.ServiceUpgradeDependencies(services, sTypes, "spark", "3.1.2"); err == nil {
+		t.Error("upgrade breaking dependent service is accepted")
+	}
+	if err := validate.ServiceUpgradeDependencies(services, sTypes, "jupyter", "6.4.0"); err != nil {
+		t.Errorf("upgrade with satisfied dependencies is rejected: %v", err)
+	}
+
+	services = []*protobuf.Service{{Type: "spark", Version: "2.4.7"}, {Type: "jupyter", Version: "6.4.0"}}
+	if err := validate.ServiceUpgradeDependencies(services, sTypes, "spark", "3.1.2"); err != nil {
+		t.Errorf("upgrade supported by dependent service is rejected: %v", err)
+	}
+
+	// services depending on the upgraded one are checked
+	services = []*protobuf.Service{{Type: "spark", Version: "3.1.2"}, {Type: "jupyter", Version: "6.4.0"},
+		{Type: "jupyterhub", Version: "1.4"}}
+	if err := validate.ServiceUpgradeDependencies(services, sTypes, "jupyter", "6.0.1"); err == nil {
+		t.Error("version unsupported by dependent service is accepted")
+	}
+
+	// dependency of the new version must be deployed
+	services = []*protobuf.Service{{Type: "jupyter", Version: "6.0.1"}}
+	if err := validate.ServiceUpgradeDependencies(services, sTypes, "jupyter", "6.4.0"); err == nil {
+		t.Error("upgrade with missing dependency is accepted")
+	}
+}
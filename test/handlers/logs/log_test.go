@@ -0,0 +1,92 @@
+package logs
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	clusterlogger "github.com/ispras/michman/internal/logger"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/handler"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+// clusterDb keeps one project with one cluster without running operations
+type clusterDb struct {
+	database.Database
+}
+
+func (db clusterDb) ReadProject(_ string) (*protobuf.Project, error) {
+	return &protobuf.Project{ID: "p-id", Name: "project"}, nil
+}
+
+func (db clusterDb) ReadCluster(_ string, _ string) (*protobuf.Cluster, error) {
+	return &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "cluster-project"}, nil
+}
+
+func (db clusterDb) ReadClusterOperations(_ string) ([]protobuf.Operation, error) {
+	return nil, nil
+}
+
+func TestServeClusterLogStreamOffset(t *testing.T) {
+	dir := t.TempDir()
+	cLogger, err := clusterlogger.NewFileLogger(dir, "c-id", utils.ActionCreate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("line1\nline2\n"))
+	cLogger.FinClusterLogsWriter()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	hS := handler.HttpServer{Db: clusterDb{}, Logger: logger,
+		Config: utils.Config{LogsOutput: utils.LogsFileOutput, LogsFilePath: dir}}
+	params := httprouter.Params{{Key: "projectIdOrName", Value: "project"}, {Key: "clusterIdOrName", Value: "cluster"}}
+
+	tests := []struct {
+		name        string
+		lastEventId string
+		offset      string
+		valid       bool
+		sent        string
+	}{
+		{name: "from beginning", valid: true,
+			sent: "id: 12\ndata: line1\ndata: line2\n\nid: 12\nevent: end\ndata: create\n\n"},
+		{name: "offset parameter", offset: "12", valid: true, sent: "id: 12\nevent: end\ndata: create\n\n"},
+		{name: "last event id", lastEventId: "12", valid: true, sent: "id: 12\nevent: end\ndata: create\n\n"},
+		{name: "last event id before offset parameter", lastEventId: "12", offset: "0", valid: true,
+			sent: "id: 12\nevent: end\ndata: create\n\n"},
+		{name: "negative offset", offset: "-1", valid: false},
+		{name: "not a number", lastEventId: "abc", valid: false},
+	}
+	for _, test := range tests {
+		url := "/logs/projects/project/clusters/cluster/stream"
+		if test.offset != "" {
+			url += "?offset=" + test.offset
+		}
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		if test.lastEventId != "" {
+			r.Header.Set("Last-Event-ID", test.lastEventId)
+		}
+		rec := httptest.NewRecorder()
+		hS.ServeClusterLogStream(rec, r, params)
+
+		isStream := strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream")
+		if isStream != test.valid {
+			t.Errorf("%s: expected valid %v, got status %d and body %q", test.name, test.valid, rec.Code, rec.Body.String())
+			continue
+		}
+		if test.valid && rec.Body.String() != test.sent {
+			t.Errorf("%s: expected %q, got %q", test.name, test.sent, rec.Body.String())
+		}
+	}
+}
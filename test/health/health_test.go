@@ -0,0 +1,263 @@
+package health
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/health"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestCheckHTTP(t *testing.T) {
+	tests := []struct {
+		status   int
+		expected int
+		health   string
+	}{
+		{status: http.StatusOK, health: utils.HealthPassing},
+		{status: http.StatusNoContent, health: utils.HealthPassing},
+		{status: http.StatusTooManyRequests, health: utils.HealthWarning},
+		{status: http.StatusInternalServerError, health: utils.HealthCritical},
+		{status: http.StatusNotFound, health: utils.HealthCritical},
+		{status: http.StatusFound, expected: http.StatusFound, health: utils.HealthPassing},
+		{status: http.StatusOK, expected: http.StatusFound, health: utils.HealthCritical},
+	}
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(test.status)
+		}))
+		res := health.NetChecker{}.CheckHTTP(health.HttpCheck{Address: server.URL, ExpectedStatus: test.expected})
+		server.Close()
+		if res.Health != test.health {
+			t.Errorf("CheckHTTP with status %d, expected %d = %s, want %s", test.status, test.expected, res.Health, test.health)
+		}
+	}
+}
+
+func TestMakeHttpCheck(t *testing.T) {
+	check := &protobuf.ServiceHealthCheck{
+		CheckType: utils.CheckTypeHTTP,
+		Configs: []*protobuf.HealthConfigs{
+			{ParameterName: "jupyterHealthPath", DefaultValue: "api/status"},
+			{ParameterName: "jupyterHealthPort", DefaultValue: "8888"},
+			{ParameterName: "jupyterHealthStatus", DefaultValue: "200"},
+		},
+	}
+	tests := []struct {
+		name    string
+		service *protobuf.Service
+		check   *protobuf.ServiceHealthCheck
+		result  health.HttpCheck
+		err     bool
+	}{
+		{
+			name:    "nothing is declared",
+			service: &protobuf.Service{URL: "10.0.0.1:8888/lab"},
+			check:   &protobuf.ServiceHealthCheck{CheckType: utils.CheckTypeHTTP},
+			result:  health.HttpCheck{Address: "http://10.0.0.1:8888/lab"},
+		},
+		{
+			name:    "declared defaults",
+			service: &protobuf.Service{URL: "http://10.0.0.1/lab"},
+			check:   check,
+			result:  health.HttpCheck{Address: "http://10.0.0.1:8888/api/status", ExpectedStatus: http.StatusOK},
+		},
+		{
+			name: "service config overrides defaults",
+			service: &protobuf.Service{URL: "https://10.0.0.1:443",
+				Config: map[string]string{"jupyterHealthPath": "/health", "jupyterHealthStatus": "204"}},
+			check:  check,
+			result: health.HttpCheck{Address: "https://10.0.0.1:8888/health", ExpectedStatus: http.StatusNoContent},
+		},
+		{
+			name:    "port is not a number",
+			service: &protobuf.Service{URL: "10.0.0.1", Config: map[string]string{"jupyterHealthPort": "http"}},
+			check:   check,
+			err:     true,
+		},
+	}
+	for _, test := range tests {
+		res, err := health.MakeHttpCheck(test.service, test.check)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if res != test.result {
+			t.Errorf("%s: expected %v, got %v", test.name, test.result, res)
+		}
+	}
+}
+
+func TestCheckTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+
+	if res := (health.NetChecker{}).CheckTCP(address); res.Health != utils.HealthPassing {
+		t.Errorf("CheckTCP of listening port = %s (%s), want %s", res.Health, res.Output, utils.HealthPassing)
+	}
+
+	listener.Close()
+	if res := (health.NetChecker{}).CheckTCP(address); res.Health != utils.HealthCritical {
+		t.Errorf("CheckTCP of closed port = %s, want %s", res.Health, utils.HealthCritical)
+	}
+}
+
+func TestCheckConsul(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/checks/spark-service" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"Status":"passing"},{"Status":"critical","Output":"connection refused"}]`))
+	}))
+	defer server.Close()
+
+	res := health.NetChecker{}.CheckConsul(server.URL, "spark")
+	if res.Health != utils.HealthCritical || res.Output != "connection refused" {
+		t.Errorf("CheckConsul = %+v, want critical result", res)
+	}
+	res = health.NetChecker{}.CheckConsul(server.URL, "jupyter")
+	if res.Health != utils.HealthUnknown {
+		t.Errorf("CheckConsul of unknown service = %s, want %s", res.Health, utils.HealthUnknown)
+	}
+}
+
+func TestParseConsulChecks(t *testing.T) {
+	tests := []struct {
+		body   string
+		health string
+	}{
+		{body: `[]`, health: utils.HealthUnknown},
+		{body: `not json`, health: utils.HealthUnknown},
+		{body: `[{"Status":"passing"}]`, health: utils.HealthPassing},
+		{body: `[{"Status":"passing"},{"Status":"warning"}]`, health: utils.HealthWarning},
+		{body: `[{"Status":"critical"},{"Status":"warning"}]`, health: utils.HealthCritical},
+	}
+	for _, test := range tests {
+		if res := health.ParseConsulChecks([]byte(test.body)); res.Health != test.health {
+			t.Errorf("ParseConsulChecks(%s) = %s, want %s", test.body, res.Health, test.health)
+		}
+	}
+}
+
+func TestNetCheckerCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	cluster := &protobuf.Cluster{}
+	tests := []struct {
+		name    string
+		service *protobuf.Service
+		check   *protobuf.ServiceHealthCheck
+		health  string
+	}{
+		{name: "no check", service: &protobuf.Service{URL: server.URL}, health: utils.HealthUnknown},
+		{name: "not supported", service: &protobuf.Service{URL: server.URL},
+			check: &protobuf.ServiceHealthCheck{CheckType: utils.CheckTypeNotSupported}, health: utils.HealthUnknown},
+		{name: "http", service: &protobuf.Service{URL: strings.TrimPrefix(server.URL, "http://")},
+			check: &protobuf.ServiceHealthCheck{CheckType: utils.CheckTypeHTTP}, health: utils.HealthPassing},
+		{name: "tcp", service: &protobuf.Service{URL: listener.Addr().String()},
+			check: &protobuf.ServiceHealthCheck{CheckType: utils.CheckTypeTCP}, health: utils.HealthPassing},
+		{name: "script without monitoring node", service: &protobuf.Service{Type: "spark"},
+			check: &protobuf.ServiceHealthCheck{CheckType: utils.CheckTypeScript}, health: utils.HealthUnknown},
+	}
+	for _, test := range tests {
+		if res := (health.NetChecker{}).Check(cluster, test.service, test.check); res.Health != test.health {
+			t.Errorf("%s: Check = %s (%s), want %s", test.name, res.Health, res.Output, test.health)
+		}
+	}
+}
+
+func TestClusterHealth(t *testing.T) {
+	tests := []struct {
+		healths []string
+		health  string
+	}{
+		{healths: nil, health: utils.HealthUnknown},
+		{healths: []string{"", utils.HealthUnknown}, health: utils.HealthUnknown},
+		{healths: []string{utils.HealthPassing, utils.HealthUnknown}, health: utils.HealthHealthy},
+		{healths: []string{utils.HealthPassing, utils.HealthWarning}, health: utils.HealthDegraded},
+		{healths: []string{utils.HealthCritical}, health: utils.HealthDegraded},
+	}
+	for _, test := range tests {
+		var services []*protobuf.Service
+		for _, h := range test.healths {
+			services = append(services, &protobuf.Service{Health: h})
+		}
+		if res := health.ClusterHealth(services); res != test.health {
+			t.Errorf("ClusterHealth(%v) = %s, want %s", test.healths, res, test.health)
+		}
+	}
+}
+
+func TestApplyResults(t *testing.T) {
+	cluster := &protobuf.Cluster{Services: []*protobuf.Service{
+		{ID: "1", Type: "spark"},
+		{ID: "2", Type: "jupyter", Health: utils.HealthPassing},
+	}}
+	health.ApplyResults(cluster, map[string]health.Result{
+		"1": {Health: utils.HealthCritical, Output: "down"},
+	}, "now")
+
+	if cluster.Services[0].Health != utils.HealthCritical || cluster.Services[0].HealthOutput != "down" ||
+		cluster.Services[0].HealthCheckedAt != "now" {
+		t.Errorf("checked service = %+v", cluster.Services[0])
+	}
+	if cluster.Services[1].Health != utils.HealthPassing || cluster.Services[1].HealthCheckedAt != "" {
+		t.Errorf("unchecked service = %+v", cluster.Services[1])
+	}
+	if cluster.Health != utils.HealthDegraded || cluster.HealthCheckedAt != "now" {
+		t.Errorf("cluster health = %s at %s, want %s", cluster.Health, cluster.HealthCheckedAt, utils.HealthDegraded)
+	}
+
+	report := health.Report(cluster)
+	if report.Health != utils.HealthDegraded || len(report.Services) != 2 || report.Services[0].Output != "down" {
+		t.Errorf("Report = %+v", report)
+	}
+}
+
+func TestConsulAddress(t *testing.T) {
+	tests := []struct {
+		nodes   []*protobuf.Node
+		address string
+	}{
+		{nodes: nil, address: ""},
+		{nodes: []*protobuf.Node{{Role: utils.NodeRoleMaster, FloatingIP: "10.0.0.1"}}, address: ""},
+		{nodes: []*protobuf.Node{{Role: utils.NodeRoleMonitoring, FloatingIP: "10.0.0.2", PrivateIP: "192.168.0.2"}},
+			address: "http://10.0.0.2:8500"},
+		{nodes: []*protobuf.Node{{Role: utils.NodeRoleMonitoring, PrivateIP: "192.168.0.2"}},
+			address: "http://192.168.0.2:8500"},
+		{nodes: []*protobuf.Node{{Role: utils.NodeRoleMonitoring, IPv6: "fd00::2"}},
+			address: "http://[fd00::2]:8500"},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{Nodes: test.nodes}
+		if res := health.ConsulAddress(cluster, health.DefaultConsulPort); res != test.address {
+			t.Errorf("ConsulAddress = %q, want %q", res, test.address)
+		}
+	}
+}
+
+func TestServiceURLs(t *testing.T) {
+	if res := health.HttpAddress("10.0.0.1:8080"); res != "http://10.0.0.1:8080" {
+		t.Errorf("HttpAddress = %s", res)
+	}
+	if res := health.HttpAddress("https://host/path"); res != "https://host/path" {
+		t.Errorf("HttpAddress = %s", res)
+	}
+	if !health.HasPort("10.0.0.1:8080") || health.HasPort("10.0.0.1") || health.HasPort("http://host/path") {
+		t.Errorf("HasPort returned wrong result")
+	}
+}
@@ -0,0 +1,79 @@
+package health
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/health"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// healthDb keeps one cluster, changed is applied to the saved cluster while its services are checked,
+// as by a concurrent operation. Whole cluster is never written by the monitor
+type healthDb struct {
+	database.Database
+	cluster *protobuf.Cluster
+	changed func(cluster *protobuf.Cluster)
+	saved   *protobuf.Cluster
+}
+
+func (db *healthDb) ReadServicesTypesList() ([]protobuf.ServiceType, error) {
+	if db.changed != nil {
+		db.changed(db.cluster)
+	}
+	return nil, nil
+}
+
+func (db *healthDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	return proto.Clone(db.cluster).(*protobuf.Cluster), nil
+}
+
+func (db *healthDb) UpdateClusterHealth(cluster *protobuf.Cluster) error {
+	db.saved = cluster
+	return nil
+}
+
+type passingChecker struct{}
+
+func (c passingChecker) Check(_ *protobuf.Cluster, _ *protobuf.Service, _ *protobuf.ServiceHealthCheck) health.Result {
+	return health.Result{Health: utils.HealthPassing}
+}
+
+func TestCheckCluster(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusActive,
+		Services: []*protobuf.Service{{ID: "s-id", Type: "spark"}}}
+
+	db := &healthDb{cluster: proto.Clone(cluster).(*protobuf.Cluster)}
+	m := health.Monitor{Db: db, Checker: passingChecker{}, Logger: logger}
+	report, err := m.CheckCluster(cluster)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.saved == nil || db.saved.Health != utils.HealthHealthy || db.saved.Services[0].Health != utils.HealthPassing {
+		t.Errorf("expected saved healthy cluster, got %v", db.saved)
+	}
+	if report.Health != utils.HealthHealthy {
+		t.Errorf("expected healthy report, got %s", report.Health)
+	}
+
+	// health is not saved for the cluster which operation is started while its services are checked
+	db = &healthDb{
+		cluster: proto.Clone(cluster).(*protobuf.Cluster),
+		changed: func(cluster *protobuf.Cluster) {
+			cluster.EntityStatus = utils.StatusInited
+		},
+	}
+	m.Db = db
+	if _, err = m.CheckCluster(cluster); err == nil {
+		t.Errorf("expected cluster status error")
+	}
+	if db.saved != nil {
+		t.Errorf("health of the changed cluster must not be saved")
+	}
+}
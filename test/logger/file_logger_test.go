@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+
+	clusterlogger "github.com/ispras/michman/internal/logger"
+	"github.com/ispras/michman/internal/utils"
+)
+
+// chunkSize is the maximal size of the logs read from the file at once
+const chunkSize = 1 << 20
+
+// writeClusterLogs writes the logs to the cluster log file in the temporary directory
+// and returns the logger reading them
+func writeClusterLogs(t *testing.T, logs string) clusterlogger.Logger {
+	cLogger, err := clusterlogger.NewFileLogger(t.TempDir(), "c-id", utils.ActionCreate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cLogger.FinClusterLogsWriter() })
+	w, err := cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.Write([]byte(logs)); err != nil {
+		t.Fatal(err)
+	}
+	return cLogger
+}
+
+func TestReadClusterLogsFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		logs   string
+		offset int64
+		read   string
+		next   int64
+	}{
+		{name: "from beginning", logs: "line1\nline2\n", offset: 0, read: "line1\nline2\n", next: 12},
+		{name: "from offset", logs: "line1\nline2\n", offset: 6, read: "line2\n", next: 12},
+		{name: "at end", logs: "line1\nline2\n", offset: 12, read: "", next: 12},
+		{name: "beyond end", logs: "line1\n", offset: 100, read: "", next: 100},
+		{name: "partial line", logs: "line1\nline", offset: 0, read: "line1\n", next: 6},
+		{name: "only partial line", logs: "line1\nline", offset: 6, read: "", next: 6},
+	}
+	for _, test := range tests {
+		cLogger := writeClusterLogs(t, test.logs)
+		read, next, err := cLogger.ReadClusterLogsFrom(test.offset)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if read != test.read || next != test.next {
+			t.Errorf("%s: expected '%s' and offset %d, got '%s' and offset %d",
+				test.name, test.read, test.next, read, next)
+		}
+	}
+}
+
+func TestReadClusterLogsFromChunkLimit(t *testing.T) {
+	// complete lines fitting into the chunk are read, the rest is read by the next call
+	line := strings.Repeat("a", 1023) + "\n"
+	logs := strings.Repeat(line, chunkSize/len(line)+1)
+	cLogger := writeClusterLogs(t, logs)
+
+	read, next, err := cLogger.ReadClusterLogsFrom(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != chunkSize || next != chunkSize {
+		t.Errorf("expected chunk of %d bytes, got %d bytes and offset %d", chunkSize, len(read), next)
+	}
+	read, next, err = cLogger.ReadClusterLogsFrom(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != line || next != int64(len(logs)) {
+		t.Errorf("expected the last line, got %d bytes and offset %d", len(read), next)
+	}
+
+	// line longer than the chunk is split, so the reading doesn't stop on it
+	long := strings.Repeat("b", chunkSize+10) + "\n"
+	cLogger = writeClusterLogs(t, long)
+	read, next, err = cLogger.ReadClusterLogsFrom(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != chunkSize || next != chunkSize {
+		t.Errorf("expected first %d bytes of the long line, got %d bytes and offset %d", chunkSize, len(read), next)
+	}
+	read, next, err = cLogger.ReadClusterLogsFrom(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read != long[chunkSize:] || next != int64(len(long)) {
+		t.Errorf("expected the rest of the long line, got %d bytes and offset %d", len(read), next)
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	clusterlogger "github.com/ispras/michman/internal/logger"
+	"github.com/ispras/michman/internal/utils"
+)
+
+func TestLogstashResendFailedChunk(t *testing.T) {
+	var mutex sync.Mutex
+	var chunks []string
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if fail {
+			hijacker, _ := w.(http.Hijacker)
+			conn, _, _ := hijacker.Hijack()
+			conn.Close()
+			return
+		}
+		var log struct {
+			Data string `json:"Data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&log); err != nil {
+			t.Error(err)
+		}
+		chunks = append(chunks, log.Data)
+	}))
+	defer server.Close()
+
+	cLogger, err := clusterlogger.NewLogstashLogger(server.URL, server.URL, "c-id", utils.ActionCreate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// chunk which failed to be sent is kept before the logs written later
+	w, err := cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("line1\n"))
+	if err = cLogger.FinClusterLogsWriter(); err == nil {
+		t.Fatal("expected error of sending logs to logstash")
+	}
+
+	mutex.Lock()
+	fail = false
+	mutex.Unlock()
+	w, err = cLogger.PrepClusterLogsWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("line2\n"))
+	if err = cLogger.FinClusterLogsWriter(); err != nil {
+		t.Fatal(err)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(chunks) != 1 || chunks[0] != "line1\nline2\n" {
+		t.Errorf("expected failed chunk sent with the next logs, got %q", chunks)
+	}
+}
@@ -0,0 +1,198 @@
+package queue
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	grpc_client "github.com/ispras/michman/internal/rest/grpc"
+	"github.com/ispras/michman/internal/rest/queue"
+	"github.com/ispras/michman/internal/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestCancelQueued(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusInited}
+	db := newQueueDb([]*protobuf.Cluster{proto.Clone(cluster).(*protobuf.Cluster)}, nil)
+	runner := &fakeRunner{}
+	q := queue.Queue{Db: db, Runner: runner, Logger: newLogger()}
+
+	// operation is cancelled right after it is saved, before its goroutine is started
+	var cancelled *protobuf.Operation
+	var cancelErr error
+	db.written = func(_ *protobuf.Operation) {
+		cancelled, cancelErr = q.Cancel(cluster, false)
+	}
+	op, err := q.Enqueue(cluster, utils.ActionCreate, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelErr != nil {
+		t.Fatal(cancelErr)
+	}
+	if cancelled.ID != op.ID || !cancelled.CancelRequested || cancelled.CleanupOnCancel {
+		t.Errorf("unexpected cancelled operation %v", cancelled)
+	}
+
+	finished := db.wait(t, 1)[op.ID]
+	if finished == nil || finished.Status != utils.OperationCancelled || finished.StartedAt != "" {
+		t.Errorf("expected operation cancelled before it started, got %v", finished)
+	}
+	if actions := runner.started(); len(actions) != 0 {
+		t.Errorf("cancelled queued operation must not reach the launcher, launcher got %v", actions)
+	}
+	if status := db.status(cluster.ID); status != utils.StatusCancelled {
+		t.Errorf("expected cancelled cluster, got %s", status)
+	}
+}
+
+func TestCancelRunning(t *testing.T) {
+	tests := []struct {
+		name    string
+		cleanup bool
+		actions []string
+	}{
+		{name: "cancel", actions: []string{utils.ActionCreate}},
+		{name: "cancel with cleanup", cleanup: true, actions: []string{utils.ActionCreate, utils.ActionDelete}},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusInited}
+		db := newQueueDb([]*protobuf.Cluster{proto.Clone(cluster).(*protobuf.Cluster)}, nil)
+
+		// creation runs until the launcher cancels it and then marks the cluster cancelled as grpc client does
+		started := make(chan struct{})
+		stop := make(chan struct{})
+		runner := &fakeRunner{
+			run: func(c *protobuf.Cluster, action string, progress grpc_client.ProgressHandler) (string, error) {
+				if action != utils.ActionCreate {
+					return utils.AnsibleOk, nil
+				}
+				progress(&protobuf.ProgressEvent{Type: utils.ProgressEventStarted})
+				close(started)
+				<-stop
+				db.setStatus(c.ID, utils.StatusCancelled)
+				return utils.AnsibleCancelled, nil
+			},
+			cancel: func(_ *protobuf.Cluster) (string, error) {
+				close(stop)
+				return utils.AnsibleCancelled, nil
+			},
+		}
+		q := queue.Queue{Db: db, Runner: runner, Logger: newLogger()}
+
+		op, err := q.Enqueue(cluster, utils.ActionCreate, "owner")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		select {
+		case <-started:
+		case <-time.After(waitTimeout):
+			t.Fatalf("%s: operation is not sent to the launcher", test.name)
+		}
+
+		cancelled, err := q.Cancel(cluster, test.cleanup)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if cancelled.ID != op.ID || !cancelled.CancelRequested || cancelled.CleanupOnCancel != test.cleanup {
+			t.Errorf("%s: unexpected cancelled operation %v", test.name, cancelled)
+		}
+
+		finished := db.wait(t, len(test.actions))
+		if finished[op.ID] == nil || finished[op.ID].Status != utils.OperationCancelled ||
+			!finished[op.ID].CancelRequested {
+			t.Errorf("%s: expected cancelled operation, got %v", test.name, finished[op.ID])
+		}
+		actions := runner.started()
+		if len(actions) != len(test.actions) {
+			t.Fatalf("%s: expected %v sent to the launcher, got %v", test.name, test.actions, actions)
+		}
+		for i := range actions {
+			if actions[i] != test.actions[i] {
+				t.Errorf("%s: expected %v sent to the launcher, got %v", test.name, test.actions, actions)
+			}
+		}
+
+		status := db.status(cluster.ID)
+		if !test.cleanup && status != utils.StatusCancelled {
+			t.Errorf("%s: expected cancelled cluster, got %s", test.name, status)
+		}
+		if test.cleanup && status != utils.StatusStopping {
+			t.Errorf("%s: expected cluster stopping for cleanup, got %s", test.name, status)
+		}
+	}
+}
+
+func TestCancelBeforeLauncherStarted(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusInited}
+	db := newQueueDb([]*protobuf.Cluster{proto.Clone(cluster).(*protobuf.Cluster)}, nil)
+
+	// operation is saved as running, but the launcher registers its run only after the cancel is requested
+	sent := make(chan struct{})
+	register := make(chan struct{})
+	stop := make(chan struct{})
+	var cancels int32
+	runner := &fakeRunner{
+		run: func(c *protobuf.Cluster, _ string, progress grpc_client.ProgressHandler) (string, error) {
+			close(sent)
+			<-register
+			progress(&protobuf.ProgressEvent{Type: utils.ProgressEventStarted})
+			select {
+			case <-stop:
+			case <-time.After(waitTimeout):
+				return utils.AnsibleOk, nil
+			}
+			db.setStatus(c.ID, utils.StatusCancelled)
+			return utils.AnsibleCancelled, nil
+		},
+		cancel: func(_ *protobuf.Cluster) (string, error) {
+			if atomic.AddInt32(&cancels, 1) == 1 {
+				close(stop)
+			}
+			return utils.AnsibleCancelled, nil
+		},
+	}
+	q := queue.Queue{Db: db, Runner: runner, Logger: newLogger()}
+
+	op, err := q.Enqueue(cluster, utils.ActionCreate, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-sent:
+	case <-time.After(waitTimeout):
+		t.Fatal("operation is not sent to the launcher")
+	}
+
+	if _, err = q.Cancel(cluster, false); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&cancels) != 0 {
+		t.Error("cancel must not be sent before the launcher registers the run")
+	}
+	close(register)
+
+	finished := db.wait(t, 1)[op.ID]
+	if finished == nil || finished.Status != utils.OperationCancelled {
+		t.Errorf("expected operation cancelled after the launcher started it, got %v", finished)
+	}
+	if n := atomic.LoadInt32(&cancels); n != 1 {
+		t.Errorf("expected one cancel sent to the launcher, got %d", n)
+	}
+	if status := db.status(cluster.ID); status != utils.StatusCancelled {
+		t.Errorf("expected cancelled cluster, got %s", status)
+	}
+}
+
+func TestCancelWithoutOperation(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusActive}
+	ops := []*protobuf.Operation{{ID: "op-id", ClusterID: cluster.ID, ProjectID: cluster.ProjectID,
+		Action: utils.ActionCreate, Status: utils.OperationSucceeded}}
+	db := newQueueDb([]*protobuf.Cluster{cluster}, ops)
+	q := queue.Queue{Db: db, Runner: &fakeRunner{}, Logger: newLogger()}
+
+	if _, err := q.Cancel(cluster, false); err == nil {
+		t.Error("expected no active operation error")
+	}
+}
@@ -0,0 +1,329 @@
+package queue
+
+import (
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	grpc_client "github.com/ispras/michman/internal/rest/grpc"
+	"github.com/ispras/michman/internal/rest/queue"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+const waitTimeout = 5 * time.Second
+
+// queueDb keeps clusters and operations in memory, operations saved in a final status are sent to finished.
+// written is called after a new operation is saved, before the queue starts it
+type queueDb struct {
+	database.Database
+	mutex    sync.Mutex
+	clusters map[string]*protobuf.Cluster
+	ops      map[string]*protobuf.Operation
+	finished chan *protobuf.Operation
+	written  func(op *protobuf.Operation)
+}
+
+func newQueueDb(clusters []*protobuf.Cluster, ops []*protobuf.Operation) *queueDb {
+	db := &queueDb{
+		clusters: make(map[string]*protobuf.Cluster),
+		ops:      make(map[string]*protobuf.Operation),
+		finished: make(chan *protobuf.Operation, 16),
+	}
+	for _, c := range clusters {
+		db.clusters[c.ID] = c
+	}
+	for _, op := range ops {
+		db.ops[op.ID] = op
+	}
+	return db
+}
+
+func (db *queueDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	c, ok := db.clusters[clusterIdOrName]
+	if !ok {
+		return nil, errors.New("cluster not found")
+	}
+	return proto.Clone(c).(*protobuf.Cluster), nil
+}
+
+func (db *queueDb) ReadClustersList() ([]protobuf.Cluster, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	clusters := make([]protobuf.Cluster, 0, len(db.clusters))
+	for _, c := range db.clusters {
+		clusters = append(clusters, protobuf.Cluster{})
+		proto.Merge(&clusters[len(clusters)-1], c)
+	}
+	return clusters, nil
+}
+
+func (db *queueDb) UpdateCluster(cluster *protobuf.Cluster) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.clusters[cluster.ID] = proto.Clone(cluster).(*protobuf.Cluster)
+	return nil
+}
+
+func (db *queueDb) setStatus(clusterId string, status string) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	db.clusters[clusterId].EntityStatus = status
+}
+
+func (db *queueDb) status(clusterId string) string {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	return db.clusters[clusterId].EntityStatus
+}
+
+func (db *queueDb) WriteOperation(op *protobuf.Operation) error {
+	db.mutex.Lock()
+	db.ops[op.ID] = proto.Clone(op).(*protobuf.Operation)
+	db.mutex.Unlock()
+	if db.written != nil {
+		db.written(op)
+	}
+	return nil
+}
+
+func (db *queueDb) UpdateOperation(op *protobuf.Operation) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	saved := proto.Clone(op).(*protobuf.Operation)
+	db.ops[op.ID] = saved
+	if op.Status == utils.OperationSucceeded || op.Status == utils.OperationFailed ||
+		op.Status == utils.OperationCancelled {
+		db.finished <- proto.Clone(saved).(*protobuf.Operation)
+	}
+	return nil
+}
+
+func (db *queueDb) ReadOperationsList() ([]protobuf.Operation, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	ops := make([]protobuf.Operation, 0, len(db.ops))
+	for _, op := range db.ops {
+		ops = append(ops, protobuf.Operation{})
+		proto.Merge(&ops[len(ops)-1], op)
+	}
+	return ops, nil
+}
+
+func (db *queueDb) ReadClusterOperations(clusterId string) ([]protobuf.Operation, error) {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	var ops []protobuf.Operation
+	for _, op := range db.ops {
+		if op.ClusterID != clusterId {
+			continue
+		}
+		ops = append(ops, protobuf.Operation{})
+		proto.Merge(&ops[len(ops)-1], op)
+	}
+	return ops, nil
+}
+
+func (db *queueDb) ReadClusterOpenUsageRecords(clusterId string) ([]protobuf.UsageRecord, error) {
+	return nil, nil
+}
+
+// wait returns the next n operations saved in a final status
+func (db *queueDb) wait(t *testing.T, n int) map[string]*protobuf.Operation {
+	t.Helper()
+	ops := make(map[string]*protobuf.Operation)
+	for len(ops) < n {
+		select {
+		case op := <-db.finished:
+			ops[op.ID] = op
+		case <-time.After(waitTimeout):
+			t.Fatalf("expected %d finished operations, got %d", n, len(ops))
+		}
+	}
+	return ops
+}
+
+// fakeRunner records actions sent to the launcher and runs them with run function,
+// run function sends started event as the launcher does after the run is registered
+type fakeRunner struct {
+	mutex   sync.Mutex
+	actions []string
+	run     func(c *protobuf.Cluster, action string, progress grpc_client.ProgressHandler) (string, error)
+	cancel  func(c *protobuf.Cluster) (string, error)
+}
+
+func (r *fakeRunner) start(c *protobuf.Cluster, action string, progress grpc_client.ProgressHandler) (string, error) {
+	r.mutex.Lock()
+	r.actions = append(r.actions, action)
+	r.mutex.Unlock()
+	if r.run == nil {
+		progress(&protobuf.ProgressEvent{Type: utils.ProgressEventStarted})
+		return utils.AnsibleOk, nil
+	}
+	return r.run(c, action, progress)
+}
+
+func (r *fakeRunner) started() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.actions...)
+}
+
+func (r *fakeRunner) StartClusterCreation(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error) {
+	return r.start(c, utils.ActionCreate, progress)
+}
+
+func (r *fakeRunner) StartClusterDestroying(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error) {
+	return r.start(c, utils.ActionDelete, progress)
+}
+
+func (r *fakeRunner) StartClusterModification(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error) {
+	return r.start(c, utils.ActionUpdate, progress)
+}
+
+func (r *fakeRunner) StartClusterScaling(c *protobuf.Cluster, progress grpc_client.ProgressHandler) (string, error) {
+	return r.start(c, utils.ActionScale, progress)
+}
+
+func (r *fakeRunner) StartClusterAction(c *protobuf.Cluster, action *protobuf.ClusterAction,
+	progress grpc_client.ProgressHandler) (string, error) {
+	return r.start(c, action.Action, progress)
+}
+
+func (r *fakeRunner) CancelCluster(c *protobuf.Cluster) (string, error) {
+	if r.cancel == nil {
+		return utils.AnsibleNotRunning, nil
+	}
+	return r.cancel(c)
+}
+
+func newLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	return logger
+}
+
+func TestEnqueue(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		runErr error
+		status string
+	}{
+		{name: "created cluster", action: utils.ActionCreate, status: utils.OperationSucceeded},
+		{name: "failed creation", action: utils.ActionCreate, runErr: errors.New("launcher is unavailable"),
+			status: utils.OperationFailed},
+		{name: "scaled cluster", action: utils.ActionScale, status: utils.OperationSucceeded},
+		{name: "deleted cluster", action: utils.ActionDelete, status: utils.OperationSucceeded},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusInited}
+		db := newQueueDb([]*protobuf.Cluster{proto.Clone(cluster).(*protobuf.Cluster)}, nil)
+		runErr := test.runErr
+		runner := &fakeRunner{run: func(_ *protobuf.Cluster, _ string, _ grpc_client.ProgressHandler) (string, error) {
+			return utils.AnsibleOk, runErr
+		}}
+		q := queue.Queue{Db: db, Runner: runner, Logger: newLogger()}
+
+		op, err := q.Enqueue(cluster, test.action, "owner")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if op.Status != utils.OperationQueued || op.ClusterID != cluster.ID || op.ProjectID != cluster.ProjectID {
+			t.Errorf("%s: unexpected queued operation %v", test.name, op)
+		}
+
+		finished := db.wait(t, 1)[op.ID]
+		if finished == nil {
+			t.Fatalf("%s: operation %s is not finished", test.name, op.ID)
+		}
+		if finished.Status != test.status || finished.Phase != utils.OperationPhaseFinished ||
+			finished.StartedAt == "" || finished.FinishedAt == "" {
+			t.Errorf("%s: unexpected finished operation %v", test.name, finished)
+		}
+		if (finished.Error != "") != (test.runErr != nil) {
+			t.Errorf("%s: unexpected operation error %q", test.name, finished.Error)
+		}
+		if actions := runner.started(); len(actions) != 1 || actions[0] != test.action {
+			t.Errorf("%s: expected %s sent to the launcher, got %v", test.name, test.action, actions)
+		}
+	}
+}
+
+func TestEnqueueUnknownAction(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", EntityStatus: utils.StatusActive}
+	db := newQueueDb([]*protobuf.Cluster{cluster}, nil)
+	q := queue.Queue{Db: db, Runner: &fakeRunner{}, Logger: newLogger()}
+
+	if _, err := q.Enqueue(cluster, utils.ActionStop, "owner"); err == nil {
+		t.Error("expected unknown action error for the lifecycle action")
+	}
+	if _, err := q.EnqueueAction(cluster, &protobuf.ClusterAction{Action: "destroy"}, "owner"); err == nil {
+		t.Error("expected unknown action error")
+	}
+	if ops, _ := db.ReadOperationsList(); len(ops) != 0 {
+		t.Errorf("operations with unknown actions must not be saved, got %d", len(ops))
+	}
+}
+
+func TestResume(t *testing.T) {
+	clusters := []*protobuf.Cluster{
+		{ID: "running", ProjectID: "p-id", Name: "running", EntityStatus: utils.StatusInited},
+		{ID: "cancelled", ProjectID: "p-id", Name: "cancelled", EntityStatus: utils.StatusInited},
+		{ID: "orphan-inited", ProjectID: "p-id", Name: "orphan-inited", EntityStatus: utils.StatusInited},
+		{ID: "orphan-stopping", ProjectID: "p-id", Name: "orphan-stopping", EntityStatus: utils.StatusStopping},
+		{ID: "active", ProjectID: "p-id", Name: "active", EntityStatus: utils.StatusActive},
+	}
+	ops := []*protobuf.Operation{
+		{ID: "op-running", ClusterID: "running", ProjectID: "p-id", Action: utils.ActionCreate,
+			Status: utils.OperationRunning},
+		{ID: "op-queued-delete", ClusterID: "deleted", ProjectID: "p-id", Action: utils.ActionDelete,
+			Status: utils.OperationQueued},
+		{ID: "op-cancelled", ClusterID: "cancelled", ProjectID: "p-id", Action: utils.ActionCreate,
+			Status: utils.OperationQueued, CancelRequested: true},
+		{ID: "op-succeeded", ClusterID: "active", ProjectID: "p-id", Action: utils.ActionCreate,
+			Status: utils.OperationSucceeded},
+	}
+	db := newQueueDb(clusters, ops)
+	runner := &fakeRunner{}
+	q := queue.Queue{Db: db, Runner: runner, Logger: newLogger()}
+
+	if err := q.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	finished := db.wait(t, 3)
+
+	expected := map[string]string{
+		"op-running":       utils.OperationSucceeded,
+		"op-queued-delete": utils.OperationSucceeded,
+		"op-cancelled":     utils.OperationCancelled,
+	}
+	for id, status := range expected {
+		if finished[id] == nil || finished[id].Status != status {
+			t.Errorf("operation %s: expected status %s, got %v", id, status, finished[id])
+		}
+	}
+	if actions := runner.started(); len(actions) != 1 || actions[0] != utils.ActionCreate {
+		t.Errorf("only the unfinished operation must be resumed, launcher got %v", actions)
+	}
+
+	statuses := map[string]string{
+		"running":         utils.StatusInited,
+		"cancelled":       utils.StatusCancelled,
+		"orphan-inited":   utils.StatusFailed,
+		"orphan-stopping": utils.StatusFailed,
+		"active":          utils.StatusActive,
+	}
+	for id, status := range statuses {
+		if current := db.status(id); current != status {
+			t.Errorf("cluster %s: expected status %s, got %s", id, status, current)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package reaper
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/reaper"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// leasesDb keeps one cluster, changed is applied to the saved cluster after the clusters list is read,
+// as by a concurrent request
+type leasesDb struct {
+	database.Database
+	cluster *protobuf.Cluster
+	changed func(cluster *protobuf.Cluster)
+}
+
+func (db *leasesDb) ReadClustersList() ([]protobuf.Cluster, error) {
+	res := []protobuf.Cluster{{ID: db.cluster.ID, ProjectID: db.cluster.ProjectID, Name: db.cluster.Name,
+		EntityStatus: db.cluster.EntityStatus, ExpiresAt: db.cluster.ExpiresAt, ExpiryWarnedAt: db.cluster.ExpiryWarnedAt}}
+	if db.changed != nil {
+		db.changed(db.cluster)
+	}
+	return res, nil
+}
+
+func (db *leasesDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	return proto.Clone(db.cluster).(*protobuf.Cluster), nil
+}
+
+func (db *leasesDb) UpdateClusterStatus(clusterId string, fromStatus string, toStatus string) (bool, error) {
+	if db.cluster.EntityStatus != fromStatus {
+		return false, nil
+	}
+	db.cluster.EntityStatus = toStatus
+	return true, nil
+}
+
+func (db *leasesDb) UpdateClusterExpiryWarning(clusterId string, expiresAt string, warnedAt string) (bool, error) {
+	if db.cluster.ExpiresAt != expiresAt {
+		return false, nil
+	}
+	db.cluster.ExpiryWarnedAt = warnedAt
+	return true, nil
+}
+
+func (db *leasesDb) ReadAutoscalingPolicy(clusterId string) (*protobuf.AutoscalingPolicy, error) {
+	return nil, database.ErrObjectNotFound("autoscaling policy", clusterId)
+}
+
+type recordingQueue struct {
+	clusters []*protobuf.Cluster
+}
+
+func (q *recordingQueue) Enqueue(cluster *protobuf.Cluster, action string, ownerId string) (*protobuf.Operation, error) {
+	q.clusters = append(q.clusters, cluster)
+	return &protobuf.Operation{ID: "op-id", ClusterID: cluster.ID, Action: action}, nil
+}
+
+type recordingNotifier struct {
+	warned int
+}
+
+func (n *recordingNotifier) WarnExpiry(_ *protobuf.Cluster) error {
+	n.warned++
+	return nil
+}
+
+func TestReap(t *testing.T) {
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	expiring := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	extended := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name      string
+		expiresAt string
+		changed   func(cluster *protobuf.Cluster)
+		deleted   bool
+		warned    bool
+		status    string
+	}{
+		{
+			name:      "expired cluster",
+			expiresAt: expired,
+			deleted:   true,
+			status:    utils.StatusStopping,
+		},
+		{
+			name:      "expired cluster with the started operation",
+			expiresAt: expired,
+			changed: func(cluster *protobuf.Cluster) {
+				cluster.EntityStatus = utils.StatusInited
+			},
+			status: utils.StatusInited,
+		},
+		{
+			name:      "expired cluster with the extended lease",
+			expiresAt: expired,
+			changed: func(cluster *protobuf.Cluster) {
+				cluster.ExpiresAt = extended
+			},
+			status: utils.StatusActive,
+		},
+		{
+			name:      "expiring cluster",
+			expiresAt: expiring,
+			warned:    true,
+			status:    utils.StatusActive,
+		},
+		{
+			name:      "expiring cluster with the extended lease",
+			expiresAt: expiring,
+			changed: func(cluster *protobuf.Cluster) {
+				cluster.ExpiresAt = extended
+			},
+			status: utils.StatusActive,
+		},
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	for _, test := range tests {
+		db := &leasesDb{
+			cluster: &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project",
+				EntityStatus: utils.StatusActive, ExpiresAt: test.expiresAt},
+			changed: test.changed,
+		}
+		queue := &recordingQueue{}
+		r := reaper.Reaper{Db: db, Queue: queue, Notifier: &recordingNotifier{}, Logger: logger}
+		r.Reap()
+
+		if test.deleted != (len(queue.clusters) == 1) {
+			t.Errorf("%s: expected deleted %v, got %d enqueued operations", test.name, test.deleted, len(queue.clusters))
+		}
+		if test.warned != (db.cluster.ExpiryWarnedAt != "") {
+			t.Errorf("%s: expected warning saved %v, got '%s'", test.name, test.warned, db.cluster.ExpiryWarnedAt)
+		}
+		if db.cluster.EntityStatus != test.status {
+			t.Errorf("%s: expected status %s, got %s", test.name, test.status, db.cluster.EntityStatus)
+		}
+	}
+}
+
+type recordingEvents struct {
+	events   []string
+	clusters []*protobuf.Cluster
+}
+
+func (e *recordingEvents) Notify(event string, cluster *protobuf.Cluster, _ *protobuf.Operation) {
+	e.events = append(e.events, event)
+	e.clusters = append(e.clusters, cluster)
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	events := &recordingEvents{}
+	notifier := reaper.WebhookNotifier{Events: events, Logger: logger}
+
+	cluster := &protobuf.Cluster{ID: "c-id", Name: "c-project", ExpiresAt: "2021-05-18T10:00:00Z"}
+	if err := notifier.WarnExpiry(cluster); err != nil {
+		t.Fatal(err)
+	}
+	if len(events.events) != 1 || events.events[0] != utils.EventClusterExpiring || events.clusters[0] != cluster {
+		t.Errorf("expected expiring event of the cluster, got %v", events.events)
+	}
+}
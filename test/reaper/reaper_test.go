@@ -0,0 +1,54 @@
+package reaper
+
+import (
+	"testing"
+	"time"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/reaper"
+)
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2021, 5, 17, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		expiresAt string
+		expired   bool
+	}{
+		{expiresAt: "", expired: false},
+		{expiresAt: "not a time", expired: false},
+		{expiresAt: "2021-05-17T11:00:00Z", expired: false},
+		{expiresAt: "2021-05-17T10:00:00Z", expired: true},
+		{expiresAt: "2021-05-16T10:00:00Z", expired: true},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{ExpiresAt: test.expiresAt}
+		if reaper.Expired(cluster, now) != test.expired {
+			t.Errorf("cluster expiring at '%s': expected expired %v", test.expiresAt, test.expired)
+		}
+	}
+}
+
+func TestNeedsWarning(t *testing.T) {
+	now := time.Date(2021, 5, 17, 10, 0, 0, 0, time.UTC)
+	period := 24 * time.Hour
+
+	tests := []struct {
+		expiresAt string
+		warnedAt  string
+		warning   bool
+	}{
+		{expiresAt: "", warning: false},
+		{expiresAt: "2021-05-20T10:00:00Z", warning: false},
+		{expiresAt: "2021-05-18T10:00:00Z", warning: true},
+		{expiresAt: "2021-05-17T12:00:00Z", warning: true},
+		{expiresAt: "2021-05-17T12:00:00Z", warnedAt: "2021-05-17T09:00:00Z", warning: false},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{ExpiresAt: test.expiresAt, ExpiryWarnedAt: test.warnedAt}
+		if reaper.NeedsWarning(cluster, now, period) != test.warning {
+			t.Errorf("cluster expiring at '%s' warned at '%s': expected warning %v",
+				test.expiresAt, test.warnedAt, test.warning)
+		}
+	}
+}
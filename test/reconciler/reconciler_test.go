@@ -0,0 +1,153 @@
+package reconciler
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/reconciler"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// driftDb keeps one cluster, changed is applied to the saved cluster right after it is read,
+// as by an operation started before the drift is saved. Whole cluster is never written by the reconciler
+type driftDb struct {
+	database.Database
+	cluster *protobuf.Cluster
+	changed func(cluster *protobuf.Cluster)
+	saved   *protobuf.Cluster
+}
+
+func (db *driftDb) ReadCluster(projectIdOrName string, clusterIdOrName string) (*protobuf.Cluster, error) {
+	cluster := proto.Clone(db.cluster).(*protobuf.Cluster)
+	if db.changed != nil {
+		db.changed(db.cluster)
+	}
+	return cluster, nil
+}
+
+func (db *driftDb) UpdateClusterDrift(cluster *protobuf.Cluster, fromStatus string) (bool, error) {
+	if db.cluster.EntityStatus != fromStatus {
+		return false, nil
+	}
+	db.saved = cluster
+	return true, nil
+}
+
+type missingInspector struct{}
+
+func (i missingInspector) InspectCluster(_ *protobuf.Cluster) (*protobuf.ClusterDrift, error) {
+	return &protobuf.ClusterDrift{Missing: true, Drift: []string{"node c-master is missing"}}, nil
+}
+
+func TestInspected(t *testing.T) {
+	tests := []struct {
+		status    string
+		inspected bool
+	}{
+		{status: utils.StatusActive, inspected: true},
+		{status: utils.StatusMissing, inspected: true},
+		{status: utils.StatusInited, inspected: false},
+		{status: utils.StatusFailed, inspected: false},
+		{status: utils.StatusStopped, inspected: false},
+	}
+	for _, test := range tests {
+		if reconciler.Inspected(test.status) != test.inspected {
+			t.Errorf("cluster in %s status: expected inspected %v", test.status, test.inspected)
+		}
+	}
+}
+
+func TestApplyDrift(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		drift  *protobuf.ClusterDrift
+		health string
+		result string
+	}{
+		{
+			name:   "active cluster without drift",
+			status: utils.StatusActive,
+			drift:  &protobuf.ClusterDrift{},
+			health: utils.HealthHealthy,
+			result: utils.StatusActive,
+		},
+		{
+			name:   "drifted active cluster",
+			status: utils.StatusActive,
+			drift:  &protobuf.ClusterDrift{Drift: []string{"node c-slave-1 is missing"}},
+			health: utils.HealthDegraded,
+			result: utils.StatusActive,
+		},
+		{
+			name:   "cluster without instances",
+			status: utils.StatusActive,
+			drift:  &protobuf.ClusterDrift{Missing: true, Drift: []string{"node c-master is missing"}},
+			health: utils.HealthDegraded,
+			result: utils.StatusMissing,
+		},
+		{
+			name:   "missing cluster with found instances",
+			status: utils.StatusMissing,
+			drift:  &protobuf.ClusterDrift{},
+			health: utils.HealthHealthy,
+			result: utils.StatusActive,
+		},
+	}
+	for _, test := range tests {
+		cluster := &protobuf.Cluster{EntityStatus: test.status, Health: utils.HealthDegraded,
+			Services: []*protobuf.Service{{Health: utils.HealthPassing}}}
+		reconciler.ApplyDrift(cluster, test.drift, "2021-05-17T10:00:00Z")
+		if cluster.EntityStatus != test.result {
+			t.Errorf("%s: expected status %s, got %s", test.name, test.result, cluster.EntityStatus)
+		}
+		if cluster.Health != test.health {
+			t.Errorf("%s: expected health %s, got %s", test.name, test.health, cluster.Health)
+		}
+		if len(cluster.Drift) != len(test.drift.Drift) || cluster.DriftCheckedAt != "2021-05-17T10:00:00Z" {
+			t.Errorf("%s: drift is not saved in the cluster", test.name)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "id", EntityStatus: utils.StatusMissing}
+	report := reconciler.Report(cluster, nil)
+	if !report.Missing || report.Health != utils.HealthUnknown || report.Drift == nil || report.ClusterID != "id" {
+		t.Errorf("unexpected report of the missing cluster: %v", report)
+	}
+}
+
+func TestReconcileCluster(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	cluster := &protobuf.Cluster{ID: "c-id", ProjectID: "p-id", Name: "c-project", EntityStatus: utils.StatusActive}
+
+	db := &driftDb{cluster: proto.Clone(cluster).(*protobuf.Cluster)}
+	r := reconciler.Reconciler{Db: db, Inspector: missingInspector{}, Logger: logger}
+	if _, err := r.ReconcileCluster(cluster); err != nil {
+		t.Fatal(err)
+	}
+	if db.saved == nil || db.saved.EntityStatus != utils.StatusMissing || len(db.saved.Drift) != 1 {
+		t.Errorf("expected saved missing cluster with drift, got %v", db.saved)
+	}
+
+	// drift is not saved for the cluster which operation is started before the drift is saved
+	db = &driftDb{
+		cluster: proto.Clone(cluster).(*protobuf.Cluster),
+		changed: func(cluster *protobuf.Cluster) {
+			cluster.EntityStatus = utils.StatusInited
+		},
+	}
+	r.Db = db
+	if _, err := r.ReconcileCluster(cluster); err == nil {
+		t.Errorf("expected cluster changed error")
+	}
+	if db.saved != nil || db.cluster.EntityStatus != utils.StatusInited {
+		t.Errorf("drift of the changed cluster must not be saved")
+	}
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ispras/michman/internal/rest/response"
+)
+
+func TestEventStreamSend(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		event string
+		data  string
+		sent  string
+	}{
+		{name: "single line", id: "6", data: "line1\n", sent: "id: 6\ndata: line1\n\n"},
+		{name: "multi-line", id: "12", data: "line1\nline2\n", sent: "id: 12\ndata: line1\ndata: line2\n\n"},
+		{name: "without trailing newline", data: "line1\nline2", sent: "data: line1\ndata: line2\n\n"},
+		{name: "empty line", data: "line1\n\nline3\n", sent: "data: line1\ndata: \ndata: line3\n\n"},
+		{name: "event type", id: "12", event: "end", data: "create", sent: "id: 12\nevent: end\ndata: create\n\n"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		stream, err := response.NewEventStream(w)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if err = stream.Send(test.id, test.event, test.data); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if w.Header().Get("Content-Type") != "text/event-stream" {
+			t.Errorf("%s: unexpected content type %s", test.name, w.Header().Get("Content-Type"))
+		}
+		if w.Body.String() != test.sent {
+			t.Errorf("%s: expected %q, got %q", test.name, test.sent, w.Body.String())
+		}
+	}
+}
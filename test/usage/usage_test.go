@@ -0,0 +1,95 @@
+package usage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/usage"
+)
+
+func TestSameNodes(t *testing.T) {
+	records := []protobuf.UsageRecord{
+		{Flavor: "small", Nodes: 1},
+		{Flavor: "large", Nodes: 3},
+	}
+
+	tests := []struct {
+		nodes map[string]int32
+		same  bool
+	}{
+		{nodes: map[string]int32{"small": 1, "large": 3}, same: true},
+		{nodes: map[string]int32{"small": 1, "large": 2}, same: false},
+		{nodes: map[string]int32{"small": 1}, same: false},
+		{nodes: map[string]int32{"small": 1, "large": 3, "medium": 1}, same: false},
+		{nodes: nil, same: false},
+	}
+	for _, test := range tests {
+		if usage.SameNodes(records, test.nodes) != test.same {
+			t.Errorf("nodes %v: expected same %v", test.nodes, test.same)
+		}
+	}
+	if !usage.SameNodes(nil, nil) {
+		t.Errorf("cluster without records and nodes must be accounted")
+	}
+}
+
+func TestHours(t *testing.T) {
+	from := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 5, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		startedAt string
+		endedAt   string
+		hours     float64
+	}{
+		{startedAt: "2021-05-10T00:00:00Z", endedAt: "2021-05-10T10:00:00Z", hours: 10},
+		{startedAt: "2021-04-30T22:00:00Z", endedAt: "2021-05-01T02:00:00Z", hours: 2},
+		{startedAt: "2021-05-20T00:00:00Z", endedAt: "", hours: 12},
+		{startedAt: "2021-04-01T00:00:00Z", endedAt: "2021-04-02T00:00:00Z", hours: 0},
+		{startedAt: "bad time", endedAt: "", hours: 0},
+	}
+	for _, test := range tests {
+		record := &protobuf.UsageRecord{StartedAt: test.startedAt, EndedAt: test.endedAt}
+		if hours := usage.Hours(record, from, to, now); hours != test.hours {
+			t.Errorf("record %s - %s: expected %v hours, got %v", test.startedAt, test.endedAt, test.hours, hours)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	from := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, 5, 2, 0, 0, 0, 0, time.UTC)
+	records := []protobuf.UsageRecord{
+		{ProjectID: "p1", ClusterID: "c1", Flavor: "small", Nodes: 2, VCPUs: 2, RAM: 4096,
+			StartedAt: "2021-05-01T00:00:00Z", EndedAt: "2021-05-01T10:00:00Z"},
+		{ProjectID: "p1", ClusterID: "c2", Flavor: "small", Nodes: 1, VCPUs: 2, RAM: 4096,
+			StartedAt: "2021-05-01T00:00:00Z", EndedAt: "2021-05-01T05:00:00Z"},
+		{ProjectID: "p2", ClusterID: "c3", Flavor: "large", Nodes: 1, VCPUs: 8, RAM: 16384,
+			StartedAt: "2021-04-30T00:00:00Z", EndedAt: "2021-05-01T01:00:00Z"},
+	}
+
+	report := usage.Report(records, map[string]string{"p1": "alpha", "p2": "beta"}, from, to, to)
+	if len(report.Items) != 2 {
+		t.Fatalf("expected 2 report items, got %d", len(report.Items))
+	}
+	item := report.Items[0]
+	if item.ProjectName != "alpha" || item.Clusters != 2 || item.NodeHours != 25 || item.VCPUHours != 50 {
+		t.Errorf("unexpected report item %v", item)
+	}
+	if report.Clusters != 3 || report.NodeHours != 26 || report.VCPUHours != 58 {
+		t.Errorf("unexpected report totals %v", report)
+	}
+
+	var buf bytes.Buffer
+	if err := usage.WriteCsv(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[1] != "p1,alpha,small,2,25.00,50.00,102400.00" {
+		t.Errorf("unexpected csv report:\n%s", buf.String())
+	}
+}
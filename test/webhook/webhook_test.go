@@ -0,0 +1,324 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ispras/michman/internal/database"
+	protobuf "github.com/ispras/michman/internal/protobuf"
+	"github.com/ispras/michman/internal/rest/webhook"
+	"github.com/ispras/michman/internal/utils"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/proto"
+)
+
+// deliveriesDb keeps saved webhook deliveries instead of the real database
+type deliveriesDb struct {
+	database.Database
+	mutex      sync.Mutex
+	webhooks   []*protobuf.Webhook
+	pending    []*protobuf.WebhookDelivery
+	deliveries []*protobuf.WebhookDelivery
+}
+
+func (db *deliveriesDb) ReadProjectWebhooks(_ string) ([]protobuf.Webhook, error) {
+	var res []protobuf.Webhook
+	for _, w := range db.webhooks {
+		res = append(res, protobuf.Webhook{})
+		proto.Merge(&res[len(res)-1], w)
+	}
+	return res, nil
+}
+
+func (db *deliveriesDb) ReadWebhook(webhookId string) (*protobuf.Webhook, error) {
+	for _, w := range db.webhooks {
+		if w.ID == webhookId {
+			return proto.Clone(w).(*protobuf.Webhook), nil
+		}
+	}
+	return nil, database.ErrObjectNotFound("webhook", webhookId)
+}
+
+func (db *deliveriesDb) ReadWebhookDeliveriesByStatus(status string) ([]protobuf.WebhookDelivery, error) {
+	var res []protobuf.WebhookDelivery
+	for _, d := range db.pending {
+		if d.Status == status {
+			res = append(res, protobuf.WebhookDelivery{})
+			proto.Merge(&res[len(res)-1], d)
+		}
+	}
+	return res, nil
+}
+
+func (db *deliveriesDb) WriteWebhookDelivery(delivery *protobuf.WebhookDelivery) error {
+	db.mutex.Lock()
+	defer db.mutex.Unlock()
+	// delivery is changed by the next attempts, so its copy is saved
+	db.deliveries = append(db.deliveries, proto.Clone(delivery).(*protobuf.WebhookDelivery))
+	return nil
+}
+
+// wait returns the last saved state of the deliveries after n of them are finished
+func (db *deliveriesDb) wait(t *testing.T, n int) map[string]*protobuf.WebhookDelivery {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		db.mutex.Lock()
+		last := make(map[string]*protobuf.WebhookDelivery)
+		finished := 0
+		for _, d := range db.deliveries {
+			last[d.ID] = d
+		}
+		for _, d := range last {
+			if d.Status != utils.DeliveryPending {
+				finished++
+			}
+		}
+		db.mutex.Unlock()
+		if finished >= n {
+			return last
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d deliveries are not finished", n)
+	return nil
+}
+
+func TestSign(t *testing.T) {
+	signature := webhook.Sign("secret", []byte(`{"Event":"active"}`))
+	expected := "sha256=d224531ca0ceb85c0ae93d159493819c856262e643e561d51667a3f34f980323"
+	if signature != expected {
+		t.Errorf("expected signature %s, got %s", expected, signature)
+	}
+}
+
+func TestSubscribed(t *testing.T) {
+	tests := []struct {
+		events     []string
+		event      string
+		subscribed bool
+	}{
+		{events: nil, event: utils.EventClusterCreated, subscribed: true},
+		{events: []string{utils.EventClusterActive, utils.EventClusterFailed}, event: utils.EventClusterFailed, subscribed: true},
+		{events: []string{utils.EventClusterActive}, event: utils.EventClusterDeleted, subscribed: false},
+	}
+	for _, test := range tests {
+		if webhook.Subscribed(&protobuf.Webhook{Events: test.events}, test.event) != test.subscribed {
+			t.Errorf("webhook with events %v: expected subscribed to %s %v", test.events, test.event, test.subscribed)
+		}
+	}
+}
+
+func TestQueuedEvent(t *testing.T) {
+	tests := []struct {
+		action string
+		event  string
+	}{
+		{action: utils.ActionCreate, event: utils.EventClusterCreated},
+		{action: utils.ActionDelete, event: utils.EventClusterDeleting},
+		{action: utils.ActionUpdate, event: ""},
+		{action: utils.ActionScale, event: ""},
+	}
+	for _, test := range tests {
+		if event := webhook.QueuedEvent(test.action); event != test.event {
+			t.Errorf("queued %s action: expected event '%s', got '%s'", test.action, test.event, event)
+		}
+	}
+}
+
+func TestFinishedEvent(t *testing.T) {
+	tests := []struct {
+		action string
+		status string
+		event  string
+	}{
+		{action: utils.ActionCreate, status: utils.OperationSucceeded, event: utils.EventClusterActive},
+		{action: utils.ActionUpdate, status: utils.OperationSucceeded, event: utils.EventClusterActive},
+		{action: utils.ActionCreate, status: utils.OperationFailed, event: utils.EventClusterFailed},
+		{action: utils.ActionDelete, status: utils.OperationSucceeded, event: utils.EventClusterDeleted},
+		{action: utils.ActionDelete, status: utils.OperationFailed, event: utils.EventClusterFailed},
+		{action: utils.ActionScale, status: utils.OperationSucceeded, event: utils.EventClusterScaled},
+		{action: utils.ActionStop, status: utils.OperationSucceeded, event: ""},
+		{action: utils.ActionCreate, status: utils.OperationCancelled, event: ""},
+	}
+	for _, test := range tests {
+		event := webhook.FinishedEvent(&protobuf.Operation{Action: test.action, Status: test.status})
+		if event != test.event {
+			t.Errorf("%s action finished with status %s: expected event '%s', got '%s'",
+				test.action, test.status, test.event, event)
+		}
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	cluster := &protobuf.Cluster{ID: "c-id", Name: "c-project", ProjectID: "p-id",
+		EntityStatus: utils.StatusFailed, NSlaves: 2}
+	op := &protobuf.Operation{ID: "op-id", Action: utils.ActionCreate, Error: "ansible failed"}
+
+	event := webhook.NewEvent(utils.EventClusterFailed, cluster, op)
+	if event.ClusterID != cluster.ID || event.ClusterName != cluster.Name || event.ProjectID != cluster.ProjectID ||
+		event.EntityStatus != utils.StatusFailed || event.NSlaves != 2 {
+		t.Errorf("event doesn't describe the cluster: %v", event)
+	}
+	if event.OperationID != op.ID || event.Action != op.Action || event.Error != op.Error {
+		t.Errorf("event doesn't describe the operation: %v", event)
+	}
+	if event.ID == "" || event.CreatedAt == "" {
+		t.Errorf("event ID and CreatedAt must be generated: %v", event)
+	}
+
+	event = webhook.NewEvent(utils.EventClusterDeleted, cluster, nil)
+	if event.EntityStatus != "" || event.OperationID != "" {
+		t.Errorf("deleted cluster event must have no status and operation: %v", event)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	payload := `{"Event":"active"}`
+	var requests int
+	var signature, event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		signature = r.Header.Get(utils.WebhookSignatureHeader)
+		event = r.Header.Get(utils.WebhookEventHeader)
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	db := &deliveriesDb{}
+	dispatcher := webhook.Dispatcher{Db: db, Logger: logger, Retries: 2, RetryDelay: time.Millisecond}
+
+	delivery := &protobuf.WebhookDelivery{ID: "d-id", Event: utils.EventClusterActive, Payload: payload,
+		Status: utils.DeliveryPending}
+	dispatcher.Deliver(&protobuf.Webhook{ID: "w-id", URL: server.URL, Secret: "secret"}, delivery)
+
+	if requests != 2 {
+		t.Fatalf("expected 2 requests to webhook, got %d", requests)
+	}
+	if signature != webhook.Sign("secret", []byte(payload)) {
+		t.Errorf("unexpected signature header %s", signature)
+	}
+	if event != utils.EventClusterActive {
+		t.Errorf("unexpected event header %s", event)
+	}
+	if delivery.Status != utils.DeliveryDelivered || delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusOK ||
+		delivery.DeliveredAt == "" || delivery.Error != "" {
+		t.Errorf("unexpected delivery result: %v", delivery)
+	}
+	if len(db.deliveries) != 2 || db.deliveries[0].ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("delivery must be saved after each attempt: %v", db.deliveries)
+	}
+
+	// webhook failing all attempts
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	delivery = &protobuf.WebhookDelivery{ID: "d-id-2", Event: utils.EventClusterActive, Payload: payload,
+		Status: utils.DeliveryPending}
+	dispatcher.Deliver(&protobuf.Webhook{ID: "w-id", URL: server.URL}, delivery)
+	if delivery.Status != utils.DeliveryFailed || delivery.Attempts != 3 || delivery.Error == "" {
+		t.Errorf("unexpected failed delivery result: %v", delivery)
+	}
+}
+
+func TestDeliverWithoutRetries(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	dispatcher := webhook.Dispatcher{Db: &deliveriesDb{}, Logger: logger, Retries: 0, RetryDelay: time.Millisecond}
+
+	delivery := &protobuf.WebhookDelivery{ID: "d-id", Event: utils.EventClusterActive, Payload: `{}`,
+		Status: utils.DeliveryPending}
+	dispatcher.Deliver(&protobuf.Webhook{ID: "w-id", URL: server.URL}, delivery)
+	if requests != 1 || delivery.Status != utils.DeliveryFailed || delivery.Attempts != 1 {
+		t.Errorf("expected one failed attempt, got %d requests and delivery %v", requests, delivery)
+	}
+}
+
+func TestNotifySavesPending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	db := &deliveriesDb{webhooks: []*protobuf.Webhook{{ID: "w-id", URL: server.URL}}}
+	dispatcher := webhook.Dispatcher{Db: db, Logger: logger, Retries: 1, RetryDelay: time.Millisecond}
+
+	dispatcher.Notify(utils.EventClusterActive, &protobuf.Cluster{ID: "c-id", ProjectID: "p-id"}, nil)
+	last := db.wait(t, 1)
+
+	db.mutex.Lock()
+	first := db.deliveries[0]
+	db.mutex.Unlock()
+	if first.Status != utils.DeliveryPending || first.Attempts != 0 || first.WebhookID != "w-id" {
+		t.Errorf("delivery must be saved as pending before the first attempt, got %v", first)
+	}
+	if d := last[first.ID]; d.Status != utils.DeliveryDelivered || d.Attempts != 1 {
+		t.Errorf("unexpected delivery result: %v", d)
+	}
+}
+
+func TestResume(t *testing.T) {
+	var requests int
+	var mutex sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		mutex.Lock()
+		requests++
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	db := &deliveriesDb{
+		webhooks: []*protobuf.Webhook{{ID: "w-id", URL: server.URL}},
+		pending: []*protobuf.WebhookDelivery{
+			{ID: "resumed", WebhookID: "w-id", Status: utils.DeliveryPending, Attempts: 1},
+			{ID: "no-retries", WebhookID: "w-id", Status: utils.DeliveryPending, Attempts: 3},
+			{ID: "deleted-webhook", WebhookID: "other-id", Status: utils.DeliveryPending},
+			{ID: "delivered", WebhookID: "w-id", Status: utils.DeliveryDelivered, Attempts: 1},
+		},
+	}
+	dispatcher := webhook.Dispatcher{Db: db, Logger: logger, Retries: 2, RetryDelay: time.Millisecond}
+
+	if err := dispatcher.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	last := db.wait(t, 3)
+
+	expected := map[string]struct {
+		status   string
+		attempts int32
+	}{
+		"resumed":         {status: utils.DeliveryDelivered, attempts: 2},
+		"no-retries":      {status: utils.DeliveryFailed, attempts: 3},
+		"deleted-webhook": {status: utils.DeliveryFailed, attempts: 0},
+	}
+	for id, e := range expected {
+		d := last[id]
+		if d == nil || d.Status != e.status || d.Attempts != e.attempts {
+			t.Errorf("delivery %s: expected status %s after %d attempts, got %v", id, e.status, e.attempts, d)
+		}
+	}
+	if _, ok := last["delivered"]; ok {
+		t.Error("finished delivery must not be resumed")
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if requests != 1 {
+		t.Errorf("expected only the resumed delivery sent, got %d requests", requests)
+	}
+}